@@ -0,0 +1,155 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/jaliko05/time-flow/models"
+)
+
+// Credentials of the super admin created by earlier versions on every fresh database
+const (
+	legacyDefaultAdminEmail    = "admin@timeflow.com"
+	legacyDefaultAdminPassword = "admin123"
+)
+
+var (
+	ErrSetupAlreadyCompleted = errors.New("setup already completed")
+	ErrInvalidSetupToken     = errors.New("invalid setup token")
+)
+
+var (
+	setupMu        sync.Mutex
+	setupRequired  bool
+	setupTokenHash string
+)
+
+// InitializeSetup checks whether an active super admin exists. If not, the server enters first-run mode
+// and prints a one-time setup token that must be used to create the first super admin.
+func InitializeSetup() {
+	if hasActiveSuperAdmin() {
+		flagLegacyDefaultAdmin()
+		return
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Failed to generate setup token: %v", err)
+	}
+	token := hex.EncodeToString(b)
+
+	setupMu.Lock()
+	setupRequired = true
+	setupTokenHash = hashSetupToken(token)
+	setupMu.Unlock()
+
+	log.Println("============================================================")
+	log.Println("No super admin found. The API is in first-run setup mode.")
+	log.Printf("One-time setup token: %s", token)
+	log.Println("POST it to /api/v1/setup or run: timeflow create-superadmin")
+	log.Println("============================================================")
+}
+
+// IsSetupRequired reports whether the server is waiting for the first super admin
+func IsSetupRequired() bool {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	return checkSetupRequired()
+}
+
+// checkSetupRequired leaves setup mode once an active super admin exists, for instance one created
+// with the CLI while the server was running. The caller must hold setupMu.
+func checkSetupRequired() bool {
+	if !setupRequired {
+		return false
+	}
+
+	if hasActiveSuperAdmin() {
+		setupRequired = false
+		setupTokenHash = ""
+	}
+	return setupRequired
+}
+
+// hasActiveSuperAdmin checks whether some active super admin can administer the server
+func hasActiveSuperAdmin() bool {
+	var count int64
+	DB.Model(&models.User{}).Where("role = ? AND is_active = ?", models.RoleSuperAdmin, true).Count(&count)
+	return count > 0
+}
+
+// CompleteSetup creates the first super admin using the one-time setup token
+func CompleteSetup(token, email, password, fullName string) (*models.User, error) {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+
+	if !checkSetupRequired() {
+		return nil, ErrSetupAlreadyCompleted
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSetupToken(token)), []byte(setupTokenHash)) != 1 {
+		return nil, ErrInvalidSetupToken
+	}
+
+	user, err := CreateSuperAdmin(email, password, fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is single use
+	setupRequired = false
+	setupTokenHash = ""
+
+	return user, nil
+}
+
+// CreateSuperAdmin creates a super admin account. It is used by the setup endpoint and the admin CLI.
+func CreateSuperAdmin(email, password, fullName string) (*models.User, error) {
+	var existing int64
+	DB.Model(&models.User{}).Where("email = ?", email).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("email already exists")
+	}
+
+	user := models.User{
+		Email:    email,
+		Password: password, // Will be hashed by BeforeCreate hook
+		FullName: fullName,
+		Role:     models.RoleSuperAdmin,
+		IsActive: true,
+	}
+
+	if err := DB.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// flagLegacyDefaultAdmin forces a password change on deployments still using the old default credentials
+func flagLegacyDefaultAdmin() {
+	var user models.User
+	if err := DB.Where("email = ? AND role = ?", legacyDefaultAdminEmail, models.RoleSuperAdmin).First(&user).Error; err != nil {
+		return
+	}
+
+	if user.MustChangePassword || !user.CheckPassword(legacyDefaultAdminPassword) {
+		return
+	}
+
+	if err := DB.Model(&user).Update("must_change_password", true).Error; err != nil {
+		log.Printf("Warning: Failed to flag default super admin for password change: %v", err)
+		return
+	}
+
+	log.Printf("Warning: %s still uses the default password. A password change will be required on next login", legacyDefaultAdminEmail)
+}
+
+func hashSetupToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -48,17 +49,11 @@ func ConnectDatabase() {
 
 	log.Println("Database connected successfully")
 
+	// Accounts created before the approval queue existed are queued once, when the queue is added
+	addsApprovalQueue := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "approval_status")
+
 	// Auto migrate schemas
-	if err := DB.AutoMigrate(
-		&models.Area{},
-		&models.User{},
-		&models.Project{},
-		&models.Task{},
-		&models.Activity{},
-		&models.Comment{},
-		&models.ProjectAssignment{},
-		&models.TaskAssignment{},
-	); err != nil {
+	if err := DB.AutoMigrate(models.All()...); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
@@ -66,9 +61,15 @@ func ConnectDatabase() {
 
 	// Run custom migrations (indexes, constraints, etc.)
 	runCustomMigrations()
-
-	// Create default super admin if not exists
-	createDefaultSuperAdmin()
+	backfillUserAreas()
+	backfillUserIdentities()
+	if addsApprovalQueue {
+		backfillPendingApprovals()
+	}
+	deactivatePendingAccounts()
+	migrateMemberRoles()
+	migrateRoleNameIndex()
+	seedActivityTypes()
 }
 
 // runCustomMigrations applies custom migrations that AutoMigrate doesn't handle
@@ -162,24 +163,131 @@ func runCustomMigrations() {
 	log.Printf("Custom migrations completed: %d/%d indexes applied", successCount, len(indexMigrations))
 }
 
-// createDefaultSuperAdmin creates a default super admin user
-func createDefaultSuperAdmin() {
-	var count int64
-	DB.Model(&models.User{}).Where("role = ?", models.RoleSuperAdmin).Count(&count)
-
-	if count == 0 {
-		superAdmin := models.User{
-			Email:    "admin@timeflow.com",
-			Password: "admin123", // Will be hashed by BeforeCreate hook
-			FullName: "Super Administrator",
-			Role:     models.RoleSuperAdmin,
-			IsActive: true,
+// backfillUserAreas registers the primary area of existing users as an area membership
+func backfillUserAreas() {
+	result := DB.Exec(`
+		INSERT INTO user_areas (user_id, area_id, role, is_primary, created_at, updated_at)
+		SELECT id, area_id, CASE WHEN role = 'admin' THEN 'admin' ELSE 'user' END, true, NOW(), NOW()
+		FROM users
+		WHERE area_id IS NOT NULL AND deleted_at IS NULL
+		ON CONFLICT (user_id, area_id) DO NOTHING`)
+	if result.Error != nil {
+		log.Printf("Warning: Failed to backfill user areas: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✓ Area memberships created for %d existing users", result.RowsAffected)
+	}
+}
+
+// backfillUserIdentities links accounts that signed in with Microsoft before identities were tracked
+func backfillUserIdentities() {
+	result := DB.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at, updated_at)
+		SELECT id, 'microsoft', microsoft_id, email, NOW(), NOW()
+		FROM users
+		WHERE microsoft_id IS NOT NULL AND microsoft_id <> '' AND deleted_at IS NULL
+		ON CONFLICT (provider, subject) DO NOTHING`)
+	if result.Error != nil {
+		log.Printf("Warning: Failed to backfill user identities: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✓ Identities linked for %d existing Microsoft users", result.RowsAffected)
+	}
+}
+
+// backfillPendingApprovals queues the Microsoft accounts that were created inactive to wait for a SuperAdmin
+// and never reviewed: never activated, never placed in an area and not deactivated on purpose
+func backfillPendingApprovals() {
+	result := DB.Exec(`
+		UPDATE users SET approval_status = 'pending'
+		WHERE auth_provider = 'microsoft' AND is_active = false AND approval_status = 'approved'
+		AND reviewed_at IS NULL AND deactivated_at IS NULL AND area_id IS NULL AND deleted_at IS NULL`)
+	if result.Error != nil {
+		log.Printf("Warning: Failed to backfill pending approvals: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✓ %d existing Microsoft accounts queued for approval", result.RowsAffected)
+	}
+}
+
+// deactivatePendingAccounts disables pending accounts that earlier versions stored as active
+func deactivatePendingAccounts() {
+	result := DB.Exec(`UPDATE users SET is_active = false WHERE approval_status = 'pending' AND is_active = true`)
+	if result.Error != nil {
+		log.Printf("Warning: Failed to deactivate pending accounts: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✓ %d pending accounts deactivated until approved", result.RowsAffected)
+	}
+}
+
+// migrateRoleNameIndex drops the old unique index on role names, which also covered deleted roles
+// and kept their names from being reused; idx_role_definition_name only covers live roles
+func migrateRoleNameIndex() {
+	migrator := DB.Migrator()
+	if !migrator.HasIndex(&models.RoleDefinition{}, "idx_role_definitions_name") {
+		return
+	}
+	if err := migrator.DropIndex(&models.RoleDefinition{}, "idx_role_definitions_name"); err != nil {
+		log.Printf("Warning: Failed to drop index idx_role_definitions_name: %v", err)
+	}
+}
+
+// migrateMemberRoles replaces the former can_modify flag of assignments with member roles:
+// project members that could not modify become viewers
+func migrateMemberRoles() {
+	migrator := DB.Migrator()
+	if migrator.HasColumn("project_assignments", "can_modify") {
+		result := DB.Exec("UPDATE project_assignments SET role = ? WHERE can_modify = false", models.MemberRoleViewer)
+		if result.Error != nil {
+			log.Printf("Warning: Failed to migrate project member roles: %v", result.Error)
+			return
+		}
+		if err := migrator.DropColumn("project_assignments", "can_modify"); err != nil {
+			log.Printf("Warning: Failed to drop project_assignments.can_modify: %v", err)
 		}
+		if result.RowsAffected > 0 {
+			log.Printf("✓ %d read-only project members migrated to viewers", result.RowsAffected)
+		}
+	}
+	if migrator.HasColumn("task_assignments", "can_modify") {
+		if err := migrator.DropColumn("task_assignments", "can_modify"); err != nil {
+			log.Printf("Warning: Failed to drop task_assignments.can_modify: %v", err)
+		}
+	}
+}
 
-		if err := DB.Create(&superAdmin).Error; err != nil {
-			log.Printf("Warning: Failed to create default super admin: %v", err)
-		} else {
-			log.Println("Default super admin created: admin@timeflow.com / admin123")
+// seedActivityTypes registers the built-in activity types as global definitions.
+// Types deleted by an administrator are not recreated.
+func seedActivityTypes() {
+	created := 0
+	for i, builtin := range models.BuiltinActivityTypes {
+		var count int64
+		DB.Unscoped().Model(&models.ActivityTypeDefinition{}).
+			Where("code = ? AND area_id IS NULL", builtin.Code).
+			Count(&count)
+		if count > 0 {
+			continue
 		}
+
+		names, _ := json.Marshal(builtin.Names)
+		definition := models.ActivityTypeDefinition{
+			Code:      builtin.Code,
+			Names:     names,
+			IsActive:  true,
+			SortOrder: i,
+		}
+		if err := DB.Create(&definition).Error; err != nil {
+			log.Printf("Warning: Failed to seed activity type %s: %v", builtin.Code, err)
+			continue
+		}
+		created++
+	}
+	if created > 0 {
+		log.Printf("✓ %d built-in activity types registered", created)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
 )
 
@@ -25,28 +26,15 @@ import (
 // @Param month query string false "Filter by month (YYYY-MM)"
 // @Param date_from query string false "Filter from date (YYYY-MM-DD)"
 // @Param date_to query string false "Filter to date (YYYY-MM-DD)"
+// @Param period query string false "Filter by period in the user's timezone (today, week)"
 // @Success 200 {object} utils.Response{data=[]models.Activity}
 // @Failure 401 {object} utils.Response
 // @Router /activities [get]
 func GetActivities(c *gin.Context) {
-	currentUserID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
-
 	query := config.DB.Preload("User").Preload("Area").Preload("Project").Preload("Task")
 
-	// Apply role-based filters
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		// Regular users only see their own activities
-		query = query.Where("user_id = ?", currentUserID)
-	} else if role == models.RoleAdmin {
-		// Admins see activities from their area
-		if userAreaID != nil {
-			query = query.Where("area_id = ?", userAreaID)
-		}
-	}
-	// SuperAdmin sees all activities
+	// Users always see their own activities, plus those of areas where they hold activities.view
+	query = policy.FromContext(c).ScopeActivities(query)
 
 	// Apply query filters
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
@@ -59,7 +47,7 @@ func GetActivities(c *gin.Context) {
 		query = query.Where("user_email = ?", userEmail)
 	}
 
-	if areaIDStr := c.Query("area_id"); areaIDStr != "" && role != models.RoleUser {
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
 		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
 			query = query.Where("area_id = ?", uint(areaID))
 		}
@@ -97,6 +85,15 @@ func GetActivities(c *gin.Context) {
 		}
 	}
 
+	if period := c.Query("period"); period != "" {
+		from, to, ok := periodRange(currentUserSettings(c), period)
+		if !ok {
+			utils.ErrorResponse(c, 400, "Invalid period. Use today or week")
+			return
+		}
+		query = query.Where("date BETWEEN ? AND ?", from, to)
+	}
+
 	var activities []models.Activity
 	if err := query.Order("date DESC, created_at DESC").Find(&activities).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to retrieve activities")
@@ -119,9 +116,6 @@ func GetActivities(c *gin.Context) {
 // @Router /activities/{id} [get]
 func GetActivity(c *gin.Context) {
 	id := c.Param("id")
-	currentUserID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var activity models.Activity
 	query := config.DB.Preload("User").Preload("Area").Preload("Project").Preload("Task")
@@ -132,29 +126,17 @@ func GetActivity(c *gin.Context) {
 	}
 
 	// Check access permissions
-	role := userRole.(models.Role)
-	if role == models.RoleUser && activity.UserID != currentUserID.(uint) {
+	if !policy.FromContext(c).CanViewActivity(&activity) {
 		utils.ErrorResponse(c, 403, "Access denied")
 		return
 	}
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if activity.AreaID == nil || *activity.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
-	}
 
 	utils.SuccessResponse(c, 200, "Activity retrieved successfully", activity)
 }
 
 // CreateActivity godoc
 // @Summary Create new activity
-// @Description Create a new time tracking activity (Users only)
+// @Description Create a new time tracking activity (requires activities.log)
 // @Tags activities
 // @Accept json
 // @Produce json
@@ -169,12 +151,10 @@ func CreateActivity(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userEmail, _ := c.Get("user_email")
 	userAreaID, _ := c.Get("user_area_id")
-	userRole, _ := c.Get("user_role")
 
-	// Only users can create activities
-	role := userRole.(models.Role)
-	if role != models.RoleUser {
-		utils.ErrorResponse(c, 403, "Only users can register activities")
+	// Only holders of activities.log can create activities
+	if !policy.FromContext(c).CanLogActivities() {
+		utils.ErrorResponse(c, 403, "You don't have permission to register activities")
 		return
 	}
 
@@ -184,12 +164,26 @@ func CreateActivity(c *gin.Context) {
 		return
 	}
 
-	// Parse date
+	// Parse date, which cannot be later than today in the user's timezone
+	settings := currentUserSettings(c)
 	activityDate, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
 		utils.ErrorResponse(c, 400, "Invalid date format. Use YYYY-MM-DD")
 		return
 	}
+	if activityDate.After(settings.Today()) {
+		utils.ErrorResponse(c, 400, "Activities cannot be registered for future dates")
+		return
+	}
+
+	// Fall back to the user's default activity type
+	if req.ActivityType == "" {
+		req.ActivityType = settings.DefaultActivityType
+	}
+	if req.ActivityType == "" {
+		utils.ErrorResponse(c, 400, "activity_type is required")
+		return
+	}
 
 	// Get user info for full name
 	var user models.User
@@ -198,45 +192,30 @@ func CreateActivity(c *gin.Context) {
 		return
 	}
 
-	// If project_id is provided, validate user is assigned to it and update project hours
-	if req.ProjectID != nil {
-		var project models.Project
-		if err := config.DB.First(&project, req.ProjectID).Error; err != nil {
-			utils.ErrorResponse(c, 404, "Project not found")
+	// The activity counts towards one of the user's areas: the requested one, otherwise the project's
+	// host or shared area the user belongs to, otherwise the primary area
+	subject := policy.FromContext(c)
+	activityAreaID, _ := userAreaID.(*uint)
+	if req.AreaID != nil {
+		if !subject.IsMemberOf(*req.AreaID) {
+			utils.ErrorResponse(c, 400, "Activities can only count towards areas you belong to")
 			return
 		}
-
-		// Validate project status allows activity registration
-		if !project.CanRegisterActivity() {
-			utils.ErrorResponse(c, 403, "Can only register activities for projects that are in progress or completed")
-			return
-		}
-
-		// Validate user is assigned to this project or it's their personal project
-		if project.ProjectType == models.ProjectTypePersonal {
-			// For personal projects, only the creator can register activities
-			if project.CreatedBy != userID.(uint) {
-				utils.ErrorResponse(c, 403, "You can only register activities for your own personal projects")
-				return
-			}
-		} else {
-			// For area projects, user must be assigned to it
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, userID.(uint), true).First(&assignment).Error
-			if err != nil {
-				utils.ErrorResponse(c, 403, "You are not assigned to this project")
-				return
-			}
-		}
+		activityAreaID = req.AreaID
 	}
 
-	// If task_id is provided, validate user is assigned to it and update task hours
+	// If task_id is provided, validate user is assigned to it and update task hours.
+	// The task's project is then checked like a project_id sent on its own.
 	if req.TaskID != nil {
 		var task models.Task
-		if err := config.DB.Preload("Project").First(&task, req.TaskID).Error; err != nil {
+		if err := config.DB.First(&task, req.TaskID).Error; err != nil {
 			utils.ErrorResponse(c, 404, "Task not found")
 			return
 		}
+		if req.ProjectID != nil && *req.ProjectID != task.ProjectID {
+			utils.ErrorResponse(c, 400, "The task does not belong to the project")
+			return
+		}
 
 		// Validate task status allows activity registration
 		if !task.CanRegisterActivity() {
@@ -252,17 +231,56 @@ func CreateActivity(c *gin.Context) {
 			return
 		}
 
-		// If task has a project, also set project_id
-		if req.ProjectID == nil {
-			req.ProjectID = &task.ProjectID
+		req.ProjectID = &task.ProjectID
+	}
+
+	// If project_id is provided, validate user is assigned to it and update project hours
+	if req.ProjectID != nil {
+		project, ok := checkActivityProject(c, subject, *req.ProjectID)
+		if !ok {
+			return
+		}
+		if req.AreaID == nil {
+			if areaID := activityProjectArea(subject, project); areaID != nil {
+				activityAreaID = areaID
+			}
+		}
+	}
+
+	// The type must be available to the activity's area
+	activityType, err := models.FindActivityType(config.DB, req.ActivityType, activityAreaID)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Activity type '"+string(req.ActivityType)+"' is not available in this area")
+		return
+	}
+	if activityType.RequiresProject && req.ProjectID == nil {
+		utils.ErrorResponse(c, 400, "Activities of this type must be linked to a project")
+		return
+	}
+
+	// Billable unless stated otherwise when the project, or else the activity type, says so
+	billable := activityType.BillableDefault
+	if req.ProjectID != nil {
+		var project models.Project
+		if err := config.DB.Select("id", "billable").First(&project, *req.ProjectID).Error; err == nil && project.Billable != nil {
+			billable = *project.Billable
 		}
 	}
+	if req.Billable != nil {
+		billable = *req.Billable
+	}
+
+	rates, err := models.ResolveRates(config.DB, &user, req.ProjectID, activityDate)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to resolve hourly rates")
+		return
+	}
 
 	activity := models.Activity{
 		UserID:          userID.(uint),
 		UserEmail:       userEmail.(string),
 		UserName:        user.FullName,
-		AreaID:          userAreaID.(*uint),
+		AreaID:          activityAreaID,
 		ProjectID:       req.ProjectID,
 		TaskID:          req.TaskID,
 		ProjectName:     req.ProjectName,
@@ -275,19 +293,18 @@ func CreateActivity(c *gin.Context) {
 		OtherArea:       req.OtherArea,
 		Observations:    req.Observations,
 		CalendarEventID: req.CalendarEventID,
+		Billable:        billable,
 	}
+	activity.ApplyRates(rates)
 
 	if err := config.DB.Create(&activity).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to create activity")
 		return
 	}
 
-	// Update project hours if applicable
+	// Update project hours and cost if applicable, notifying budget thresholds
 	if req.ProjectID != nil {
-		var project models.Project
-		if err := config.DB.First(&project, req.ProjectID).Error; err == nil {
-			project.UpdateUsedHours(config.DB)
-		}
+		refreshProjectUsage(*req.ProjectID)
 	}
 
 	// Update task hours if applicable
@@ -318,6 +335,7 @@ func CreateActivity(c *gin.Context) {
 // @Failure 401 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
 // @Router /activities/{id} [put]
 func UpdateActivity(c *gin.Context) {
 	id := c.Param("id")
@@ -341,22 +359,78 @@ func UpdateActivity(c *gin.Context) {
 		return
 	}
 
-	// If execution time changed and there's a project, update project hours
-	if req.ExecutionTime != nil && activity.ProjectID != nil {
-		var project models.Project
-		if err := config.DB.First(&project, activity.ProjectID).Error; err == nil {
-			// Remove old time and add new time
-			project.UsedHours -= activity.ExecutionTime
-			project.UsedHours += *req.ExecutionTime
-			project.RemainingHours = project.EstimatedHours - project.UsedHours
-			if project.EstimatedHours > 0 {
-				project.CompletionPercent = (project.UsedHours / project.EstimatedHours) * 100
+	// Invoiced activities are locked until their invoice is voided
+	if activity.IsInvoiced() {
+		utils.ErrorResponse(c, 409, "The activity has been invoiced and can no longer be updated")
+		return
+	}
+
+	// The activity can only be moved to another of the user's areas
+	subject := policy.FromContext(c)
+	if req.AreaID != nil && !subject.IsMemberOf(*req.AreaID) {
+		utils.ErrorResponse(c, 400, "Activities can only count towards areas you belong to")
+		return
+	}
+
+	// A new project goes through the same checks as on creation, and the activity follows it to
+	// the project's host or shared area the user belongs to unless an area is given
+	areaID := activity.AreaID
+	if req.AreaID != nil {
+		areaID = req.AreaID
+	}
+	projectChanged := req.ProjectID != nil && (activity.ProjectID == nil || *activity.ProjectID != *req.ProjectID)
+	if projectChanged {
+		project, ok := checkActivityProject(c, subject, *req.ProjectID)
+		if !ok {
+			return
+		}
+		if req.AreaID == nil {
+			if projectAreaID := activityProjectArea(subject, project); projectAreaID != nil {
+				areaID = projectAreaID
 			}
-			config.DB.Save(&project)
 		}
 	}
 
+	// Validate the new date, which cannot be later than today in the user's timezone
+	var activityDate time.Time
+	if req.Date != "" {
+		var err error
+		activityDate, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid date format. Use YYYY-MM-DD")
+			return
+		}
+		if activityDate.After(currentUserSettings(c).Today()) {
+			utils.ErrorResponse(c, 400, "Activities cannot be registered for future dates")
+			return
+		}
+	}
+
+	// A new type, area or project must keep the type available to the activity's area
+	if req.ActivityType != "" || req.AreaID != nil || projectChanged {
+		code, projectID := activity.ActivityType, activity.ProjectID
+		if req.ActivityType != "" {
+			code = req.ActivityType
+		}
+		if req.ProjectID != nil {
+			projectID = req.ProjectID
+		}
+		activityType, err := models.FindActivityType(config.DB, code, areaID)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Activity type '"+string(code)+"' is not available in this area")
+			return
+		}
+		if activityType.RequiresProject && projectID == nil {
+			utils.ErrorResponse(c, 400, "Activities of this type must be linked to a project")
+			return
+		}
+	}
+
+	// Project totals are recomputed after saving, for the previous project too if it changes
+	previousProjectID := activity.ProjectID
+
 	// Update fields
+	activity.AreaID = areaID
 	if req.ProjectID != nil {
 		activity.ProjectID = req.ProjectID
 	}
@@ -373,27 +447,95 @@ func UpdateActivity(c *gin.Context) {
 		activity.ExecutionTime = *req.ExecutionTime
 	}
 	if req.Date != "" {
-		if activityDate, err := time.Parse("2006-01-02", req.Date); err == nil {
-			activity.Date = activityDate
-			activity.Month = activityDate.Format("2006-01")
-		}
+		activity.Date = activityDate
+		activity.Month = activityDate.Format("2006-01")
 	}
 	if req.OtherArea != "" {
 		activity.OtherArea = req.OtherArea
 	}
 	activity.Observations = req.Observations
+	if req.Billable != nil {
+		activity.Billable = *req.Billable
+	}
+
+	// Rates are looked up again only when the work moves to another project or day
+	if req.ProjectID != nil || req.Date != "" {
+		var user models.User
+		if err := config.DB.First(&user, activity.UserID).Error; err == nil {
+			rates, err := models.ResolveRates(config.DB, &user, activity.ProjectID, activity.Date)
+			if err != nil {
+				utils.ErrorResponse(c, 500, "Failed to resolve hourly rates")
+				return
+			}
+			activity.CostRate, activity.BillRate = rates.CostRate, rates.BillRate
+		}
+	}
+	activity.RecalculateAmounts()
 
 	if err := config.DB.Save(&activity).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update activity")
 		return
 	}
 
+	if previousProjectID != nil && (activity.ProjectID == nil || *previousProjectID != *activity.ProjectID) {
+		refreshProjectUsage(*previousProjectID)
+	}
+	if activity.ProjectID != nil {
+		refreshProjectUsage(*activity.ProjectID)
+	}
+
 	// Reload to get relations
 	config.DB.Preload("User").Preload("Area").Preload("Project").First(&activity, activity.ID)
 
 	utils.SuccessResponse(c, 200, "Activity updated successfully", activity)
 }
 
+// checkActivityProject checks the current user can register time on the project, writing the error response otherwise
+func checkActivityProject(c *gin.Context, subject *policy.Subject, projectID uint) (*models.Project, bool) {
+	var project models.Project
+	if err := config.DB.First(&project, projectID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return nil, false
+	}
+
+	// Validate project status allows activity registration
+	if !project.CanRegisterActivity() {
+		utils.ErrorResponse(c, 403, "Can only register activities for projects that are in progress or completed")
+		return nil, false
+	}
+
+	// Validate user is assigned to this project or it's their personal project
+	if project.ProjectType == models.ProjectTypePersonal {
+		// For personal projects, only the creator can register activities
+		if project.CreatedBy != subject.UserID {
+			utils.ErrorResponse(c, 403, "You can only register activities for your own personal projects")
+			return nil, false
+		}
+	} else {
+		// For area projects, user must be a member other than a viewer
+		role, ok := subject.ProjectRole(project.ID)
+		if !ok {
+			utils.ErrorResponse(c, 403, "You are not assigned to this project")
+			return nil, false
+		}
+		if !role.CanLogTime() {
+			utils.ErrorResponse(c, 403, "Project viewers cannot register activities")
+			return nil, false
+		}
+	}
+	return &project, true
+}
+
+// activityProjectArea returns the project's host or shared area the user belongs to, or nil
+func activityProjectArea(subject *policy.Subject, project *models.Project) *uint {
+	for _, areaID := range models.ProjectAreaIDs(config.DB, project) {
+		if subject.IsMemberOf(areaID) {
+			return &areaID
+		}
+	}
+	return nil
+}
+
 // DeleteActivity godoc
 // @Summary Delete activity
 // @Description Soft delete an activity (Owner only)
@@ -405,6 +547,7 @@ func UpdateActivity(c *gin.Context) {
 // @Failure 401 {object} utils.Response
 // @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
 // @Router /activities/{id} [delete]
 func DeleteActivity(c *gin.Context) {
 	id := c.Param("id")
@@ -422,17 +565,10 @@ func DeleteActivity(c *gin.Context) {
 		return
 	}
 
-	// If activity has a project, update project hours
-	if activity.ProjectID != nil {
-		var project models.Project
-		if err := config.DB.First(&project, activity.ProjectID).Error; err == nil {
-			project.UsedHours -= activity.ExecutionTime
-			project.RemainingHours = project.EstimatedHours - project.UsedHours
-			if project.EstimatedHours > 0 {
-				project.CompletionPercent = (project.UsedHours / project.EstimatedHours) * 100
-			}
-			config.DB.Save(&project)
-		}
+	// Invoiced activities are locked until their invoice is voided
+	if activity.IsInvoiced() {
+		utils.ErrorResponse(c, 409, "The activity has been invoiced and can no longer be deleted")
+		return
 	}
 
 	if err := config.DB.Delete(&activity).Error; err != nil {
@@ -440,6 +576,11 @@ func DeleteActivity(c *gin.Context) {
 		return
 	}
 
+	// If activity has a project, update project hours and cost
+	if activity.ProjectID != nil {
+		refreshProjectUsage(*activity.ProjectID)
+	}
+
 	utils.SuccessResponse(c, 200, "Activity deleted successfully", nil)
 }
 
@@ -454,25 +595,12 @@ func DeleteActivity(c *gin.Context) {
 // @Param month query string false "Filter by month (YYYY-MM)"
 // @Param date_from query string false "Filter from date (YYYY-MM-DD)"
 // @Param date_to query string false "Filter to date (YYYY-MM-DD)"
+// @Param period query string false "Filter by period in the user's timezone (today, week)"
 // @Success 200 {object} utils.Response{data=models.ActivityStats}
 // @Failure 401 {object} utils.Response
 // @Router /activities/stats [get]
 func GetActivityStats(c *gin.Context) {
-	currentUserID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
-
-	query := config.DB.Model(&models.Activity{})
-
-	// Apply role-based filters
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		query = query.Where("user_id = ?", currentUserID)
-	} else if role == models.RoleAdmin {
-		if userAreaID != nil {
-			query = query.Where("area_id = ?", userAreaID)
-		}
-	}
+	query := policy.FromContext(c).ScopeActivities(config.DB.Model(&models.Activity{}))
 
 	// Apply query filters
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
@@ -481,7 +609,7 @@ func GetActivityStats(c *gin.Context) {
 		}
 	}
 
-	if areaIDStr := c.Query("area_id"); areaIDStr != "" && role != models.RoleUser {
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
 		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
 			query = query.Where("area_id = ?", uint(areaID))
 		}
@@ -503,6 +631,15 @@ func GetActivityStats(c *gin.Context) {
 		}
 	}
 
+	if period := c.Query("period"); period != "" {
+		from, to, ok := periodRange(currentUserSettings(c), period)
+		if !ok {
+			utils.ErrorResponse(c, 400, "Invalid period. Use today or week")
+			return
+		}
+		query = query.Where("date BETWEEN ? AND ?", from, to)
+	}
+
 	// Get total hours and count
 	var totalHours float64
 	var totalActivities int64
@@ -571,3 +708,16 @@ func GetActivityStats(c *gin.Context) {
 
 	utils.SuccessResponse(c, 200, "Statistics retrieved successfully", stats)
 }
+
+// periodRange returns the first and last calendar day of "today" or "this week" for the user
+func periodRange(settings *models.UserSettings, period string) (time.Time, time.Time, bool) {
+	today := settings.Today()
+	switch period {
+	case "today":
+		return today, today, true
+	case "week":
+		start := settings.WeekStartOf(today)
+		return start, start.AddDate(0, 0, 6), true
+	}
+	return time.Time{}, time.Time{}, false
+}
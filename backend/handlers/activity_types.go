@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+var activityTypeCodePattern = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+// GetActivityTypes godoc
+// @Summary Get activity types
+// @Description Get the activity types available to the user's areas (global and area types), with names in the user's locale.
+// @Description With area_id, the types available to that area. Managers can pass include_inactive=true.
+// @Tags activity-types
+// @Produce json
+// @Security BearerAuth
+// @Param area_id query int false "Area ID"
+// @Param include_inactive query bool false "Include inactive types"
+// @Success 200 {object} utils.Response{data=[]models.ActivityTypeDefinition}
+// @Failure 401 {object} utils.Response
+// @Router /activity-types [get]
+func GetActivityTypes(c *gin.Context) {
+	subject := policy.FromContext(c)
+
+	var areaIDs []uint
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		areaID, err := strconv.ParseUint(areaIDStr, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid area_id")
+			return
+		}
+		areaIDs = []uint{uint(areaID)}
+	} else {
+		areaIDs = models.UserAreaIDs(config.DB, subject.UserID)
+	}
+
+	query := models.ScopeActivityTypesFor(config.DB, areaIDs)
+	if c.Query("include_inactive") == "true" && subject.CanAnywhere(models.PermActivityTypesManage) {
+		query = config.DB.Where("area_id IS NULL OR area_id IN ?", models.NonEmptyIDs(areaIDs))
+	}
+
+	var types []models.ActivityTypeDefinition
+	if err := query.Order("sort_order ASC, code ASC").Find(&types).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve activity types")
+		return
+	}
+
+	locale := currentUserSettings(c).Locale
+	for i := range types {
+		types[i].Localize(locale)
+	}
+
+	utils.SuccessResponse(c, 200, "Activity types retrieved successfully", types)
+}
+
+// CreateActivityType godoc
+// @Summary Create activity type
+// @Description Create an activity type for an area, or a global one (requires the global activity_types.manage permission)
+// @Tags activity-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param activity_type body CreateActivityTypeRequest true "Activity type data"
+// @Success 201 {object} utils.Response{data=models.ActivityTypeDefinition}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /activity-types [post]
+func CreateActivityType(c *gin.Context) {
+	var req models.CreateActivityTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermActivityTypesManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only add activity types to areas you manage")
+		return
+	}
+	if !activityTypeCodePattern.MatchString(string(req.Code)) {
+		utils.ErrorResponse(c, 400, "Invalid code. Use 2-50 lowercase letters, numbers or underscores")
+		return
+	}
+	names, msg := activityTypeNames(req.Names)
+	if msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	// A code is either global or defined per area, never both
+	var count int64
+	query := config.DB.Model(&models.ActivityTypeDefinition{}).Where("code = ?", req.Code)
+	if req.AreaID != nil {
+		query = query.Where("area_id IS NULL OR area_id = ?", *req.AreaID)
+	}
+	query.Count(&count)
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "An activity type with this code already exists")
+		return
+	}
+
+	userID := c.MustGet("user_id").(uint)
+	definition := models.ActivityTypeDefinition{
+		Code:            req.Code,
+		AreaID:          req.AreaID,
+		Names:           names,
+		IsActive:        true,
+		BillableDefault: req.BillableDefault,
+		RequiresProject: req.RequiresProject,
+		SortOrder:       req.SortOrder,
+		CreatedBy:       &userID,
+	}
+	if err := config.DB.Create(&definition).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create activity type")
+		return
+	}
+	definition.Localize(currentUserSettings(c).Locale)
+
+	utils.SuccessResponse(c, 201, "Activity type created successfully", definition)
+}
+
+// UpdateActivityType godoc
+// @Summary Update activity type
+// @Description Update the names and flags of an activity type. The code cannot change because activities store it.
+// @Tags activity-types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Activity type ID"
+// @Param activity_type body UpdateActivityTypeRequest true "Activity type data"
+// @Success 200 {object} utils.Response{data=models.ActivityTypeDefinition}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /activity-types/{id} [put]
+func UpdateActivityType(c *gin.Context) {
+	var req models.UpdateActivityTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var definition models.ActivityTypeDefinition
+	if err := config.DB.First(&definition, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Activity type not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermActivityTypesManage, definition.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Names != nil {
+		names, msg := activityTypeNames(req.Names)
+		if msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+		updates["names"] = names
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.BillableDefault != nil {
+		updates["billable_default"] = *req.BillableDefault
+	}
+	if req.RequiresProject != nil {
+		updates["requires_project"] = *req.RequiresProject
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+
+	if err := config.DB.Model(&definition).Updates(updates).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update activity type")
+		return
+	}
+
+	config.DB.First(&definition, definition.ID)
+	definition.Localize(currentUserSettings(c).Locale)
+
+	utils.SuccessResponse(c, 200, "Activity type updated successfully", definition)
+}
+
+// DeleteActivityType godoc
+// @Summary Delete activity type
+// @Description Delete an activity type. Existing activities keep their type; new ones can no longer use it.
+// @Tags activity-types
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Activity type ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /activity-types/{id} [delete]
+func DeleteActivityType(c *gin.Context) {
+	var definition models.ActivityTypeDefinition
+	if err := config.DB.First(&definition, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Activity type not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermActivityTypesManage, definition.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	if err := config.DB.Delete(&definition).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete activity type")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Activity type deleted successfully", nil)
+}
+
+// activityTypeNames validates the display names and encodes them, returning an error message or ""
+func activityTypeNames(names map[string]string) ([]byte, string) {
+	if len(names) == 0 {
+		return nil, "At least one name is required"
+	}
+	for locale, name := range names {
+		if !localePattern.MatchString(locale) || name == "" {
+			return nil, "Names must be keyed by locale (e.g. es, en) and not be empty"
+		}
+	}
+	encoded, _ := json.Marshal(names)
+	return encoded, ""
+}
@@ -39,7 +39,7 @@ func GetAreas(c *gin.Context) {
 
 // GetArea godoc
 // @Summary Get area by ID
-// @Description Get a specific area with its users
+// @Description Get a specific area with its users, parent and child areas
 // @Tags areas
 // @Produce json
 // @Security BearerAuth
@@ -52,7 +52,7 @@ func GetArea(c *gin.Context) {
 	id := c.Param("id")
 
 	var area models.Area
-	if err := config.DB.Preload("Users").First(&area, id).Error; err != nil {
+	if err := config.DB.Preload("Users").Preload("Parent").Preload("Children").First(&area, id).Error; err != nil {
 		utils.ErrorResponse(c, 404, "Area not found")
 		return
 	}
@@ -87,9 +87,18 @@ func CreateArea(c *gin.Context) {
 		return
 	}
 
+	if req.ParentID != nil {
+		var parent models.Area
+		if err := config.DB.First(&parent, *req.ParentID).Error; err != nil {
+			utils.ErrorResponse(c, 404, "Parent area not found")
+			return
+		}
+	}
+
 	area := models.Area{
 		Name:        req.Name,
 		Description: req.Description,
+		ParentID:    req.ParentID,
 		IsActive:    true,
 	}
 
@@ -140,6 +149,23 @@ func UpdateArea(c *gin.Context) {
 	if req.IsActive != nil {
 		area.IsActive = *req.IsActive
 	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			area.ParentID = nil
+		} else {
+			var parent models.Area
+			if err := config.DB.First(&parent, *req.ParentID).Error; err != nil {
+				utils.ErrorResponse(c, 404, "Parent area not found")
+				return
+			}
+			// The new parent cannot be the area itself or one of its descendants
+			if models.LoadAreaTree(config.DB).IsAncestor(area.ID, parent.ID) {
+				utils.ErrorResponse(c, 400, "An area cannot be moved under itself or one of its descendants")
+				return
+			}
+			area.ParentID = &parent.ID
+		}
+	}
 
 	if err := config.DB.Save(&area).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update area")
@@ -177,6 +203,14 @@ func DeleteArea(c *gin.Context) {
 		return
 	}
 
+	// Check if area has child areas
+	var childCount int64
+	config.DB.Model(&models.Area{}).Where("parent_id = ?", id).Count(&childCount)
+	if childCount > 0 {
+		utils.ErrorResponse(c, 400, "Cannot delete area with child areas")
+		return
+	}
+
 	if err := config.DB.Delete(&area).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to delete area")
 		return
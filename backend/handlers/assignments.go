@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// AddProjectMembers godoc
+// @Summary Add project members
+// @Description Assign users to a project with a role (contributor by default). Users who are already members keep their role.
+// @Description Admins of an area the project is shared with can assign members of their area as contributors or viewers.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param members body AddProjectMembersRequest true "Users to assign"
+// @Success 200 {object} utils.Response{data=[]models.ProjectAssignment}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/members [post]
+func AddProjectMembers(c *gin.Context) {
+	var req models.AddProjectMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	// Project managers assign from any of the project's areas; admins of a shared area, their own people
+	subject := policy.FromContext(c)
+	all, areaIDs := subject.AssignableAreas(&project)
+	if !all && len(areaIDs) == 0 {
+		utils.ErrorResponse(c, 403, "You don't have permission to manage this project's members")
+		return
+	}
+	role := req.Role
+	if role == "" {
+		role = models.MemberRoleContributor
+	}
+	if role.CanManageTasks() && !subject.CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "Only project managers can grant the owner or manager role")
+		return
+	}
+
+	userIDs := uniqueIDs(req.UserIDs)
+	for _, userID := range userIDs {
+		if code, msg := checkProjectMember(userID, all, areaIDs); msg != "" {
+			utils.ErrorResponse(c, code, msg)
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			if err := assignProjectTo(tx, project.ID, userID, subject.UserID, role); err != nil {
+				return err
+			}
+		}
+		if project.Status == models.ProjectStatusUnassigned && len(userIDs) > 0 {
+			return tx.Model(&project).Update("status", models.ProjectStatusAssigned).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to assign project members")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project members assigned successfully", activeProjectMembers(project.ID))
+}
+
+// RemoveProjectMember godoc
+// @Summary Remove project member
+// @Description Unassign a user from a project. The project goes back to unassigned when nobody is left.
+// @Description Admins of an area the project is shared with can remove members of their area.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.Response{data=[]models.ProjectAssignment}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/members/{userId} [delete]
+func RemoveProjectMember(c *gin.Context) {
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	var assignment models.ProjectAssignment
+	if err := config.DB.Preload("User").Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, c.Param("userId"), true).
+		First(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project member not found")
+		return
+	}
+
+	// Admins of a shared area can only remove their own people
+	subject := policy.FromContext(c)
+	if !subject.CanManageProject(&project) {
+		_, areaIDs := subject.AssignableAreas(&project)
+		if len(areaIDs) == 0 || !assignment.User.BelongsToAnyArea(config.DB, areaIDs) {
+			utils.ErrorResponse(c, 403, "You don't have permission to manage this project's members")
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := unassignProjectFrom(tx, project.ID, assignment.UserID, subject.UserID); err != nil {
+			return err
+		}
+		var remaining int64
+		tx.Model(&models.ProjectAssignment{}).Where("project_id = ? AND is_active = ?", project.ID, true).Count(&remaining)
+		if remaining == 0 && project.Status == models.ProjectStatusAssigned {
+			return tx.Model(&project).Update("status", models.ProjectStatusUnassigned).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to remove project member")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project member removed successfully", activeProjectMembers(project.ID))
+}
+
+// AddTaskAssignees godoc
+// @Summary Add task assignees
+// @Description Assign users to a task, keeping its current assignees. Backlog tasks become assigned.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param assignees body AddTaskAssigneesRequest true "Users to assign"
+// @Success 200 {object} utils.Response{data=[]models.TaskAssignment}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/assignees [post]
+func AddTaskAssignees(c *gin.Context) {
+	var req models.AddTaskAssigneesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	userIDs := uniqueIDs(req.UserIDs)
+	for _, userID := range userIDs {
+		if code, msg := checkTaskAssignee(&task.Project, userID); msg != "" {
+			utils.ErrorResponse(c, code, msg)
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			if err := assignTaskTo(tx, task.ID, userID, subject.UserID); err != nil {
+				return err
+			}
+		}
+		if task.Status == models.TaskStatusBacklog && len(userIDs) > 0 {
+			return tx.Model(&task).Update("status", models.TaskStatusAssigned).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to assign task")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Task assignees added successfully", activeTaskAssignees(task.ID))
+}
+
+// RemoveTaskAssignee godoc
+// @Summary Remove task assignee
+// @Description Unassign a user from a task. An assigned task goes back to the backlog when nobody is left.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.Response{data=[]models.TaskAssignment}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/assignees/{userId} [delete]
+func RemoveTaskAssignee(c *gin.Context) {
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var assignment models.TaskAssignment
+	if err := config.DB.Where("task_id = ? AND user_id = ? AND is_active = ?", task.ID, c.Param("userId"), true).
+		First(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task assignee not found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := unassignTaskFrom(tx, task.ID, assignment.UserID, subject.UserID); err != nil {
+			return err
+		}
+		var remaining int64
+		tx.Model(&models.TaskAssignment{}).Where("task_id = ? AND is_active = ?", task.ID, true).Count(&remaining)
+		if remaining == 0 && task.Status == models.TaskStatusAssigned {
+			return tx.Model(&task).Update("status", models.TaskStatusBacklog).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to remove task assignee")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Task assignee removed successfully", activeTaskAssignees(task.ID))
+}
+
+// GetProjectAssignmentHistory godoc
+// @Summary Get project assignment history
+// @Description List everyone who has been a member of the project, with the periods they were assigned, their role,
+// @Description the hours allocated to them (estimates of their active tasks, split among each task's assignees) and the hours they logged
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} utils.Response{data=[]models.AssignmentHistoryEntry}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/assignment-history [get]
+func GetProjectAssignmentHistory(c *gin.Context) {
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanViewProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var assignments []models.ProjectAssignment
+	config.DB.Preload("User").Where("project_id = ?", project.ID).Find(&assignments)
+	var events []models.AssignmentEvent
+	config.DB.Where("project_id = ?", project.ID).Order("created_at ASC, id ASC").Find(&events)
+
+	var tasks []models.Task
+	config.DB.Select("id", "estimated_hours").Where("project_id = ?", project.ID).Find(&tasks)
+	allocated := allocatedHours(tasks)
+	logged := loggedHours("project_id = ?", project.ID)
+
+	history := make([]models.AssignmentHistoryEntry, 0, len(assignments))
+	for _, a := range assignments {
+		entry := newHistoryEntry(&a.User, a.IsActive, allocated, logged)
+		entry.Role = a.Role
+		entry.Periods = assignmentPeriods(eventsOf(events, a.UserID), a.AssignedAt, a.AssignedBy, a.IsActive, unassignedAt(a.UnassignedAt, a.UpdatedAt))
+		history = append(history, entry)
+	}
+	sortHistory(history)
+
+	utils.SuccessResponse(c, 200, "Assignment history retrieved successfully", history)
+}
+
+// GetTaskAssignmentHistory godoc
+// @Summary Get task assignment history
+// @Description List everyone who has been assigned to the task, with the periods they were assigned,
+// @Description the hours allocated to them (the task estimate split among its current assignees) and the hours they logged on it
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} utils.Response{data=[]models.AssignmentHistoryEntry}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/assignment-history [get]
+func GetTaskAssignmentHistory(c *gin.Context) {
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	if !policy.FromContext(c).CanViewTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var assignments []models.TaskAssignment
+	config.DB.Preload("User").Where("task_id = ?", task.ID).Find(&assignments)
+	var events []models.AssignmentEvent
+	config.DB.Where("task_id = ?", task.ID).Order("created_at ASC, id ASC").Find(&events)
+
+	allocated := allocatedHours([]models.Task{task})
+	logged := loggedHours("task_id = ?", task.ID)
+
+	history := make([]models.AssignmentHistoryEntry, 0, len(assignments))
+	for _, a := range assignments {
+		entry := newHistoryEntry(&a.User, a.IsActive, allocated, logged)
+		entry.Periods = assignmentPeriods(eventsOf(events, a.UserID), a.AssignedAt, a.AssignedBy, a.IsActive, unassignedAt(a.UnassignedAt, a.UpdatedAt))
+		history = append(history, entry)
+	}
+	sortHistory(history)
+
+	utils.SuccessResponse(c, 200, "Assignment history retrieved successfully", history)
+}
+
+// assignProjectTo gives the user an active assignment on the project with the role, reactivating a previous one if present.
+// Active members keep their assignment and role.
+func assignProjectTo(tx *gorm.DB, projectID, userID, assignedBy uint, role models.MemberRole) error {
+	var assignment models.ProjectAssignment
+	err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&assignment).Error
+	if err == nil {
+		if assignment.IsActive {
+			return nil
+		}
+		if err := tx.Model(&assignment).Updates(map[string]interface{}{
+			"is_active":     true,
+			"unassigned_at": nil,
+			"assigned_by":   assignedBy,
+			"assigned_at":   time.Now(),
+			"role":          role,
+		}).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Create(&models.ProjectAssignment{
+		ProjectID:  projectID,
+		UserID:     userID,
+		AssignedBy: assignedBy,
+		Role:       role,
+		IsActive:   true,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.AssignmentEvent{ProjectID: &projectID, UserID: userID, Action: models.AssignmentActionAssigned, Role: role, ActorID: assignedBy}).Error
+}
+
+// unassignProjectFrom deactivates the user's assignment on the project, if active
+func unassignProjectFrom(tx *gorm.DB, projectID, userID, actorID uint) error {
+	result := tx.Model(&models.ProjectAssignment{}).
+		Where("project_id = ? AND user_id = ? AND is_active = ?", projectID, userID, true).
+		Updates(map[string]interface{}{"is_active": false, "unassigned_at": time.Now()})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return tx.Create(&models.AssignmentEvent{ProjectID: &projectID, UserID: userID, Action: models.AssignmentActionUnassigned, ActorID: actorID}).Error
+}
+
+// assignTaskTo gives the user an active assignment on the task, reactivating a previous one if present
+func assignTaskTo(tx *gorm.DB, taskID, userID, assignedBy uint) error {
+	var assignment models.TaskAssignment
+	err := tx.Where("task_id = ? AND user_id = ?", taskID, userID).First(&assignment).Error
+	if err == nil {
+		if assignment.IsActive {
+			return nil
+		}
+		if err := tx.Model(&assignment).Updates(map[string]interface{}{
+			"is_active":     true,
+			"unassigned_at": nil,
+			"assigned_by":   assignedBy,
+			"assigned_at":   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+	} else if err := tx.Create(&models.TaskAssignment{
+		TaskID:     taskID,
+		UserID:     userID,
+		AssignedBy: assignedBy,
+		IsActive:   true,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.AssignmentEvent{TaskID: &taskID, UserID: userID, Action: models.AssignmentActionAssigned, ActorID: assignedBy}).Error
+}
+
+// unassignTaskFrom deactivates the user's assignment on the task, if active
+func unassignTaskFrom(tx *gorm.DB, taskID, userID, actorID uint) error {
+	result := tx.Model(&models.TaskAssignment{}).
+		Where("task_id = ? AND user_id = ? AND is_active = ?", taskID, userID, true).
+		Updates(map[string]interface{}{"is_active": false, "unassigned_at": time.Now()})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return tx.Create(&models.AssignmentEvent{TaskID: &taskID, UserID: userID, Action: models.AssignmentActionUnassigned, ActorID: actorID}).Error
+}
+
+// checkProjectMember checks the user is active and belongs to one of the areas the caller can assign from
+// (see policy.Subject.AssignableAreas), returning the status code and message of the error, or ""
+func checkProjectMember(userID uint, all bool, areaIDs []uint) (int, string) {
+	var user models.User
+	if err := config.DB.Where("is_active = ?", true).First(&user, userID).Error; err != nil {
+		return 404, "Assigned user not found: " + strconv.FormatUint(uint64(userID), 10)
+	}
+	if !all && !user.BelongsToAnyArea(config.DB, areaIDs) {
+		return 403, "Can only assign users from the project's areas"
+	}
+	return 0, ""
+}
+
+// checkTaskAssignee checks the user can work on tasks of the project, returning the status code and message of the error, or ""
+func checkTaskAssignee(project *models.Project, userID uint) (int, string) {
+	var user models.User
+	if err := config.DB.Where("is_active = ?", true).First(&user, userID).Error; err != nil {
+		return 404, "Assigned user not found"
+	}
+	if areaIDs := models.ProjectAreaIDs(config.DB, project); len(areaIDs) > 0 && !user.BelongsToAnyArea(config.DB, areaIDs) {
+		return 400, "Assigned user must belong to one of the project's areas"
+	}
+	if role, ok := models.ProjectMemberRole(config.DB, project.ID, user.ID); ok && !role.CanLogTime() {
+		return 400, "Project viewers cannot be assigned tasks"
+	}
+	return 0, ""
+}
+
+func activeProjectMembers(projectID uint) []models.ProjectAssignment {
+	var members []models.ProjectAssignment
+	config.DB.Preload("User").Where("project_id = ? AND is_active = ?", projectID, true).Order("assigned_at ASC").Find(&members)
+	return members
+}
+
+func activeTaskAssignees(taskID uint) []models.TaskAssignment {
+	var assignees []models.TaskAssignment
+	config.DB.Preload("User").Where("task_id = ? AND is_active = ?", taskID, true).Order("assigned_at ASC").Find(&assignees)
+	return assignees
+}
+
+// assignmentPeriods rebuilds when a user was assigned from their recorded events. Assignments made before
+// events were recorded start at the assignment's date, and inactive ones end when it was deactivated.
+func assignmentPeriods(events []models.AssignmentEvent, assignedAt time.Time, assignedBy uint, active bool, unassignedAt time.Time) []models.AssignmentPeriod {
+	periods := []models.AssignmentPeriod{}
+	var open *models.AssignmentPeriod
+	if len(events) == 0 || events[0].Action != models.AssignmentActionAssigned {
+		open = &models.AssignmentPeriod{From: assignedAt, AssignedBy: assignedBy}
+	}
+	for _, e := range events {
+		switch e.Action {
+		case models.AssignmentActionAssigned:
+			if open == nil {
+				open = &models.AssignmentPeriod{From: e.CreatedAt, AssignedBy: e.ActorID}
+			}
+		case models.AssignmentActionUnassigned:
+			if open != nil {
+				to := e.CreatedAt
+				open.To = &to
+				periods = append(periods, *open)
+				open = nil
+			}
+		}
+	}
+	if open != nil {
+		if !active {
+			open.To = &unassignedAt
+		}
+		periods = append(periods, *open)
+	}
+	return periods
+}
+
+func eventsOf(events []models.AssignmentEvent, userID uint) []models.AssignmentEvent {
+	var userEvents []models.AssignmentEvent
+	for _, e := range events {
+		if e.UserID == userID {
+			userEvents = append(userEvents, e)
+		}
+	}
+	return userEvents
+}
+
+func unassignedAt(at *time.Time, updatedAt time.Time) time.Time {
+	if at != nil {
+		return *at
+	}
+	return updatedAt
+}
+
+// allocatedHours splits the estimate of each task among its active assignees
+func allocatedHours(tasks []models.Task) map[uint]float64 {
+	estimates := make(map[uint]float64, len(tasks))
+	taskIDs := make([]uint, 0, len(tasks))
+	for _, t := range tasks {
+		estimates[t.ID] = t.EstimatedHours
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	var assignments []models.TaskAssignment
+	config.DB.Select("task_id", "user_id").Where("task_id IN ? AND is_active = ?", models.NonEmptyIDs(taskIDs), true).Find(&assignments)
+	assignees := make(map[uint]int, len(tasks))
+	for _, a := range assignments {
+		assignees[a.TaskID]++
+	}
+
+	allocated := make(map[uint]float64)
+	for _, a := range assignments {
+		allocated[a.UserID] += estimates[a.TaskID] / float64(assignees[a.TaskID])
+	}
+	return allocated
+}
+
+// loggedHours sums the hours each user logged on the activities matching the condition
+func loggedHours(condition string, args ...interface{}) map[uint]float64 {
+	var rows []struct {
+		UserID uint
+		Hours  float64
+	}
+	config.DB.Model(&models.Activity{}).Select("user_id, SUM(execution_time) AS hours").Where(condition, args...).Group("user_id").Scan(&rows)
+
+	logged := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		logged[row.UserID] = row.Hours
+	}
+	return logged
+}
+
+func newHistoryEntry(user *models.User, active bool, allocated, logged map[uint]float64) models.AssignmentHistoryEntry {
+	return models.AssignmentHistoryEntry{
+		UserID:         user.ID,
+		FullName:       user.FullName,
+		Email:          user.Email,
+		IsActive:       active,
+		AllocatedHours: roundHours(allocated[user.ID]),
+		LoggedHours:    roundHours(logged[user.ID]),
+	}
+}
+
+// sortHistory lists current assignees first, then by when they joined
+func sortHistory(history []models.AssignmentHistoryEntry) {
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].IsActive != history[j].IsActive {
+			return history[i].IsActive
+		}
+		if len(history[i].Periods) == 0 || len(history[j].Periods) == 0 {
+			return len(history[i].Periods) > 0
+		}
+		return history[i].Periods[0].From.Before(history[j].Periods[0].From)
+	})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jaliko05/time-flow/models"
+)
+
+func TestAssignmentPeriods(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 9, 0, 0, 0, time.UTC) }
+	event := func(action models.AssignmentAction, d int, actor uint) models.AssignmentEvent {
+		return models.AssignmentEvent{UserID: 4, Action: action, CreatedAt: day(d), ActorID: actor}
+	}
+	assigned, unassigned := models.AssignmentActionAssigned, models.AssignmentActionUnassigned
+
+	type period struct {
+		from, to int // Day of the month, to 0 while still assigned
+		by       uint
+	}
+
+	tests := []struct {
+		name       string
+		events     []models.AssignmentEvent
+		active     bool
+		assignedAt int
+		unassigned int
+		want       []period
+	}{
+		{
+			name:       "assigned before events were recorded, still active",
+			active:     true,
+			assignedAt: 1,
+			want:       []period{{1, 0, 1}},
+		},
+		{
+			name:       "assigned before events were recorded, deactivated",
+			assignedAt: 1, unassigned: 6,
+			want: []period{{1, 6, 1}},
+		},
+		{
+			name:   "assigned and still active",
+			events: []models.AssignmentEvent{event(assigned, 2, 3)},
+			active: true,
+			want:   []period{{2, 0, 3}},
+		},
+		{
+			name:   "reassigned after leaving",
+			events: []models.AssignmentEvent{event(assigned, 2, 3), event(unassigned, 5, 3), event(assigned, 9, 8)},
+			active: true,
+			want:   []period{{2, 5, 3}, {9, 0, 8}},
+		},
+		{
+			name:       "first recorded event is an unassignment",
+			events:     []models.AssignmentEvent{event(unassigned, 4, 3)},
+			assignedAt: 1, unassigned: 4,
+			want: []period{{1, 4, 1}},
+		},
+		{
+			name:   "role changes and repeated events do not split periods",
+			events: []models.AssignmentEvent{event(assigned, 2, 3), event(models.AssignmentActionRoleChanged, 3, 3), event(assigned, 4, 8), event(unassigned, 7, 3), event(unassigned, 8, 3)},
+			want:   []period{{2, 7, 3}},
+		},
+		{
+			name:       "inactive without an unassignment event ends at deactivation",
+			events:     []models.AssignmentEvent{event(assigned, 2, 3)},
+			unassigned: 10,
+			want:       []period{{2, 10, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignmentPeriods(tt.events, day(tt.assignedAt), 1, tt.active, day(tt.unassigned))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d periods %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if !p.From.Equal(day(want.from)) || p.AssignedBy != want.by {
+					t.Errorf("period %d from %v by %d, want from day %d by %d", i, p.From, p.AssignedBy, want.from, want.by)
+				}
+				switch {
+				case want.to == 0 && p.To != nil:
+					t.Errorf("period %d ends %v, want still open", i, *p.To)
+				case want.to != 0 && (p.To == nil || !p.To.Equal(day(want.to))):
+					t.Errorf("period %d ends %v, want day %d", i, p.To, want.to)
+				}
+			}
+		})
+	}
+}
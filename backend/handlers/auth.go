@@ -1,14 +1,20 @@
 package handlers
 
 import (
-	"log"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/identity"
 	"github.com/jaliko05/time-flow/models"
 	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
 )
 
+var errInvitationUsed = errors.New("invitation already used")
+
 // Login godoc
 // @Summary Login user
 // @Description Authenticate user with email and password
@@ -28,7 +34,7 @@ func Login(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := config.DB.Preload("Area").Where("email = ? AND is_active = ?", req.Email, true).First(&user).Error; err != nil {
+	if err := config.DB.Preload("Area").Where("LOWER(email) = ? AND is_active = ? AND approval_status = ?", strings.ToLower(strings.TrimSpace(req.Email)), true, models.ApprovalStatusApproved).First(&user).Error; err != nil {
 		utils.ErrorResponse(c, 401, "Invalid email or password")
 		return
 	}
@@ -47,15 +53,16 @@ func Login(c *gin.Context) {
 	response := models.LoginResponse{
 		Token: token,
 		User: models.UserResponse{
-			ID:           user.ID,
-			Email:        user.Email,
-			FullName:     user.FullName,
-			Role:         user.Role,
-			AreaID:       user.AreaID,
-			Area:         user.Area,
-			WorkSchedule: user.WorkSchedule,
-			LunchBreak:   user.LunchBreak,
-			IsActive:     user.IsActive,
+			ID:                 user.ID,
+			Email:              user.Email,
+			FullName:           user.FullName,
+			Role:               user.Role,
+			AreaID:             user.AreaID,
+			Area:               user.Area,
+			WorkSchedule:       user.WorkSchedule,
+			LunchBreak:         user.LunchBreak,
+			IsActive:           user.IsActive,
+			MustChangePassword: user.MustChangePassword,
 		},
 	}
 
@@ -80,87 +87,117 @@ func MicrosoftLogin(c *gin.Context) {
 		return
 	}
 
-	// Validate Microsoft token and get user info
-	msUserInfo, err := utils.ValidateMicrosoftToken(req.AccessToken)
-	if err != nil {
-		utils.ErrorResponse(c, 401, "Invalid Microsoft token: "+err.Error())
+	ssoLogin(c, identity.MicrosoftProviderName, identity.Credentials{AccessToken: req.AccessToken})
+}
+
+// pendingApprovalResponse replies to an SSO login whose account has not been approved yet
+func pendingApprovalResponse(c *gin.Context, user *models.User, message string) {
+	utils.SuccessResponse(c, 202, message, gin.H{
+		"user": models.UserResponse{
+			ID:             user.ID,
+			Email:          user.Email,
+			FullName:       user.FullName,
+			Role:           user.Role,
+			IsActive:       user.IsActive,
+			ApprovalStatus: user.ApprovalStatus,
+		},
+		"pending_approval": true,
+		"approval_status":  user.ApprovalStatus,
+	})
+}
+
+// Me godoc
+// @Summary Get current user
+// @Description Get the authenticated user's information
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserResponse
+// @Failure 401 {object} utils.Response
+// @Router /auth/me [get]
+func Me(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var user models.User
+	if err := config.DB.Preload("Area").Preload("Areas.Area").First(&user, userID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
 		return
 	}
 
-	// Look for existing user by Microsoft ID or email
-	var user models.User
-	result := config.DB.Preload("Area").Where("microsoft_id = ? OR (email = ? AND auth_provider = ?)",
-		msUserInfo.ID, msUserInfo.Mail, "microsoft").First(&user)
-
-	if result.Error != nil {
-		// User doesn't exist, create new user
-		fullName := msUserInfo.DisplayName
-		if fullName == "" {
-			fullName = msUserInfo.GivenName + " " + msUserInfo.Surname
-		}
+	response := models.UserResponse{
+		ID:                 user.ID,
+		Email:              user.Email,
+		FullName:           user.FullName,
+		Role:               user.Role,
+		AreaID:             user.AreaID,
+		Area:               user.Area,
+		Areas:              user.Areas,
+		WorkSchedule:       user.WorkSchedule,
+		LunchBreak:         user.LunchBreak,
+		IsActive:           user.IsActive,
+		ApprovalStatus:     user.ApprovalStatus,
+		MustChangePassword: user.MustChangePassword,
+		Settings:           loadUserSettings(user.ID),
+	}
 
-		user = models.User{
-			Email:                msUserInfo.Mail,
-			FullName:             fullName,
-			Role:                 models.RoleUser, // Default role
-			MicrosoftID:          &msUserInfo.ID,
-			MicrosoftAccessToken: &req.AccessToken,
-			AuthProvider:         "microsoft",
-			IsActive:             false, // Pending SuperAdmin approval
-		}
+	// Banner information when a SuperAdmin is acting as this user
+	if info := currentImpersonation(c); info != nil {
+		response.IsImpersonated = true
+		response.Impersonation = info
+	}
 
-		if err := config.DB.Create(&user).Error; err != nil {
-			utils.ErrorResponse(c, 500, "Failed to create user")
-			return
-		}
+	utils.SuccessResponse(c, 200, "User retrieved successfully", response)
+}
 
-		// Reload to get Area relation
-		config.DB.Preload("Area").First(&user, user.ID)
-
-		// Return special response for pending approval
-		utils.SuccessResponse(c, 202, "Account created. Waiting for administrator approval", gin.H{
-			"user": models.UserResponse{
-				ID:       user.ID,
-				Email:    user.Email,
-				FullName: user.FullName,
-				Role:     user.Role,
-				IsActive: user.IsActive,
-			},
-			"pending_approval": true,
-		})
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the authenticated user's password. Clears the forced password change flag and returns a fresh token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/change-password [post]
+func ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
 		return
-	} else {
-		// User exists, check if active
-		if !user.IsActive {
-			utils.ErrorResponse(c, 401, "User account is inactive")
-			return
-		}
+	}
 
-		// Update Microsoft ID and token if needed
-		if user.MicrosoftID == nil || *user.MicrosoftID == "" {
-			user.MicrosoftID = &msUserInfo.ID
-		}
-		// Always update the access token on login
-		user.MicrosoftAccessToken = &req.AccessToken
-		user.AuthProvider = "microsoft"
-
-		log.Printf("Updating user %d with Microsoft token (length: %d)", user.ID, len(req.AccessToken))
-
-		// Save with specific fields to ensure update
-		if err := config.DB.Model(&user).Updates(map[string]interface{}{
-			"microsoft_id":           user.MicrosoftID,
-			"microsoft_access_token": user.MicrosoftAccessToken,
-			"auth_provider":          user.AuthProvider,
-		}).Error; err != nil {
-			log.Printf("Error updating user token: %v", err)
-			utils.ErrorResponse(c, 500, "Failed to update user token")
-			return
-		}
+	var user models.User
+	if err := config.DB.Preload("Area").First(&user, userID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	if user.Password == "" || !user.CheckPassword(req.CurrentPassword) {
+		utils.ErrorResponse(c, 401, "Current password is incorrect")
+		return
+	}
+	if req.CurrentPassword == req.NewPassword {
+		utils.ErrorResponse(c, 400, "New password must be different from the current password")
+		return
+	}
 
-		log.Printf("Successfully updated user %d Microsoft token", user.ID)
+	if err := user.SetPassword(req.NewPassword); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to change password")
+		return
+	}
+	user.MustChangePassword = false
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password":             user.Password,
+		"must_change_password": false,
+	}).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to change password")
+		return
 	}
 
-	// Generate JWT token
 	token, err := utils.GenerateToken(&user)
 	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to generate token")
@@ -182,45 +219,12 @@ func MicrosoftLogin(c *gin.Context) {
 		},
 	}
 
-	utils.SuccessResponse(c, 200, "Login successful", response)
-}
-
-// Me godoc
-// @Summary Get current user
-// @Description Get the authenticated user's information
-// @Tags auth
-// @Produce json
-// @Security BearerAuth
-// @Success 200 {object} UserResponse
-// @Failure 401 {object} utils.Response
-// @Router /auth/me [get]
-func Me(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-
-	var user models.User
-	if err := config.DB.Preload("Area").First(&user, userID).Error; err != nil {
-		utils.ErrorResponse(c, 404, "User not found")
-		return
-	}
-
-	response := models.UserResponse{
-		ID:           user.ID,
-		Email:        user.Email,
-		FullName:     user.FullName,
-		Role:         user.Role,
-		AreaID:       user.AreaID,
-		Area:         user.Area,
-		WorkSchedule: user.WorkSchedule,
-		LunchBreak:   user.LunchBreak,
-		IsActive:     user.IsActive,
-	}
-
-	utils.SuccessResponse(c, 200, "User retrieved successfully", response)
+	utils.SuccessResponse(c, 200, "Password changed successfully", response)
 }
 
 // Register godoc
 // @Summary Register new user
-// @Description Public endpoint to register a new user account with role 'user'
+// @Description Public endpoint to register a new account from an invitation. The role and area come from the invitation. Without an invitation token, registration is only allowed when OPEN_REGISTRATION_ENABLED is true and always creates a 'user' account.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -236,25 +240,83 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
 	// Check if email already exists
 	var existingUser models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+	if err := config.DB.Where("LOWER(email) = ?", email).First(&existingUser).Error; err == nil {
 		utils.ErrorResponse(c, 400, "Email already exists")
 		return
 	}
 
+	// Without an invitation the account gets no area; an admin adds it to one later
 	user := models.User{
-		Email:    req.Email,
+		Email:    email,
 		Password: req.Password,
 		FullName: req.FullName,
 		Role:     models.RoleUser, // Force user role for public registration
-		AreaID:   req.AreaID,
 		IsActive: true,
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		utils.ErrorResponse(c, 500, "Failed to create user")
-		return
+	if req.InvitationToken == "" {
+		if !utils.IsOpenRegistrationEnabled() {
+			utils.ErrorResponse(c, 403, "Registration requires an invitation")
+			return
+		}
+
+		if err := config.DB.Create(&user).Error; err != nil {
+			utils.ErrorResponse(c, 500, "Failed to create user")
+			return
+		}
+	} else {
+		var invitation models.Invitation
+		if err := config.DB.Where("token_hash = ?", utils.HashToken(req.InvitationToken)).First(&invitation).Error; err != nil {
+			utils.ErrorResponse(c, 400, "Invalid invitation token")
+			return
+		}
+		if !invitation.IsUsable() {
+			utils.ErrorResponse(c, 400, "Invitation is "+string(invitation.CurrentStatus()))
+			return
+		}
+		if !strings.EqualFold(invitation.Email, email) {
+			utils.ErrorResponse(c, 400, "Email does not match the invitation")
+			return
+		}
+
+		// Role and area are fixed by the invitation
+		user.Email = invitation.Email
+		user.Role = invitation.Role
+		user.AreaID = invitation.AreaID
+
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+
+			// Mark as accepted only if nobody used it concurrently (single use)
+			now := time.Now()
+			result := tx.Model(&models.Invitation{}).
+				Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+				Updates(map[string]interface{}{
+					"accepted_at":      now,
+					"accepted_user_id": user.ID,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errInvitationUsed
+			}
+			return nil
+		})
+		if errors.Is(err, errInvitationUsed) {
+			utils.ErrorResponse(c, 400, "Invitation has already been used")
+			return
+		}
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to create user")
+			return
+		}
 	}
 
 	// Reload to get Area relation
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm/clause"
+)
+
+// GetBudgetReport godoc
+// @Summary Get projects over budget
+// @Description List the projects hosted in or shared with the areas where the caller holds stats.view whose hour or money budget usage reaches min_percent (default 100, i.e. over budget)
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param min_percent query number false "Minimum budget usage percentage (default 100)"
+// @Param area_id query int false "Filter by host or shared area ID"
+// @Param active query bool false "Filter by active status"
+// @Success 200 {object} utils.Response{data=[]models.BudgetReportItem}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /stats/budgets [get]
+func GetBudgetReport(c *gin.Context) {
+	minPercent, err := strconv.ParseFloat(c.DefaultQuery("min_percent", "100"), 64)
+	if err != nil || minPercent < 0 {
+		utils.ErrorResponse(c, 400, "Invalid min_percent")
+		return
+	}
+
+	// Projects hosted in or shared with areas where the caller can view statistics
+	query := policy.FromContext(c).ScopeProjectAreas(config.DB.Model(&models.Project{}), models.PermStatsView).
+		Where("estimated_hours > 0 OR budget_hours > 0 OR budget_amount > 0")
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = models.ScopeProjectsInAreas(query, []uint{uint(areaID)})
+		}
+	}
+	if activeStr := c.Query("active"); activeStr == "true" || activeStr == "false" {
+		query = query.Where("is_active = ?", activeStr == "true")
+	}
+
+	var projects []models.Project
+	if err := query.Find(&projects).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve projects")
+		return
+	}
+
+	items := []models.BudgetReportItem{}
+	for _, project := range projects {
+		item := models.BudgetReportItem{
+			ProjectID:   project.ID,
+			ProjectName: project.Name,
+			AreaID:      project.AreaID,
+			AreaIDs:     models.ProjectAreaIDs(config.DB, &project),
+			Currency:    utils.GetBillingCurrency(),
+			Budgets:     project.BudgetUsage(),
+		}
+		for _, usage := range item.Budgets {
+			if usage.Percent > item.MaxPercent {
+				item.MaxPercent = usage.Percent
+			}
+		}
+		if item.MaxPercent >= minPercent {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].MaxPercent > items[j].MaxPercent })
+
+	utils.SuccessResponse(c, 200, "Budget report retrieved successfully", items)
+}
+
+// refreshProjectUsage recomputes the hours and cost logged on a project and notifies budget thresholds crossed
+func refreshProjectUsage(projectID uint) {
+	var project models.Project
+	if err := config.DB.First(&project, projectID).Error; err != nil {
+		return
+	}
+	if err := project.UpdateUsedHours(config.DB); err != nil {
+		log.Printf("Warning: Failed to update used hours of project %d: %v", project.ID, err)
+		return
+	}
+	checkBudgetThresholds(&project)
+}
+
+// checkBudgetThresholds records the thresholds the project's usage has reached and notifies each one once.
+// Thresholds usage has dropped below again are cleared so they notify the next time they are crossed.
+func checkBudgetThresholds(project *models.Project) {
+	usages := project.BudgetUsage()
+	kinds := make([]models.BudgetKind, 0, len(usages))
+
+	for _, usage := range usages {
+		kinds = append(kinds, usage.Kind)
+		config.DB.Where("project_id = ? AND kind = ? AND threshold > ?", project.ID, usage.Kind, usage.Percent).
+			Delete(&models.ProjectBudgetAlert{})
+
+		// Only the highest newly crossed threshold is notified
+		crossed := 0
+		for _, threshold := range project.Thresholds() {
+			if usage.Percent < float64(threshold) {
+				break
+			}
+			alert := models.ProjectBudgetAlert{
+				ProjectID: project.ID,
+				Kind:      usage.Kind,
+				Threshold: threshold,
+				Used:      usage.Used,
+				Budget:    usage.Budget,
+			}
+			result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+			if result.Error == nil && result.RowsAffected > 0 {
+				crossed = threshold
+			}
+		}
+		if crossed > 0 {
+			notifyBudgetThreshold(project, usage, crossed)
+		}
+	}
+
+	// Budgets that were removed no longer have alerts
+	query := config.DB.Where("project_id = ?", project.ID)
+	if len(kinds) > 0 {
+		query = query.Where("kind NOT IN ?", kinds)
+	}
+	query.Delete(&models.ProjectBudgetAlert{})
+}
+
+// notifyBudgetThreshold notifies the project's managers, in app and by email
+func notifyBudgetThreshold(project *models.Project, usage models.BudgetUsage, threshold int) {
+	unit := "hours"
+	if usage.Kind == models.BudgetKindAmount {
+		unit = utils.GetBillingCurrency()
+	}
+	title := fmt.Sprintf("Project %s reached %d%% of its %s budget", project.Name, threshold, usage.Kind)
+	message := fmt.Sprintf("%s has used %.2f of %.2f %s (%.0f%%).", project.Name, usage.Used, usage.Budget, unit, usage.Percent)
+
+	recipients := policy.ProjectManagers(project)
+
+	for _, user := range recipients {
+		notification := models.Notification{
+			UserID:    user.ID,
+			Type:      models.NotificationTypeBudgetThreshold,
+			Title:     title,
+			Message:   message,
+			ProjectID: &project.ID,
+		}
+		if err := config.DB.Create(&notification).Error; err != nil {
+			log.Printf("Warning: Failed to notify user %d about project %d: %v", user.ID, project.ID, err)
+			continue
+		}
+
+		email := user.Email
+		go func() {
+			if err := utils.SendEmail(email, title, message+"\n\n"+utils.GetAppURL()+"/projects/"+strconv.Itoa(int(project.ID))); err != nil {
+				log.Printf("Warning: Failed to email budget alert to %s: %v", email, err)
+			}
+		}()
+	}
+}
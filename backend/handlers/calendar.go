@@ -44,28 +44,28 @@ func GetCalendarEvents(c *gin.Context) {
 		return
 	}
 
-	// Determinar rango de fechas
+	// Determinar rango de fechas en la zona horaria del usuario
+	settings := loadUserSettings(user.ID)
 	var startDate, endDate time.Time
-	var err error
 
 	if req.StartDate != "" && req.EndDate != "" {
-		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		startDay, err := time.Parse("2006-01-02", req.StartDate)
 		if err != nil {
 			utils.ErrorResponse(c, 400, "Invalid start_date format. Use YYYY-MM-DD")
 			return
 		}
 
-		endDate, err = time.Parse("2006-01-02", req.EndDate)
+		endDay, err := time.Parse("2006-01-02", req.EndDate)
 		if err != nil {
 			utils.ErrorResponse(c, 400, "Invalid end_date format. Use YYYY-MM-DD")
 			return
 		}
-		endDate = endDate.Add(24 * time.Hour) // Incluir todo el día final
+		startDate = settings.StartOfDay(startDay)
+		endDate = settings.StartOfDay(endDay.AddDate(0, 0, 1)) // Incluir todo el día final
 	} else {
 		// Por defecto: eventos de hoy
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		endDate = startDate.Add(24 * time.Hour)
+		startDate = settings.StartOfDay(settings.Today())
+		endDate = settings.StartOfDay(settings.Today().AddDate(0, 0, 1))
 	}
 
 	// Obtener eventos usando el token guardado
@@ -75,31 +75,12 @@ func GetCalendarEvents(c *gin.Context) {
 		return
 	}
 
-	// Convertir a formato de respuesta
-	response := make([]models.CalendarEventResponse, 0, len(events))
-	for _, event := range events {
-		startTime, _ := time.Parse("2006-01-02T15:04:05.0000000", event.Start.DateTime)
-		endTime, _ := time.Parse("2006-01-02T15:04:05.0000000", event.End.DateTime)
-		duration := endTime.Sub(startTime).Hours()
-
-		response = append(response, models.CalendarEventResponse{
-			ID:          event.ID,
-			Subject:     event.Subject,
-			Description: event.BodyPreview,
-			StartTime:   startTime,
-			EndTime:     endTime,
-			Location:    event.Location.DisplayName,
-			IsOnline:    event.IsOnlineMeeting,
-			Duration:    duration,
-		})
-	}
-
-	utils.SuccessResponse(c, 200, "Calendar events retrieved successfully", response)
+	utils.SuccessResponse(c, 200, "Calendar events retrieved successfully", calendarEventsResponse(events, settings.Location()))
 }
 
 // GetTodayCalendarEvents godoc
 // @Summary Get today's calendar events
-// @Description Get calendar events for today from Microsoft
+// @Description Get calendar events for today, in the user's timezone, from Microsoft
 // @Tags calendar
 // @Produce json
 // @Security BearerAuth
@@ -126,12 +107,54 @@ func GetTodayCalendarEvents(c *gin.Context) {
 
 	log.Printf("User %d has Microsoft token (length: %d)", user.ID, len(*user.MicrosoftAccessToken))
 
-	events, err := utils.GetTodayEvents(*user.MicrosoftAccessToken)
+	settings := loadUserSettings(user.ID)
+	events, err := utils.GetTodayEvents(*user.MicrosoftAccessToken, settings.Location())
 	if err != nil {
 		utils.ErrorResponse(c, 401, "Failed to get calendar events: "+err.Error())
 		return
 	}
 
+	utils.SuccessResponse(c, 200, "Today's calendar events retrieved successfully", calendarEventsResponse(events, settings.Location()))
+}
+
+// GetWeekCalendarEvents godoc
+// @Summary Get this week's calendar events
+// @Description Get calendar events for the current week from Microsoft, using the user's timezone and week start
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.CalendarEventResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /calendar/week [get]
+func GetWeekCalendarEvents(c *gin.Context) {
+	// Obtener usuario autenticado
+	userID, _ := c.Get("user_id")
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	// Verificar que el usuario tenga token de Microsoft
+	if user.MicrosoftAccessToken == nil || *user.MicrosoftAccessToken == "" {
+		utils.ErrorResponse(c, 401, "No Microsoft calendar access. Please logout and login again with Microsoft to sync your calendar.")
+		return
+	}
+
+	settings := loadUserSettings(user.ID)
+	events, err := utils.GetWeekEvents(*user.MicrosoftAccessToken, settings.Location(), settings.WeekStart)
+	if err != nil {
+		utils.ErrorResponse(c, 401, "Failed to get calendar events: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Week calendar events retrieved successfully", calendarEventsResponse(events, settings.Location()))
+}
+
+// calendarEventsResponse converts Graph events (requested in UTC) to the user's timezone
+func calendarEventsResponse(events []utils.CalendarEvent, loc *time.Location) []models.CalendarEventResponse {
 	response := make([]models.CalendarEventResponse, 0, len(events))
 	for _, event := range events {
 		startTime, _ := time.Parse("2006-01-02T15:04:05.0000000", event.Start.DateTime)
@@ -142,13 +165,12 @@ func GetTodayCalendarEvents(c *gin.Context) {
 			ID:          event.ID,
 			Subject:     event.Subject,
 			Description: event.BodyPreview,
-			StartTime:   startTime,
-			EndTime:     endTime,
+			StartTime:   startTime.In(loc),
+			EndTime:     endTime.In(loc),
 			Location:    event.Location.DisplayName,
 			IsOnline:    event.IsOnlineMeeting,
 			Duration:    duration,
 		})
 	}
-
-	utils.SuccessResponse(c, 200, "Today's calendar events retrieved successfully", response)
+	return response
 }
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// Weight of the skill match in the candidate score; the rest is free capacity
+const candidateSkillWeight = 0.7
+
+// requiredSkill is a skill a task or project asks for
+type requiredSkill struct {
+	SkillID  uint
+	Name     string
+	MinLevel int
+}
+
+// GetTaskCandidates godoc
+// @Summary Suggest users for a task
+// @Description Rank the members of the project's host and shared areas by skill match (70%) and free capacity (30%).
+// @Description Capacity is the work schedule hours until the task's due date (or the given number of weeks) minus the remaining estimated hours of their open tasks.
+// @Description Tasks without required skills use the project's.
+// @Tags skills
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param weeks query int false "Capacity horizon in weeks when the task has no due date (default 2)"
+// @Param limit query int false "Maximum number of candidates (default 10)"
+// @Success 200 {object} utils.Response{data=models.CandidatesResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/candidates [get]
+func GetTaskCandidates(c *gin.Context) {
+	var task models.Task
+	if err := config.DB.Preload("Project").Preload("RequiredSkills.Skill").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+	if task.Project.AreaID == nil {
+		utils.ErrorResponse(c, 400, "Candidates are only suggested for area projects")
+		return
+	}
+
+	required := make([]requiredSkill, 0, len(task.RequiredSkills))
+	for _, s := range task.RequiredSkills {
+		required = append(required, requiredSkill{s.SkillID, s.Skill.Name, s.MinLevel})
+	}
+	if len(required) == 0 {
+		var projectSkills []models.ProjectSkill
+		config.DB.Preload("Skill").Where("project_id = ?", task.ProjectID).Find(&projectSkills)
+		for _, s := range projectSkills {
+			required = append(required, requiredSkill{s.SkillID, s.Skill.Name, s.MinLevel})
+		}
+	}
+
+	var assigned []uint
+	config.DB.Model(&models.TaskAssignment{}).Where("task_id = ? AND is_active = ?", task.ID, true).Pluck("user_id", &assigned)
+
+	respondCandidates(c, models.ProjectAreaIDs(config.DB, &task.Project), required, assigned, task.DueDate)
+}
+
+// GetProjectCandidates godoc
+// @Summary Suggest users for a project
+// @Description Rank the members of the project's host and shared areas by skill match (70%) and free capacity (30%).
+// @Description Capacity is the work schedule hours until the project's due date (or the given number of weeks) minus the remaining estimated hours of their open tasks.
+// @Tags skills
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param weeks query int false "Capacity horizon in weeks when the project has no due date (default 2)"
+// @Param limit query int false "Maximum number of candidates (default 10)"
+// @Success 200 {object} utils.Response{data=models.CandidatesResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/candidates [get]
+func GetProjectCandidates(c *gin.Context) {
+	var project models.Project
+	if err := config.DB.Preload("RequiredSkills.Skill").First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+	if project.AreaID == nil {
+		utils.ErrorResponse(c, 400, "Candidates are only suggested for area projects")
+		return
+	}
+
+	required := make([]requiredSkill, 0, len(project.RequiredSkills))
+	for _, s := range project.RequiredSkills {
+		required = append(required, requiredSkill{s.SkillID, s.Skill.Name, s.MinLevel})
+	}
+
+	var assigned []uint
+	config.DB.Model(&models.ProjectAssignment{}).Where("project_id = ? AND is_active = ?", project.ID, true).Pluck("user_id", &assigned)
+
+	respondCandidates(c, models.ProjectAreaIDs(config.DB, &project), required, assigned, project.DueDate)
+}
+
+// respondCandidates ranks the active members of the project's host and shared areas and writes the response
+func respondCandidates(c *gin.Context, areaIDs []uint, required []requiredSkill, assigned []uint, dueDate *time.Time) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "2"))
+	if err != nil || weeks < 1 || weeks > 12 {
+		utils.ErrorResponse(c, 400, "weeks must be between 1 and 12")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		utils.ErrorResponse(c, 400, "Invalid limit")
+		return
+	}
+
+	// The horizon starts today for the person asking and ends at the due date when there is one ahead
+	from := currentUserSettings(c).Today()
+	to := from.AddDate(0, 0, weeks*7-1)
+	if dueDate != nil {
+		due := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+		if !due.Before(from) {
+			to = due
+		}
+	}
+
+	candidates, err := rankCandidates(areaIDs, required, assigned, from, to)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to rank candidates")
+		return
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	utils.SuccessResponse(c, 200, "Candidates retrieved successfully", models.CandidatesResponse{
+		From:       from,
+		To:         to,
+		Candidates: candidates,
+	})
+}
+
+// rankCandidates scores every active member of the areas by skill match and free capacity between from and to
+func rankCandidates(areaIDs []uint, required []requiredSkill, assigned []uint, from, to time.Time) ([]models.CandidateSuggestion, error) {
+	var users []models.User
+	if err := config.DB.
+		Where("id IN (?)", config.DB.Model(&models.UserArea{}).Select("user_id").Where("area_id IN ?", areaIDs)).
+		Where("is_active = ? AND approval_status = ?", true, models.ApprovalStatusApproved).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return []models.CandidateSuggestion{}, nil
+	}
+
+	userIDs := make([]uint, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.ID)
+	}
+
+	// Levels of the required skills, per user
+	levels := make(map[uint]map[uint]int, len(users))
+	if len(required) > 0 {
+		skillIDs := make([]uint, 0, len(required))
+		for _, s := range required {
+			skillIDs = append(skillIDs, s.SkillID)
+		}
+		var userSkills []models.UserSkill
+		if err := config.DB.Where("user_id IN ? AND skill_id IN ?", userIDs, skillIDs).Find(&userSkills).Error; err != nil {
+			return nil, err
+		}
+		for _, us := range userSkills {
+			if levels[us.UserID] == nil {
+				levels[us.UserID] = make(map[uint]int)
+			}
+			levels[us.UserID][us.SkillID] = us.Level
+		}
+	}
+
+	// Remaining estimated hours of open tasks, split evenly between their assignees
+	var open []struct {
+		UserID uint
+		Hours  float64
+	}
+	if err := config.DB.Raw(`
+		SELECT ta.user_id, SUM(GREATEST(t.estimated_hours - t.used_hours, 0) / (
+			SELECT COUNT(*) FROM task_assignments x
+			WHERE x.task_id = t.id AND x.is_active = true AND x.deleted_at IS NULL)) AS hours
+		FROM task_assignments ta
+		JOIN tasks t ON t.id = ta.task_id AND t.deleted_at IS NULL
+		WHERE ta.user_id IN ? AND ta.is_active = true AND ta.deleted_at IS NULL AND t.status <> ?
+		GROUP BY ta.user_id`, userIDs, models.TaskStatusCompleted).Scan(&open).Error; err != nil {
+		return nil, err
+	}
+	openHours := make(map[uint]float64, len(open))
+	for _, o := range open {
+		openHours[o.UserID] = o.Hours
+	}
+
+	isAssigned := make(map[uint]bool, len(assigned))
+	for _, id := range assigned {
+		isAssigned[id] = true
+	}
+
+	candidates := make([]models.CandidateSuggestion, 0, len(users))
+	for i := range users {
+		user := &users[i]
+		candidate := models.CandidateSuggestion{
+			UserID:          user.ID,
+			FullName:        user.FullName,
+			Email:           user.Email,
+			MatchedSkills:   []models.SkillGap{},
+			MissingSkills:   []models.SkillGap{},
+			ScheduleHours:   roundHours(user.ScheduledHours(from, to)),
+			OpenHours:       roundHours(openHours[user.ID]),
+			AlreadyAssigned: isAssigned[user.ID],
+		}
+		candidate.FreeHours = roundHours(candidate.ScheduleHours - candidate.OpenHours)
+
+		// Each required skill counts as level / minimum level, capped at 1
+		match := 1.0
+		if len(required) > 0 {
+			total := 0.0
+			for _, s := range required {
+				level := levels[user.ID][s.SkillID]
+				gap := models.SkillGap{SkillID: s.SkillID, Name: s.Name, MinLevel: s.MinLevel, Level: level}
+				if level >= s.MinLevel {
+					candidate.MatchedSkills = append(candidate.MatchedSkills, gap)
+				} else {
+					candidate.MissingSkills = append(candidate.MissingSkills, gap)
+				}
+				total += math.Min(float64(level)/float64(s.MinLevel), 1)
+			}
+			match = total / float64(len(required))
+		}
+
+		capacity := 0.0
+		if candidate.ScheduleHours > 0 {
+			capacity = math.Max(0, math.Min(candidate.FreeHours/candidate.ScheduleHours, 1))
+		}
+
+		candidate.SkillMatch = roundHours(match * 100)
+		candidate.Score = roundHours((candidateSkillWeight*match + (1-candidateSkillWeight)*capacity) * 100)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].FreeHours > candidates[j].FreeHours
+	})
+	return candidates, nil
+}
+
+// roundHours rounds to one decimal
+func roundHours(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetClients godoc
+// @Summary Get clients
+// @Description Get the clients projects can be linked to. With area_id, returns the clients available in that area (shared and area clients).
+// @Tags clients
+// @Produce json
+// @Security BearerAuth
+// @Param area_id query int false "Filter by area ID"
+// @Param search query string false "Search by name or tax ID"
+// @Param include_inactive query bool false "Include inactive clients"
+// @Success 200 {object} utils.Response{data=[]models.Client}
+// @Failure 401 {object} utils.Response
+// @Router /clients [get]
+func GetClients(c *gin.Context) {
+	query := config.DB.Model(&models.Client{})
+
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = query.Where("area_id IS NULL OR area_id = ?", uint(areaID))
+		}
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("name ILIKE ? OR tax_id ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	if c.Query("include_inactive") != "true" {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var clients []models.Client
+	if err := query.Order("name ASC").Find(&clients).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve clients")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Clients retrieved successfully", clients)
+}
+
+// GetClient godoc
+// @Summary Get client by ID
+// @Description Get a client by ID
+// @Tags clients
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Client ID"
+// @Success 200 {object} utils.Response{data=models.Client}
+// @Failure 404 {object} utils.Response
+// @Router /clients/{id} [get]
+func GetClient(c *gin.Context) {
+	var client models.Client
+	if err := config.DB.Preload("Area").First(&client, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Client not found")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Client retrieved successfully", client)
+}
+
+// CreateClient godoc
+// @Summary Create client
+// @Description Create a client for an area, or one shared by every area (requires the global clients.manage permission)
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client body CreateClientRequest true "Client data"
+// @Success 201 {object} utils.Response{data=models.Client}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /clients [post]
+func CreateClient(c *gin.Context) {
+	var req models.CreateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermClientsManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only add clients to areas you manage")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if clientNameTaken(name, req.AreaID, 0) {
+		utils.ErrorResponse(c, 400, "A client with this name already exists")
+		return
+	}
+
+	client := models.Client{
+		Name:        name,
+		TaxID:       strings.TrimSpace(req.TaxID),
+		Email:       req.Email,
+		Phone:       req.Phone,
+		Address:     req.Address,
+		ContactName: req.ContactName,
+		AreaID:      req.AreaID,
+		IsActive:    true,
+		CreatedBy:   c.MustGet("user_id").(uint),
+	}
+	if err := config.DB.Create(&client).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create client")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Client created successfully", client)
+}
+
+// UpdateClient godoc
+// @Summary Update client
+// @Description Update a client. Issued invoices keep showing the client as it is now.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Client ID"
+// @Param client body UpdateClientRequest true "Client data"
+// @Success 200 {object} utils.Response{data=models.Client}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /clients/{id} [put]
+func UpdateClient(c *gin.Context) {
+	var req models.UpdateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var client models.Client
+	if err := config.DB.First(&client, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Client not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermClientsManage, client.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if clientNameTaken(name, client.AreaID, client.ID) {
+			utils.ErrorResponse(c, 400, "A client with this name already exists")
+			return
+		}
+		updates["name"] = name
+	}
+	if req.TaxID != nil {
+		updates["tax_id"] = strings.TrimSpace(*req.TaxID)
+	}
+	if req.Email != nil {
+		updates["email"] = *req.Email
+	}
+	if req.Phone != nil {
+		updates["phone"] = *req.Phone
+	}
+	if req.Address != nil {
+		updates["address"] = *req.Address
+	}
+	if req.ContactName != nil {
+		updates["contact_name"] = *req.ContactName
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if err := config.DB.Model(&client).Updates(updates).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update client")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Client updated successfully", client)
+}
+
+// DeleteClient godoc
+// @Summary Delete client
+// @Description Delete a client without projects or invoices. Deactivate it instead to keep its history.
+// @Tags clients
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Client ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /clients/{id} [delete]
+func DeleteClient(c *gin.Context) {
+	var client models.Client
+	if err := config.DB.First(&client, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Client not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermClientsManage, client.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var projects, invoices int64
+	config.DB.Model(&models.Project{}).Where("client_id = ?", client.ID).Count(&projects)
+	config.DB.Model(&models.Invoice{}).Where("client_id = ?", client.ID).Count(&invoices)
+	if projects > 0 || invoices > 0 {
+		utils.ErrorResponse(c, 400, "The client has projects or invoices. Deactivate it instead")
+		return
+	}
+
+	if err := config.DB.Delete(&client).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete client")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Client deleted successfully", nil)
+}
+
+// checkProjectClient checks a project of the area can be linked to the client, returning an error message or ""
+func checkProjectClient(clientID uint, areaID *uint) string {
+	var client models.Client
+	if err := config.DB.First(&client, clientID).Error; err != nil {
+		return "Client not found"
+	}
+	if !client.IsActive {
+		return "The client is inactive"
+	}
+	if !client.AvailableIn(areaID) {
+		return "The client belongs to another area"
+	}
+	return ""
+}
+
+func clientNameTaken(name string, areaID *uint, excludeID uint) bool {
+	query := config.DB.Model(&models.Client{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID)
+	if areaID == nil {
+		query = query.Where("area_id IS NULL")
+	} else {
+		query = query.Where("area_id = ?", *areaID)
+	}
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
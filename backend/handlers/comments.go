@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
 )
 
@@ -21,9 +22,7 @@ import (
 // @Failure 401 {object} utils.Response
 // @Router /comments [get]
 func GetComments(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
+	subject := policy.FromContext(c)
 
 	query := config.DB.Preload("User")
 
@@ -51,24 +50,9 @@ func GetComments(c *gin.Context) {
 		}
 
 		// Check access permissions
-		role := userRole.(models.Role)
-		if role == models.RoleUser {
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, userID.(uint), true).First(&assignment).Error
-			if err != nil {
-				utils.ErrorResponse(c, 403, "Access denied")
-				return
-			}
-		} else if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			if project.AreaID == nil || *project.AreaID != *areaID {
-				utils.ErrorResponse(c, 403, "Access denied")
-				return
-			}
+		if !subject.CanViewProject(&project) {
+			utils.ErrorResponse(c, 403, "Access denied")
+			return
 		}
 
 		query = query.Where("project_id = ?", projectID)
@@ -88,25 +72,10 @@ func GetComments(c *gin.Context) {
 			return
 		}
 
-		// Check access permissions based on project
-		role := userRole.(models.Role)
-		if role == models.RoleUser {
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", task.Project.ID, userID.(uint), true).First(&assignment).Error
-			if err != nil {
-				utils.ErrorResponse(c, 403, "Access denied")
-				return
-			}
-		} else if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			if task.Project.AreaID == nil || *task.Project.AreaID != *areaID {
-				utils.ErrorResponse(c, 403, "Access denied")
-				return
-			}
+		// Check access permissions
+		if !subject.CanViewTask(&task) {
+			utils.ErrorResponse(c, 403, "Access denied")
+			return
 		}
 
 		query = query.Where("task_id = ?", taskID)
@@ -136,8 +105,6 @@ func GetComments(c *gin.Context) {
 // @Router /comments [post]
 func CreateComment(c *gin.Context) {
 	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var req models.CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -157,7 +124,7 @@ func CreateComment(c *gin.Context) {
 	}
 
 	// Verify access to project or task
-	role := userRole.(models.Role)
+	subject := policy.FromContext(c)
 
 	if req.ProjectID != nil {
 		var project models.Project
@@ -167,23 +134,9 @@ func CreateComment(c *gin.Context) {
 		}
 
 		// Check permissions
-		if role == models.RoleUser {
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, userID.(uint), true).First(&assignment).Error
-			if err != nil {
-				utils.ErrorResponse(c, 403, "You can only comment on projects assigned to you")
-				return
-			}
-		} else if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			if project.AreaID == nil || *project.AreaID != *areaID {
-				utils.ErrorResponse(c, 403, "You can only comment on projects in your area")
-				return
-			}
+		if !subject.CanViewProject(&project) {
+			utils.ErrorResponse(c, 403, "You can only comment on projects you have access to")
+			return
 		}
 	}
 
@@ -194,24 +147,10 @@ func CreateComment(c *gin.Context) {
 			return
 		}
 
-		// Check permissions based on project
-		if role == models.RoleUser {
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", task.Project.ID, userID.(uint), true).First(&assignment).Error
-			if err != nil {
-				utils.ErrorResponse(c, 403, "You can only comment on tasks in projects assigned to you")
-				return
-			}
-		} else if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			if task.Project.AreaID == nil || *task.Project.AreaID != *areaID {
-				utils.ErrorResponse(c, 403, "You can only comment on tasks in projects in your area")
-				return
-			}
+		// Check permissions
+		if !subject.CanViewTask(&task) {
+			utils.ErrorResponse(c, 403, "You can only comment on tasks you have access to")
+			return
 		}
 	}
 
@@ -298,7 +237,6 @@ func UpdateComment(c *gin.Context) {
 func DeleteComment(c *gin.Context) {
 	id := c.Param("id")
 	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
 
 	var comment models.Comment
 	if err := config.DB.First(&comment, id).Error; err != nil {
@@ -306,9 +244,8 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Only the comment owner or admins/superadmins can delete it
-	role := userRole.(models.Role)
-	if comment.UserID != userID.(uint) && role == models.RoleUser {
+	// Only the comment owner or managers of the project can delete it
+	if comment.UserID != userID.(uint) && !canModerateComment(c, &comment) {
 		utils.ErrorResponse(c, 403, "You can only delete your own comments")
 		return
 	}
@@ -320,3 +257,22 @@ func DeleteComment(c *gin.Context) {
 
 	utils.SuccessResponse(c, 200, "Comment deleted successfully", nil)
 }
+
+// canModerateComment checks if the current user manages the project the comment belongs to
+func canModerateComment(c *gin.Context, comment *models.Comment) bool {
+	var project models.Project
+	if comment.ProjectID != nil {
+		if err := config.DB.First(&project, *comment.ProjectID).Error; err != nil {
+			return false
+		}
+	} else if comment.TaskID != nil {
+		var task models.Task
+		if err := config.DB.Preload("Project").First(&task, *comment.TaskID).Error; err != nil {
+			return false
+		}
+		project = task.Project
+	} else {
+		return false
+	}
+	return policy.FromContext(c).CanManageProject(&project)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetProjectForecast godoc
+// @Summary Forecast project completion
+// @Description Forecast a project from its logged activities and task states: burn rate, projected completion against the due date,
+// @Description and earned-value figures (planned, earned and actual value, variances, SPI/CPI, estimate at completion) in hours and, with a money budget, in money.
+// @Description Progress counts completed tasks fully and started tasks by half (50/50 rule), weighted by estimate. The burn rate is the average of the last weeks.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param weeks query int false "Weeks used for the burn rate (default 4)"
+// @Success 200 {object} utils.Response{data=models.ProjectForecast}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/forecast [get]
+func GetProjectForecast(c *gin.Context) {
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "4"))
+	if err != nil || weeks < 1 || weeks > 52 {
+		utils.ErrorResponse(c, 400, "weeks must be between 1 and 52")
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanViewProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var tasks []models.Task
+	config.DB.Where("project_id = ?", project.ID).Find(&tasks)
+
+	var history []models.BurnPoint
+	if err := config.DB.Model(&models.Activity{}).
+		Select("DATE_TRUNC('week', date) AS week_start, SUM(execution_time) AS hours").
+		Where("project_id = ?", project.ID).
+		Group("DATE_TRUNC('week', date)").
+		Order("week_start ASC").
+		Scan(&history).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to load project activity")
+		return
+	}
+
+	today := currentUserSettings(c).Today()
+	forecast := models.ProjectForecast{
+		ProjectID:     project.ID,
+		AsOf:          today,
+		TasksTotal:    len(tasks),
+		DueDate:       project.DueDate,
+		BurnRateBasis: "none",
+		History:       []models.BurnPoint{},
+	}
+	for _, t := range tasks {
+		switch t.Status {
+		case models.TaskStatusCompleted:
+			forecast.TasksCompleted++
+		case models.TaskStatusInProgress, models.TaskStatusPaused:
+			forecast.TasksInProgress++
+		}
+	}
+
+	// Weekly series and burn rate: recent weeks, or the whole history when nothing was logged lately
+	var cumulative, recent float64
+	recentFrom := today.AddDate(0, 0, -7*weeks)
+	for _, point := range history {
+		cumulative += point.Hours
+		point.CumulativeHours = roundHours(cumulative)
+		point.Hours = roundHours(point.Hours)
+		forecast.History = append(forecast.History, point)
+		if !point.WeekStart.Before(recentFrom) {
+			recent += point.Hours
+		}
+	}
+	if recent > 0 {
+		forecast.BurnRate = recent / float64(weeks)
+		forecast.BurnRateBasis = "recent"
+	} else if len(history) > 0 {
+		span := today.Sub(history[0].WeekStart).Hours()/24/7 + 1
+		forecast.BurnRate = cumulative / math.Max(span, 1)
+		forecast.BurnRateBasis = "overall"
+	}
+	forecast.BurnRate = roundHours(forecast.BurnRate)
+
+	// Hour budget: the project's, or the sum of its task estimates
+	budget := project.HourBudget()
+	if budget <= 0 {
+		for _, t := range tasks {
+			budget += t.EstimatedHours
+		}
+	}
+
+	progress := models.EarnedProgress(tasks)
+	if project.Status == models.ProjectStatusCompleted {
+		progress = 1
+	}
+	forecast.PercentComplete = roundHours(progress * 100)
+	if budget > 0 {
+		forecast.PercentSpent = roundHours(project.UsedHours / budget * 100)
+	}
+
+	var planned *float64
+	if project.DueDate != nil {
+		start := project.CreatedAt
+		if project.StartDate != nil {
+			start = *project.StartDate
+		}
+		p := models.PlannedProgress(start, *project.DueDate, today)
+		planned = &p
+	}
+
+	forecast.Hours = models.NewEarnedValue("hours", budget, progress, planned, project.UsedHours)
+	if project.BudgetAmount != nil && *project.BudgetAmount > 0 {
+		cost := models.NewEarnedValue(utils.GetBillingCurrency(), *project.BudgetAmount, progress, planned, project.SpentAmount)
+		forecast.Cost = &cost
+	}
+
+	// Projected completion: remaining hours at the current burn rate
+	switch {
+	case project.Status == models.ProjectStatusCompleted && project.CompletedAt != nil:
+		completed := *project.CompletedAt
+		forecast.ProjectedCompletion = &completed
+	case budget <= 0:
+		// Without a budget there is nothing left to burn through, so no date can be projected
+	case forecast.Hours.EstimateToComplete == 0:
+		forecast.ProjectedCompletion = &today
+	case forecast.BurnRate > 0:
+		days := int(math.Ceil(forecast.Hours.EstimateToComplete / forecast.BurnRate * 7))
+		projected := today.AddDate(0, 0, days)
+		forecast.ProjectedCompletion = &projected
+	}
+	if forecast.ProjectedCompletion != nil && project.DueDate != nil {
+		due := time.Date(project.DueDate.Year(), project.DueDate.Month(), project.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+		projected := time.Date(forecast.ProjectedCompletion.Year(), forecast.ProjectedCompletion.Month(), forecast.ProjectedCompletion.Day(), 0, 0, 0, 0, time.UTC)
+		daysLate := int(projected.Sub(due).Hours() / 24)
+		forecast.DaysLate = &daysLate
+	}
+
+	utils.SuccessResponse(c, 200, "Project forecast retrieved successfully", forecast)
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/identity"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetIdentityMappings godoc
+// @Summary Get identity mapping rules
+// @Description Get the rules that place SSO users in areas based on their groups or email domain
+// @Tags identity-mappings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.IdentityMappingRule}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /identity-mappings [get]
+func GetIdentityMappings(c *gin.Context) {
+	var rules []models.IdentityMappingRule
+	if err := config.DB.Preload("Area").Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve identity mapping rules")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Identity mapping rules retrieved successfully", rules)
+}
+
+// CreateIdentityMapping godoc
+// @Summary Create identity mapping rule
+// @Description Map an identity provider group (object ID for Microsoft) or verified email domain to an area and role.
+// @Description Only group rules can grant area admin. Users matching a rule skip approval and are re-synced on every login.
+// @Tags identity-mappings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rule body CreateIdentityMappingRequest true "Rule data"
+// @Success 201 {object} utils.Response{data=models.IdentityMappingRule}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /identity-mappings [post]
+func CreateIdentityMapping(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateIdentityMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	rule := models.IdentityMappingRule{
+		Name:       req.Name,
+		Provider:   req.Provider,
+		MatchType:  req.MatchType,
+		MatchValue: strings.TrimSpace(req.MatchValue),
+		AreaID:     req.AreaID,
+		Role:       req.Role,
+		Priority:   req.Priority,
+		IsActive:   true,
+		CreatedBy:  userID.(uint),
+	}
+	if rule.Role == "" {
+		rule.Role = models.RoleUser
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if !validateIdentityMapping(c, &rule) {
+		return
+	}
+
+	if err := config.DB.Create(&rule).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create identity mapping rule")
+		return
+	}
+
+	config.DB.Preload("Area").First(&rule, rule.ID)
+
+	utils.SuccessResponse(c, 201, "Identity mapping rule created successfully", rule)
+}
+
+// UpdateIdentityMapping godoc
+// @Summary Update identity mapping rule
+// @Description Update an identity mapping rule. Users are re-synced on their next login.
+// @Tags identity-mappings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID"
+// @Param rule body UpdateIdentityMappingRequest true "Rule data"
+// @Success 200 {object} utils.Response{data=models.IdentityMappingRule}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /identity-mappings/{id} [put]
+func UpdateIdentityMapping(c *gin.Context) {
+	var req models.UpdateIdentityMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var rule models.IdentityMappingRule
+	if err := config.DB.First(&rule, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Identity mapping rule not found")
+		return
+	}
+
+	if req.Name != "" {
+		rule.Name = req.Name
+	}
+	if req.Provider != nil {
+		rule.Provider = *req.Provider
+	}
+	if req.MatchType != "" {
+		rule.MatchType = req.MatchType
+	}
+	if value := strings.TrimSpace(req.MatchValue); value != "" {
+		rule.MatchValue = value
+	}
+	if req.AreaID != nil {
+		rule.AreaID = *req.AreaID
+	}
+	if req.Role != "" {
+		rule.Role = req.Role
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if !validateIdentityMapping(c, &rule) {
+		return
+	}
+
+	if err := config.DB.Omit("Area").Save(&rule).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update identity mapping rule")
+		return
+	}
+
+	config.DB.Preload("Area").First(&rule, rule.ID)
+
+	utils.SuccessResponse(c, 200, "Identity mapping rule updated successfully", rule)
+}
+
+// DeleteIdentityMapping godoc
+// @Summary Delete identity mapping rule
+// @Description Delete an identity mapping rule. Memberships it created are removed on the user's next login.
+// @Tags identity-mappings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /identity-mappings/{id} [delete]
+func DeleteIdentityMapping(c *gin.Context) {
+	var rule models.IdentityMappingRule
+	if err := config.DB.First(&rule, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Identity mapping rule not found")
+		return
+	}
+
+	if err := config.DB.Delete(&rule).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete identity mapping rule")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Identity mapping rule deleted successfully", nil)
+}
+
+// validateIdentityMapping checks the provider, role and area of a rule
+func validateIdentityMapping(c *gin.Context, rule *models.IdentityMappingRule) bool {
+	// Area admin is only granted by directory groups, and only SuperAdmins can set that up
+	if rule.Role == models.RoleAdmin {
+		if role, _ := c.Get("user_role"); role != models.RoleSuperAdmin {
+			utils.ErrorResponse(c, 403, "Only SuperAdmins can create rules that grant area admin")
+			return false
+		}
+		if rule.MatchType != models.MappingMatchGroup {
+			utils.ErrorResponse(c, 400, "Only group rules can grant area admin")
+			return false
+		}
+	}
+
+	if rule.Provider != "" {
+		if _, err := identity.Get(rule.Provider); err != nil {
+			utils.ErrorResponse(c, 400, "Unknown identity provider: "+rule.Provider)
+			return false
+		}
+	}
+
+	if rule.MatchType == models.MappingMatchDomain {
+		rule.MatchValue = strings.ToLower(strings.TrimPrefix(rule.MatchValue, "@"))
+	}
+
+	var area models.Area
+	if err := config.DB.Where("is_active = ?", true).First(&area, rule.AreaID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Area not found")
+		return false
+	}
+	return true
+}
+
+// syncIdentityMappings applies the mapping rules matching the identity to the user: memberships
+// created by rules are added, updated or removed to follow the directory, while memberships added
+// by hand are left alone. A pending user that matches a rule is approved. Reports whether any rule matched.
+func syncIdentityMappings(tx *gorm.DB, user *models.User, ident *identity.Identity) (bool, error) {
+	// SuperAdmins are managed by hand only
+	if user.Role == models.RoleSuperAdmin {
+		return false, nil
+	}
+
+	var rules []models.IdentityMappingRule
+	if err := tx.Joins("JOIN areas ON areas.id = identity_mapping_rules.area_id AND areas.is_active = ? AND areas.deleted_at IS NULL", true).
+		Where("identity_mapping_rules.is_active = ?", true).
+		Order("identity_mapping_rules.priority DESC, identity_mapping_rules.id ASC").
+		Find(&rules).Error; err != nil {
+		return false, err
+	}
+
+	// Areas granted by the matching rules, admin winning over user, in priority order.
+	// Only group rules grant admin.
+	desired := make(map[uint]models.Role)
+	var areaOrder []uint
+	for i := range rules {
+		if !rules[i].Matches(ident.Provider, ident.Email, ident.EmailVerified, ident.Groups) {
+			continue
+		}
+		grant := models.RoleUser
+		if rules[i].MatchType == models.MappingMatchGroup {
+			grant = models.MembershipRoleFor(rules[i].Role)
+		}
+		role, seen := desired[rules[i].AreaID]
+		if !seen {
+			areaOrder = append(areaOrder, rules[i].AreaID)
+		}
+		if !seen || role != models.RoleAdmin {
+			desired[rules[i].AreaID] = grant
+		}
+	}
+	matched := len(areaOrder) > 0
+
+	var memberships []models.UserArea
+	if err := tx.Where("user_id = ?", user.ID).Find(&memberships).Error; err != nil {
+		return false, err
+	}
+
+	changed := false
+	current := make(map[uint]*models.UserArea)
+	var kept []*models.UserArea
+	for i := range memberships {
+		membership := &memberships[i]
+		role, wanted := desired[membership.AreaID]
+		if membership.Source == models.MembershipSourceSSO && !wanted {
+			if err := tx.Delete(membership).Error; err != nil {
+				return false, err
+			}
+			changed = true
+			continue
+		}
+		if membership.Source == models.MembershipSourceSSO && membership.Role != role {
+			if err := tx.Model(membership).Update("role", role).Error; err != nil {
+				return false, err
+			}
+			membership.Role = role
+			changed = true
+		}
+		current[membership.AreaID] = membership
+		kept = append(kept, membership)
+	}
+
+	for _, areaID := range areaOrder {
+		if _, exists := current[areaID]; exists {
+			continue
+		}
+		membership := &models.UserArea{
+			UserID: user.ID,
+			AreaID: areaID,
+			Role:   desired[areaID],
+			Source: models.MembershipSourceSSO,
+		}
+		if err := tx.Create(membership).Error; err != nil {
+			return false, err
+		}
+		current[areaID] = membership
+		kept = append(kept, membership)
+		changed = true
+	}
+
+	approve := matched && user.IsPendingApproval()
+	if !changed && !approve {
+		return matched, nil
+	}
+
+	// Keep the primary area when it survived, otherwise use the highest priority rule's area
+	var primary *models.UserArea
+	for _, membership := range kept {
+		if membership.IsPrimary {
+			primary = membership
+		}
+	}
+	if primary == nil && matched {
+		primary = current[areaOrder[0]]
+	}
+	if primary == nil && len(kept) > 0 {
+		primary = kept[0]
+	}
+
+	updates := map[string]interface{}{}
+	if primary != nil {
+		if !primary.IsPrimary {
+			if err := tx.Model(&models.UserArea{}).Where("user_id = ?", user.ID).
+				Update("is_primary", gorm.Expr("id = ?", primary.ID)).Error; err != nil {
+				return false, err
+			}
+		}
+		user.AreaID = &primary.AreaID
+		user.Role = models.MembershipRoleFor(primary.Role)
+	} else {
+		user.AreaID = nil
+		user.Role = models.RoleUser
+	}
+	updates["area_id"] = user.AreaID
+	updates["role"] = user.Role
+
+	if approve {
+		now := time.Now()
+		user.IsActive = true
+		user.ApprovalStatus = models.ApprovalStatusApproved
+		user.ReviewedAt = &now
+		updates["is_active"] = true
+		updates["approval_status"] = models.ApprovalStatusApproved
+		updates["reviewed_at"] = now
+	}
+
+	return matched, tx.Model(user).Updates(updates).Error
+}
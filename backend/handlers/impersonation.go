@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// StartImpersonation godoc
+// @Summary Impersonate a user
+// @Description Issue a time-limited token that acts as another user, to see exactly what they see (SuperAdmin only).
+// @Description Sessions are read-only unless allow_writes is set, and every request made with the token is audited.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param impersonation body StartImpersonationRequest true "User to impersonate and reason"
+// @Success 201 {object} utils.Response{data=models.ImpersonationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /auth/impersonate [post]
+func StartImpersonation(c *gin.Context) {
+	impersonatorID, _ := c.Get("user_id")
+
+	var req models.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var impersonator models.User
+	if err := config.DB.First(&impersonator, impersonatorID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Area").Preload("Areas.Area").First(&user, req.UserID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	if user.ID == impersonator.ID {
+		utils.ErrorResponse(c, 400, "You cannot impersonate yourself")
+		return
+	}
+	if user.Role == models.RoleSuperAdmin {
+		utils.ErrorResponse(c, 403, "Super admins cannot be impersonated")
+		return
+	}
+	if !user.IsActive || user.ApprovalStatus != models.ApprovalStatusApproved {
+		utils.ErrorResponse(c, 400, "Only active, approved users can be impersonated")
+		return
+	}
+
+	maxMinutes := utils.GetImpersonationMaxMinutes()
+	minutes := req.DurationMinutes
+	if minutes == 0 || minutes > maxMinutes {
+		minutes = maxMinutes
+	}
+
+	session := models.ImpersonationSession{
+		ImpersonatorID:     impersonator.ID,
+		ImpersonatedUserID: user.ID,
+		Reason:             req.Reason,
+		ReadOnly:           !req.AllowWrites,
+		ExpiresAt:          time.Now().Add(time.Duration(minutes) * time.Minute),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to start impersonation")
+		return
+	}
+
+	token, err := utils.GenerateImpersonationToken(&user, &impersonator, &session)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate token")
+		return
+	}
+
+	response := models.ImpersonationResponse{
+		Token:   token,
+		Session: session,
+		User: models.UserResponse{
+			ID:             user.ID,
+			Email:          user.Email,
+			FullName:       user.FullName,
+			Role:           user.Role,
+			AreaID:         user.AreaID,
+			Area:           user.Area,
+			Areas:          user.Areas,
+			WorkSchedule:   user.WorkSchedule,
+			LunchBreak:     user.LunchBreak,
+			IsActive:       user.IsActive,
+			IsImpersonated: true,
+			Impersonation:  impersonationInfo(&session, &impersonator),
+		},
+	}
+
+	utils.SuccessResponse(c, 201, "Impersonation started", response)
+}
+
+// StopImpersonation godoc
+// @Summary Stop impersonating
+// @Description End the current impersonation session. The impersonation token stops working immediately.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.ImpersonationSession}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/impersonation/stop [post]
+func StopImpersonation(c *gin.Context) {
+	sessionID, ok := c.Get("impersonation_session_id")
+	if !ok {
+		utils.ErrorResponse(c, 400, "Not impersonating")
+		return
+	}
+
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, sessionID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Impersonation session not found")
+		return
+	}
+
+	now := time.Now()
+	session.EndedAt = &now
+	if err := config.DB.Model(&session).Update("ended_at", now).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to stop impersonation")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Impersonation stopped", session)
+}
+
+// GetImpersonationSessions godoc
+// @Summary Get impersonation sessions
+// @Description Get impersonation sessions, most recent first (SuperAdmin only)
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Param impersonator_id query int false "Filter by impersonator"
+// @Param user_id query int false "Filter by impersonated user"
+// @Success 200 {object} utils.Response{data=[]models.ImpersonationSession}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /impersonations [get]
+func GetImpersonationSessions(c *gin.Context) {
+	query := config.DB.Preload("Impersonator").Preload("ImpersonatedUser")
+
+	if impersonatorID := c.Query("impersonator_id"); impersonatorID != "" {
+		query = query.Where("impersonator_id = ?", impersonatorID)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("impersonated_user_id = ?", userID)
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := query.Order("created_at DESC").Find(&sessions).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve impersonation sessions")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Impersonation sessions retrieved successfully", sessions)
+}
+
+// GetImpersonationAuditLog godoc
+// @Summary Get impersonation audit log
+// @Description Get every request made during an impersonation session (SuperAdmin only)
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} utils.Response{data=[]models.ImpersonationAuditLog}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /impersonations/{id}/audit [get]
+func GetImpersonationAuditLog(c *gin.Context) {
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Impersonation session not found")
+		return
+	}
+
+	var entries []models.ImpersonationAuditLog
+	if err := config.DB.Where("session_id = ?", session.ID).Order("created_at ASC").Find(&entries).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve audit log")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Audit log retrieved successfully", entries)
+}
+
+// currentImpersonation returns the banner details when the request is made with an impersonation token
+func currentImpersonation(c *gin.Context) *models.ImpersonationInfo {
+	sessionID, ok := c.Get("impersonation_session_id")
+	if !ok {
+		return nil
+	}
+
+	var session models.ImpersonationSession
+	if err := config.DB.Preload("Impersonator").First(&session, sessionID).Error; err != nil {
+		return nil
+	}
+	return impersonationInfo(&session, &session.Impersonator)
+}
+
+func impersonationInfo(session *models.ImpersonationSession, impersonator *models.User) *models.ImpersonationInfo {
+	return &models.ImpersonationInfo{
+		SessionID:        session.ID,
+		ImpersonatorID:   impersonator.ID,
+		ImpersonatorName: impersonator.FullName,
+		ReadOnly:         session.ReadOnly,
+		ExpiresAt:        session.ExpiresAt,
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetInvitations godoc
+// @Summary Get invitations
+// @Description Get invitations for the areas where the caller holds invitations.manage
+// @Tags invitations
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, accepted, revoked, expired)"
+// @Success 200 {object} utils.Response{data=[]models.Invitation}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /invitations [get]
+func GetInvitations(c *gin.Context) {
+	query := config.DB.Preload("Area").Preload("InvitedByUser")
+
+	// Restrict to areas where the caller can manage invitations
+	query = policy.FromContext(c).ScopeAreas(query, models.PermInvitationsManage, "invitations.area_id")
+
+	now := time.Now()
+	switch models.InvitationStatus(c.Query("status")) {
+	case models.InvitationStatusPending:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case models.InvitationStatusAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	case models.InvitationStatusRevoked:
+		query = query.Where("revoked_at IS NOT NULL")
+	case models.InvitationStatusExpired:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve invitations")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invitations retrieved successfully", invitations)
+}
+
+// CreateInvitation godoc
+// @Summary Create invitation
+// @Description Invite a person to register with a pre-set role and area. SuperAdmin can invite users or admins to any area. Admin can only invite 'user' role to their own area.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invitation body CreateInvitationRequest true "Invitation data"
+// @Success 201 {object} utils.Response{data=models.InvitationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /invitations [post]
+func CreateInvitation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	// Same rules as CreateUser: area-scoped managers only invite 'user' role in their areas
+	subject := policy.FromContext(c)
+	if !subject.Can(models.PermInvitationsManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only invite users to areas you manage")
+		return
+	}
+	if !subject.CanAssignBaseRole(role) {
+		utils.ErrorResponse(c, 403, "You can only invite users with 'user' role")
+		return
+	}
+
+	var area models.Area
+	if err := config.DB.Where("is_active = ?", true).First(&area, *req.AreaID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Area not found")
+		return
+	}
+
+	var existingUser models.User
+	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		utils.ErrorResponse(c, 400, "Email already exists")
+		return
+	}
+
+	var pendingCount int64
+	config.DB.Model(&models.Invitation{}).
+		Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", req.Email, time.Now()).
+		Count(&pendingCount)
+	if pendingCount > 0 {
+		utils.ErrorResponse(c, 400, "A pending invitation already exists for this email. Resend it instead")
+		return
+	}
+
+	invitation := models.Invitation{
+		Email:     req.Email,
+		Role:      role,
+		AreaID:    &area.ID,
+		InvitedBy: userID.(uint),
+		ExpiresAt: invitationExpiry(req.ExpiresInHours),
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate invitation token")
+		return
+	}
+	invitation.TokenHash = utils.HashToken(token)
+
+	if err := config.DB.Create(&invitation).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create invitation")
+		return
+	}
+
+	invitationURL, emailSent := sendInvitation(&invitation, token)
+
+	// Reload to get relations
+	config.DB.Preload("Area").Preload("InvitedByUser").First(&invitation, invitation.ID)
+
+	utils.SuccessResponse(c, 201, "Invitation created successfully", models.InvitationResponse{
+		Invitation:    invitation,
+		InvitationURL: invitationURL,
+		EmailSent:     emailSent,
+	})
+}
+
+// ResendInvitation godoc
+// @Summary Resend invitation
+// @Description Issue a new token for a pending or expired invitation and send it again. The previous link stops working.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invitation ID"
+// @Param invitation body ResendInvitationRequest false "New expiration"
+// @Success 200 {object} utils.Response{data=models.InvitationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invitations/{id}/resend [post]
+func ResendInvitation(c *gin.Context) {
+	var req models.ResendInvitationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, 400, err.Error())
+			return
+		}
+	}
+
+	invitation, ok := loadManagedInvitation(c)
+	if !ok {
+		return
+	}
+
+	status := invitation.CurrentStatus()
+	if status != models.InvitationStatusPending && status != models.InvitationStatusExpired {
+		utils.ErrorResponse(c, 400, "Only pending or expired invitations can be resent")
+		return
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate invitation token")
+		return
+	}
+	invitation.TokenHash = utils.HashToken(token)
+	invitation.ExpiresAt = invitationExpiry(req.ExpiresInHours)
+
+	if err := config.DB.Save(invitation).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update invitation")
+		return
+	}
+
+	invitationURL, emailSent := sendInvitation(invitation, token)
+
+	config.DB.Preload("Area").Preload("InvitedByUser").First(invitation, invitation.ID)
+
+	utils.SuccessResponse(c, 200, "Invitation resent successfully", models.InvitationResponse{
+		Invitation:    *invitation,
+		InvitationURL: invitationURL,
+		EmailSent:     emailSent,
+	})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revoke a pending invitation so its token can no longer be used
+// @Tags invitations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} utils.Response{data=models.Invitation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invitations/{id} [delete]
+func RevokeInvitation(c *gin.Context) {
+	invitation, ok := loadManagedInvitation(c)
+	if !ok {
+		return
+	}
+
+	if invitation.AcceptedAt != nil {
+		utils.ErrorResponse(c, 400, "Invitation has already been accepted")
+		return
+	}
+	if invitation.RevokedAt != nil {
+		utils.ErrorResponse(c, 400, "Invitation is already revoked")
+		return
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+	if err := config.DB.Save(invitation).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke invitation")
+		return
+	}
+	invitation.Status = invitation.CurrentStatus()
+
+	utils.SuccessResponse(c, 200, "Invitation revoked successfully", invitation)
+}
+
+// ValidateInvitation godoc
+// @Summary Validate invitation token
+// @Description Public endpoint used by the registration form to check an invitation token and prefill email, role and area
+// @Tags auth
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Success 200 {object} utils.Response{data=models.Invitation}
+// @Failure 404 {object} utils.Response
+// @Router /auth/invitations/{token} [get]
+func ValidateInvitation(c *gin.Context) {
+	var invitation models.Invitation
+	if err := config.DB.Preload("Area").Where("token_hash = ?", utils.HashToken(c.Param("token"))).First(&invitation).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Invitation not found")
+		return
+	}
+
+	if !invitation.IsUsable() {
+		utils.ErrorResponse(c, 400, "Invitation is "+string(invitation.CurrentStatus()))
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invitation is valid", invitation)
+}
+
+// loadManagedInvitation loads the invitation from the :id param and checks the caller can manage it
+func loadManagedInvitation(c *gin.Context) (*models.Invitation, bool) {
+	var invitation models.Invitation
+	if err := config.DB.First(&invitation, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Invitation not found")
+		return nil, false
+	}
+
+	if !policy.FromContext(c).Can(models.PermInvitationsManage, invitation.AreaID) {
+		utils.ErrorResponse(c, 403, "Can only manage invitations in your area")
+		return nil, false
+	}
+
+	return &invitation, true
+}
+
+// invitationExpiry returns the expiration time for an invitation, falling back to the configured default
+func invitationExpiry(hours int) time.Time {
+	if hours <= 0 {
+		hours = utils.GetInvitationExpirationHours()
+	}
+	return time.Now().Add(time.Duration(hours) * time.Hour)
+}
+
+// sendInvitation emails the invitation link and records the delivery. It returns the link and whether
+// the email was sent; without SMTP nothing is sent or recorded and the link has to be shared by hand.
+func sendInvitation(invitation *models.Invitation, token string) (string, bool) {
+	invitationURL := utils.GetAppURL() + "/register?invitation=" + token
+
+	if !utils.EmailConfigured() {
+		log.Printf("SMTP not configured, invitation %d was not emailed", invitation.ID)
+		return invitationURL, false
+	}
+
+	body := fmt.Sprintf(
+		"You have been invited to join Time Flow.\n\nComplete your registration here:\n%s\n\nThis invitation expires on %s.",
+		invitationURL, invitation.ExpiresAt.Format("2006-01-02 15:04 MST"),
+	)
+	if err := utils.SendEmail(invitation.Email, "Invitation to Time Flow", body); err != nil {
+		log.Printf("Warning: Failed to send invitation %d: %v", invitation.ID, err)
+		return invitationURL, false
+	}
+
+	now := time.Now()
+	config.DB.Model(invitation).Updates(map[string]interface{}{
+		"last_sent_at": now,
+		"send_count":   invitation.SendCount + 1,
+	})
+
+	return invitationURL, true
+}
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+var errNothingToInvoice = errors.New("nothing to invoice")
+
+// GetInvoices godoc
+// @Summary Get invoices
+// @Description Get the invoices of the areas where the caller holds invoices.manage (invoices spanning every area need it globally)
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param client_id query int false "Filter by client ID"
+// @Param area_id query int false "Filter by area ID"
+// @Param status query string false "Filter by status (issued, void)"
+// @Success 200 {object} utils.Response{data=[]models.Invoice}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /invoices [get]
+func GetInvoices(c *gin.Context) {
+	query := policy.FromContext(c).ScopeAreas(config.DB.Preload("Client"), models.PermInvoicesManage, "invoices.area_id")
+
+	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
+		if clientID, err := strconv.ParseUint(clientIDStr, 10, 32); err == nil {
+			query = query.Where("client_id = ?", uint(clientID))
+		}
+	}
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = query.Where("area_id = ?", uint(areaID))
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []models.Invoice
+	if err := query.Order("issued_at DESC").Find(&invoices).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve invoices")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invoices retrieved successfully", invoices)
+}
+
+// GetInvoice godoc
+// @Summary Get invoice by ID
+// @Description Get an invoice with its line items
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} utils.Response{data=models.Invoice}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invoices/{id} [get]
+func GetInvoice(c *gin.Context) {
+	invoice, ok := loadInvoice(c)
+	if !ok {
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invoice retrieved successfully", invoice)
+}
+
+// PreviewInvoice godoc
+// @Summary Preview invoice
+// @Description Collect the uninvoiced billable activities of the client's projects in the period, grouped by project and task, without issuing an invoice
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param client_id query int true "Client ID"
+// @Param date_from query string true "Start date (YYYY-MM-DD)"
+// @Param date_to query string true "End date (YYYY-MM-DD)"
+// @Param area_id query int false "Only activities of this area (required without the global invoices.manage permission)"
+// @Success 200 {object} utils.Response{data=models.Invoice}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invoices/preview [get]
+func PreviewInvoice(c *gin.Context) {
+	req := models.CreateInvoiceRequest{
+		DateFrom: c.Query("date_from"),
+		DateTo:   c.Query("date_to"),
+	}
+	clientID, err := strconv.ParseUint(c.Query("client_id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "client_id is required")
+		return
+	}
+	req.ClientID = uint(clientID)
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		areaID, err := strconv.ParseUint(areaIDStr, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid area_id")
+			return
+		}
+		id := uint(areaID)
+		req.AreaID = &id
+	}
+
+	invoice, status, msg := newInvoice(c, &req)
+	if status != 0 {
+		utils.ErrorResponse(c, status, msg)
+		return
+	}
+
+	lines, err := invoiceLines(uninvoicedActivities(config.DB, invoice))
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to collect billable activities")
+		return
+	}
+	invoice.SetLines(lines)
+
+	utils.SuccessResponse(c, 200, "Invoice preview generated successfully", invoice)
+}
+
+// CreateInvoice godoc
+// @Summary Issue invoice
+// @Description Invoice the uninvoiced billable activities of the client's projects in the period, with line items grouped by project and task.
+// @Description Amounts come from the rates snapshotted on each activity. Invoiced activities are locked until the invoice is voided.
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invoice body CreateInvoiceRequest true "Invoice data"
+// @Success 201 {object} utils.Response{data=models.Invoice}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invoices [post]
+func CreateInvoice(c *gin.Context) {
+	var req models.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	invoice, status, msg := newInvoice(c, &req)
+	if status != 0 {
+		utils.ErrorResponse(c, status, msg)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Client", "Lines").Create(invoice).Error; err != nil {
+			return err
+		}
+		invoice.AssignNumber()
+
+		// Claim the activities first so two invoices can never include the same one
+		claimed := uninvoicedActivities(tx, invoice)
+		result := tx.Model(&models.Activity{}).
+			Where("id IN (?)", claimed.Select("activities.id")).
+			Update("invoice_id", invoice.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNothingToInvoice
+		}
+
+		lines, err := invoiceLines(tx.Model(&models.Activity{}).Where("activities.invoice_id = ?", invoice.ID))
+		if err != nil {
+			return err
+		}
+		invoice.SetLines(lines)
+		for i := range invoice.Lines {
+			invoice.Lines[i].InvoiceID = invoice.ID
+		}
+		if err := tx.Create(&invoice.Lines).Error; err != nil {
+			return err
+		}
+		return tx.Model(invoice).Updates(map[string]interface{}{
+			"number": invoice.Number,
+			"hours":  invoice.Hours,
+			"total":  invoice.Total,
+		}).Error
+	})
+	if errors.Is(err, errNothingToInvoice) {
+		utils.ErrorResponse(c, 400, "The client has no uninvoiced billable activities in this period")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create invoice")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Invoice created successfully", invoice)
+}
+
+// VoidInvoice godoc
+// @Summary Void invoice
+// @Description Void an issued invoice. Its activities are unlocked and can be edited and invoiced again.
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Success 200 {object} utils.Response{data=models.Invoice}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invoices/{id}/void [post]
+func VoidInvoice(c *gin.Context) {
+	invoice, ok := loadInvoice(c)
+	if !ok {
+		return
+	}
+	if invoice.Status == models.InvoiceStatusVoid {
+		utils.ErrorResponse(c, 400, "The invoice is already void")
+		return
+	}
+
+	now := time.Now()
+	userID := c.MustGet("user_id").(uint)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Activity{}).Where("invoice_id = ?", invoice.ID).Update("invoice_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(invoice).Updates(map[string]interface{}{
+			"status":    models.InvoiceStatusVoid,
+			"voided_at": now,
+			"voided_by": userID,
+		}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to void invoice")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Invoice voided successfully", invoice)
+}
+
+// ExportInvoice godoc
+// @Summary Export invoice
+// @Description Download an invoice as PDF or as CSV (one row per line item)
+// @Tags invoices
+// @Produce application/pdf
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path int true "Invoice ID"
+// @Param format query string false "pdf (default) or csv"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invoices/{id}/export [get]
+func ExportInvoice(c *gin.Context) {
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "csv" {
+		utils.ErrorResponse(c, 400, "format must be pdf or csv")
+		return
+	}
+
+	invoice, ok := loadInvoice(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("%s.%s", invoice.Number, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		c.Data(200, "text/csv; charset=utf-8", invoiceCSV(invoice))
+		return
+	}
+	c.Data(200, "application/pdf", invoicePDF(invoice))
+}
+
+// loadInvoice loads the invoice of the :id parameter with its client and lines, writing the error response when
+// it does not exist or the caller cannot manage it
+func loadInvoice(c *gin.Context) (*models.Invoice, bool) {
+	var invoice models.Invoice
+	if err := config.DB.Preload("Client", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&invoice, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Invoice not found")
+		return nil, false
+	}
+	if !policy.FromContext(c).Can(models.PermInvoicesManage, invoice.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return nil, false
+	}
+	return &invoice, true
+}
+
+// newInvoice validates an invoice request and builds the invoice without lines.
+// Returns 0 when valid, otherwise the HTTP status and message.
+func newInvoice(c *gin.Context, req *models.CreateInvoiceRequest) (*models.Invoice, int, string) {
+	from, err := time.Parse("2006-01-02", req.DateFrom)
+	if err != nil {
+		return nil, 400, "Invalid date_from format. Use YYYY-MM-DD"
+	}
+	to, err := time.Parse("2006-01-02", req.DateTo)
+	if err != nil {
+		return nil, 400, "Invalid date_to format. Use YYYY-MM-DD"
+	}
+	if to.Before(from) {
+		return nil, 400, "date_to cannot be before date_from"
+	}
+
+	// Without an area the invoice collects activities of every area
+	if !policy.FromContext(c).Can(models.PermInvoicesManage, req.AreaID) {
+		if req.AreaID == nil {
+			return nil, 403, "Choose an area_id you can invoice"
+		}
+		return nil, 403, "You can only invoice activities of areas you manage"
+	}
+
+	var client models.Client
+	if err := config.DB.First(&client, req.ClientID).Error; err != nil {
+		return nil, 404, "Client not found"
+	}
+	if client.AreaID != nil && req.AreaID != nil && *client.AreaID != *req.AreaID {
+		return nil, 400, "The client belongs to another area"
+	}
+
+	return &models.Invoice{
+		ClientID:   client.ID,
+		AreaID:     req.AreaID,
+		PeriodFrom: from,
+		PeriodTo:   to,
+		Status:     models.InvoiceStatusIssued,
+		Currency:   utils.GetBillingCurrency(),
+		Notes:      req.Notes,
+		IssuedAt:   time.Now(),
+		CreatedBy:  c.MustGet("user_id").(uint),
+		Client:     client,
+	}, 0, ""
+}
+
+// uninvoicedActivities selects the billable, not yet invoiced activities the invoice covers
+func uninvoicedActivities(db *gorm.DB, invoice *models.Invoice) *gorm.DB {
+	query := db.Model(&models.Activity{}).
+		Where("activities.billable = ? AND activities.invoice_id IS NULL", true).
+		Where("activities.date >= ? AND activities.date <= ?", invoice.PeriodFrom, invoice.PeriodTo).
+		Where("activities.project_id IN (?)", db.Model(&models.Project{}).Select("id").Where("client_id = ?", invoice.ClientID))
+	if invoice.AreaID != nil {
+		query = query.Where("activities.area_id = ?", *invoice.AreaID)
+	}
+	return query
+}
+
+// invoiceLines groups activities into one line per project task (and one per project for activities without task)
+func invoiceLines(activities *gorm.DB) ([]models.InvoiceLine, error) {
+	lines := []models.InvoiceLine{}
+	err := activities.
+		Joins("JOIN projects ON projects.id = activities.project_id").
+		Joins("LEFT JOIN tasks ON tasks.id = activities.task_id").
+		Select(`activities.project_id, activities.task_id,
+			MAX(projects.name) AS project_name, COALESCE(MAX(tasks.name), '') AS task_name,
+			COUNT(*) AS activity_count,
+			SUM(activities.execution_time) AS hours,
+			SUM(activities.revenue_amount) AS amount`).
+		Group("activities.project_id, activities.task_id").
+		Order("project_name ASC, task_name ASC").
+		Scan(&lines).Error
+	return lines, err
+}
+
+// invoiceCSV renders one row per line item plus a total row
+func invoiceCSV(invoice *models.Invoice) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"invoice", "client", "period_from", "period_to", "project", "task", "description", "activities", "hours", "rate", "amount", "currency"})
+	for _, line := range invoice.Lines {
+		w.Write([]string{
+			invoice.Number,
+			invoice.Client.Name,
+			invoice.PeriodFrom.Format("2006-01-02"),
+			invoice.PeriodTo.Format("2006-01-02"),
+			line.ProjectName,
+			line.TaskName,
+			line.Description,
+			strconv.Itoa(line.ActivityCount),
+			formatAmount(line.Hours),
+			formatAmount(line.Rate),
+			formatAmount(line.Amount),
+			invoice.Currency,
+		})
+	}
+	w.Write([]string{invoice.Number, invoice.Client.Name, "", "", "", "", "Total", "", formatAmount(invoice.Hours), "", formatAmount(invoice.Total), invoice.Currency})
+	w.Flush()
+	return buf.Bytes()
+}
+
+// invoicePDF renders the invoice as a one-column document with a line items table
+func invoicePDF(invoice *models.Invoice) []byte {
+	doc := utils.NewPDFDocument()
+	doc.Heading("Invoice " + invoice.Number)
+	if invoice.Status == models.InvoiceStatusVoid {
+		doc.Row(12, true, utils.PDFCell{Text: "VOID"})
+	}
+	doc.Space(8)
+	doc.Text("Issued: " + invoice.IssuedAt.Format("2006-01-02"))
+	doc.Text(fmt.Sprintf("Period: %s to %s", invoice.PeriodFrom.Format("2006-01-02"), invoice.PeriodTo.Format("2006-01-02")))
+	doc.Space(8)
+
+	client := invoice.Client
+	doc.Row(10, true, utils.PDFCell{Text: "Bill to"})
+	for _, text := range []string{client.Name, client.TaxID, client.ContactName, client.Address, client.Email, client.Phone} {
+		if text != "" {
+			doc.Text(text)
+		}
+	}
+	doc.Space(12)
+
+	columns := func(bold bool, description, hours, rate, amount string) {
+		doc.Row(10, bold,
+			utils.PDFCell{X: 0, Width: 280, Text: description},
+			utils.PDFCell{X: 290, Width: 60, Text: hours},
+			utils.PDFCell{X: 355, Width: 70, Text: rate},
+			utils.PDFCell{X: 430, Width: 65, Text: amount})
+	}
+	columns(true, "Description", "Hours", "Rate", "Amount")
+	doc.Rule()
+	for _, line := range invoice.Lines {
+		columns(false, line.Description, formatAmount(line.Hours), formatAmount(line.Rate), formatAmount(line.Amount))
+	}
+	doc.Rule()
+	columns(true, "Total ("+invoice.Currency+")", formatAmount(invoice.Hours), "", formatAmount(invoice.Total))
+
+	if invoice.Notes != "" {
+		doc.Space(12)
+		doc.Text(invoice.Notes)
+	}
+	return doc.Bytes()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetJWKS godoc
+// @Summary Get JSON Web Key Set
+// @Description Get the public keys that verify tokens issued by this server, identified by kid.
+// @Description Served at /.well-known/jwks.json, outside the /api/v1 base path.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKSet
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, utils.GetJWKS())
+}
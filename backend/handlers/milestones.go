@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetProjectMilestones godoc
+// @Summary Get project milestones
+// @Description Get a project's milestones by due date, with their linked tasks, progress and status
+// @Tags milestones
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Success 200 {object} utils.Response{data=[]models.Milestone}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/milestones [get]
+func GetProjectMilestones(c *gin.Context) {
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanViewProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var milestones []models.Milestone
+	if err := config.DB.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("\"order\" ASC, created_at DESC")
+	}).Where("project_id = ?", project.ID).Order("due_date ASC, id ASC").Find(&milestones).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve milestones")
+		return
+	}
+
+	today := currentUserSettings(c).Today()
+	for i := range milestones {
+		milestones[i].Evaluate(milestones[i].Tasks, today)
+	}
+
+	utils.SuccessResponse(c, 200, "Milestones retrieved successfully", milestones)
+}
+
+// CreateMilestone godoc
+// @Summary Create milestone
+// @Description Create a milestone in a project, optionally linking tasks of the project. A task belongs to one milestone at most; linking moves it.
+// @Tags milestones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param milestone body CreateMilestoneRequest true "Milestone data"
+// @Success 201 {object} utils.Response{data=models.Milestone}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/milestones [post]
+func CreateMilestone(c *gin.Context) {
+	var req models.CreateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTasksIn(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	dueDate, err := time.Parse("2006-01-02", req.DueDate)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid due_date format. Use YYYY-MM-DD")
+		return
+	}
+	taskIDs := uniqueIDs(req.TaskIDs)
+	if msg := checkMilestoneTasks(project.ID, taskIDs); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	milestone := models.Milestone{
+		ProjectID:   project.ID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		DueDate:     dueDate,
+		CreatedBy:   c.MustGet("user_id").(uint),
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&milestone).Error; err != nil {
+			return err
+		}
+		return linkMilestoneTasks(tx, &milestone, taskIDs)
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create milestone")
+		return
+	}
+
+	config.DB.Preload("Tasks").First(&milestone, milestone.ID)
+	milestone.Evaluate(milestone.Tasks, currentUserSettings(c).Today())
+
+	utils.SuccessResponse(c, 201, "Milestone created successfully", milestone)
+}
+
+// UpdateMilestone godoc
+// @Summary Update milestone
+// @Description Update a milestone. Sending task_ids replaces its linked tasks.
+// @Tags milestones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Milestone ID"
+// @Param milestone body UpdateMilestoneRequest true "Milestone data"
+// @Success 200 {object} utils.Response{data=models.Milestone}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /milestones/{id} [put]
+func UpdateMilestone(c *gin.Context) {
+	var req models.UpdateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var milestone models.Milestone
+	if err := config.DB.Preload("Project").First(&milestone, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Milestone not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTasksIn(milestone.Project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if name := strings.TrimSpace(req.Name); name != "" {
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid due_date format. Use YYYY-MM-DD")
+			return
+		}
+		updates["due_date"] = dueDate
+	}
+	taskIDs := uniqueIDs(req.TaskIDs)
+	if req.TaskIDs != nil {
+		if msg := checkMilestoneTasks(milestone.ProjectID, taskIDs); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&milestone).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.TaskIDs == nil {
+			return nil
+		}
+		return linkMilestoneTasks(tx, &milestone, taskIDs)
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update milestone")
+		return
+	}
+
+	milestone.Project = nil
+	config.DB.Preload("Tasks").First(&milestone, milestone.ID)
+	milestone.Evaluate(milestone.Tasks, currentUserSettings(c).Today())
+
+	utils.SuccessResponse(c, 200, "Milestone updated successfully", milestone)
+}
+
+// DeleteMilestone godoc
+// @Summary Delete milestone
+// @Description Delete a milestone. Its tasks are kept and unlinked.
+// @Tags milestones
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Milestone ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /milestones/{id} [delete]
+func DeleteMilestone(c *gin.Context) {
+	var milestone models.Milestone
+	if err := config.DB.Preload("Project").First(&milestone, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Milestone not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTasksIn(milestone.Project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).Where("milestone_id = ?", milestone.ID).Update("milestone_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&milestone).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete milestone")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Milestone deleted successfully", nil)
+}
+
+// GetMilestoneAgenda godoc
+// @Summary Get upcoming and overdue milestones
+// @Description List the milestones of the active projects the user can see that are overdue, or due within the next days and not completed
+// @Tags milestones
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days ahead for upcoming milestones (default 14)"
+// @Success 200 {object} utils.Response{data=models.MilestoneAgenda}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /milestones [get]
+func GetMilestoneAgenda(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "14"))
+	if err != nil || days < 0 || days > 365 {
+		utils.ErrorResponse(c, 400, "days must be between 0 and 365")
+		return
+	}
+
+	today := currentUserSettings(c).Today()
+	query := config.DB.Joins("JOIN projects ON projects.id = milestones.project_id AND projects.deleted_at IS NULL").
+		Where("projects.is_active = ? AND projects.status <> ?", true, models.ProjectStatusCompleted).
+		Where("milestones.due_date <= ?", today.AddDate(0, 0, days))
+	query = policy.FromContext(c).ScopeProjects(query)
+
+	var milestones []models.Milestone
+	if err := query.Preload("Project").Order("milestones.due_date ASC, milestones.id ASC").Find(&milestones).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve milestones")
+		return
+	}
+	evaluateMilestones(milestones, today)
+
+	agenda := models.MilestoneAgenda{Overdue: []models.Milestone{}, Upcoming: []models.Milestone{}}
+	for _, milestone := range milestones {
+		switch milestone.Status {
+		case models.MilestoneStatusOverdue:
+			agenda.Overdue = append(agenda.Overdue, milestone)
+		case models.MilestoneStatusPending, models.MilestoneStatusInProgress:
+			agenda.Upcoming = append(agenda.Upcoming, milestone)
+		}
+	}
+
+	utils.SuccessResponse(c, 200, "Milestones retrieved successfully", agenda)
+}
+
+// evaluateMilestones computes the progress and status of milestones loaded without their tasks
+func evaluateMilestones(milestones []models.Milestone, today time.Time) {
+	ids := make([]uint, 0, len(milestones))
+	for _, m := range milestones {
+		ids = append(ids, m.ID)
+	}
+
+	var tasks []models.Task
+	config.DB.Select("id", "milestone_id", "status", "estimated_hours").Where("milestone_id IN ?", models.NonEmptyIDs(ids)).Find(&tasks)
+	byMilestone := make(map[uint][]models.Task, len(milestones))
+	for _, t := range tasks {
+		byMilestone[*t.MilestoneID] = append(byMilestone[*t.MilestoneID], t)
+	}
+
+	for i := range milestones {
+		milestones[i].Evaluate(byMilestone[milestones[i].ID], today)
+	}
+}
+
+// checkMilestoneTasks checks the tasks belong to the project, returning an error message or ""
+func checkMilestoneTasks(projectID uint, taskIDs []uint) string {
+	if len(taskIDs) == 0 {
+		return ""
+	}
+	var count int64
+	config.DB.Model(&models.Task{}).Where("id IN ? AND project_id = ?", taskIDs, projectID).Count(&count)
+	if int(count) != len(taskIDs) {
+		return "Milestone tasks must belong to the same project"
+	}
+	return ""
+}
+
+// checkTaskMilestone checks a task of the project can be linked to the milestone, returning an error message or ""
+func checkTaskMilestone(milestoneID, projectID uint) string {
+	var milestone models.Milestone
+	if err := config.DB.First(&milestone, milestoneID).Error; err != nil {
+		return "Milestone not found"
+	}
+	if milestone.ProjectID != projectID {
+		return "The milestone belongs to another project"
+	}
+	return ""
+}
+
+// linkMilestoneTasks makes the given tasks the only ones linked to the milestone
+func linkMilestoneTasks(tx *gorm.DB, milestone *models.Milestone, taskIDs []uint) error {
+	if err := tx.Model(&models.Task{}).Where("milestone_id = ?", milestone.ID).Update("milestone_id", nil).Error; err != nil {
+		return err
+	}
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Task{}).Where("id IN ?", taskIDs).Update("milestone_id", milestone.ID).Error
+}
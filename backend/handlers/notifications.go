@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetMyNotifications godoc
+// @Summary Get my notifications
+// @Description Get the current user's notifications, newest first (at most 100)
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} utils.Response{data=[]models.Notification}
+// @Failure 401 {object} utils.Response
+// @Router /notifications [get]
+func GetMyNotifications(c *gin.Context) {
+	query := config.DB.Where("user_id = ?", c.MustGet("user_id").(uint))
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Limit(100).Find(&notifications).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve notifications")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Notifications retrieved successfully", notifications)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark notification as read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.Response{data=models.Notification}
+// @Failure 404 {object} utils.Response
+// @Router /notifications/{id}/read [patch]
+func MarkNotificationRead(c *gin.Context) {
+	var notification models.Notification
+	if err := config.DB.Where("user_id = ?", c.MustGet("user_id").(uint)).First(&notification, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Notification not found")
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		config.DB.Model(&notification).Update("read_at", now)
+	}
+
+	utils.SuccessResponse(c, 200, "Notification marked as read", notification)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /notifications/read-all [post]
+func MarkAllNotificationsRead(c *gin.Context) {
+	if err := config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", c.MustGet("user_id").(uint)).
+		Update("read_at", time.Now()).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update notifications")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Notifications marked as read", nil)
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetOffboardingPreview godoc
+// @Summary Preview user offboarding
+// @Description List the open tasks and projects a user is assigned to, before offboarding them
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=models.OffboardingReport}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/offboarding [get]
+func GetOffboardingPreview(c *gin.Context) {
+	user, ok := loadOffboardingUser(c)
+	if !ok {
+		return
+	}
+
+	tasks, projects, err := openWork(config.DB, user.ID)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve open work")
+		return
+	}
+
+	report := models.OffboardingReport{
+		UserID:   user.ID,
+		Tasks:    make([]models.OffboardingItem, 0, len(tasks)),
+		Projects: make([]models.OffboardingItem, 0, len(projects)),
+	}
+	for i := range tasks {
+		report.Tasks = append(report.Tasks, taskItem(&tasks[i]))
+	}
+	for i := range projects {
+		report.Projects = append(report.Projects, projectItem(&projects[i]))
+	}
+
+	utils.SuccessResponse(c, 200, "Offboarding preview retrieved successfully", report)
+}
+
+// OffboardUser godoc
+// @Summary Offboard user
+// @Description Deactivate a user, revoke their sessions and hand over their open tasks and projects, in one transaction.
+// @Description With reassign_to_id the work goes to that user where they belong to the project's area;
+// @Description otherwise (or where they don't) it returns to backlog when nobody else is assigned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param offboarding body OffboardUserRequest false "Recipient of the open work"
+// @Success 200 {object} utils.Response{data=models.OffboardingReport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/offboard [post]
+func OffboardUser(c *gin.Context) {
+	var req models.OffboardUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, 400, err.Error())
+			return
+		}
+	}
+
+	user, ok := loadOffboardingUser(c)
+	if !ok {
+		return
+	}
+
+	var recipient *models.User
+	if req.ReassignToID != nil {
+		recipient = &models.User{}
+		if err := config.DB.Where("is_active = ?", true).First(recipient, *req.ReassignToID).Error; err != nil {
+			utils.ErrorResponse(c, 404, "Recipient not found or inactive")
+			return
+		}
+		if recipient.ID == user.ID {
+			utils.ErrorResponse(c, 400, "Recipient must be another user")
+			return
+		}
+	}
+
+	var report *models.OffboardingReport
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = offboardUser(tx, policy.FromContext(c), c.MustGet("user_id").(uint), user, recipient)
+		return err
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to offboard user")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User offboarded successfully", report)
+}
+
+// loadOffboardingUser loads the user of the :id parameter and checks the caller may offboard them
+func loadOffboardingUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return nil, false
+	}
+
+	subject := policy.FromContext(c)
+	if user.ID == subject.UserID {
+		utils.ErrorResponse(c, 400, "You cannot offboard yourself")
+		return nil, false
+	}
+	if !subject.CanManageUser(&user) || (user.Role != models.RoleUser && !subject.IsGlobal(models.PermUsersManage)) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return nil, false
+	}
+	return &user, true
+}
+
+// openWork returns the unfinished tasks and area projects the user is actively assigned to
+func openWork(db *gorm.DB, userID uint) ([]models.Task, []models.Project, error) {
+	var tasks []models.Task
+	if err := db.Preload("Project").
+		Where("id IN (?)", db.Model(&models.TaskAssignment{}).Select("task_id").Where("user_id = ? AND is_active = ?", userID, true)).
+		Where("status <> ?", models.TaskStatusCompleted).
+		Order("project_id ASC, id ASC").
+		Find(&tasks).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var projects []models.Project
+	if err := db.
+		Where("id IN (?)", db.Model(&models.ProjectAssignment{}).Select("project_id").Where("user_id = ? AND is_active = ?", userID, true)).
+		Where("status <> ? AND project_type = ?", models.ProjectStatusCompleted, models.ProjectTypeArea).
+		Order("id ASC").
+		Find(&projects).Error; err != nil {
+		return nil, nil, err
+	}
+	return tasks, projects, nil
+}
+
+// offboardUser deactivates the user, revokes their tokens and hands their open work over to the
+// recipient, or back to backlog when recipient is nil. It must run inside a transaction.
+func offboardUser(tx *gorm.DB, subject *policy.Subject, actorID uint, user *models.User, recipient *models.User) (*models.OffboardingReport, error) {
+	now := time.Now()
+
+	tasks, projects, err := openWork(tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.OffboardingReport{
+		UserID:   user.ID,
+		Tasks:    make([]models.OffboardingItem, 0, len(tasks)),
+		Projects: make([]models.OffboardingItem, 0, len(projects)),
+	}
+	if recipient != nil {
+		report.ReassignedTo = &recipient.ID
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		item := taskItem(task)
+
+		if err := unassignTaskFrom(tx, task.ID, user.ID, actorID); err != nil {
+			return nil, err
+		}
+
+		if recipient != nil {
+			item.Reason = handoverBlocker(tx, subject.CanManageTask(task), recipient, models.ProjectAreaIDs(tx, &task.Project))
+			if item.Reason == "" {
+				if err := assignTaskTo(tx, task.ID, recipient.ID, actorID); err != nil {
+					return nil, err
+				}
+				item.Action = models.OffboardingReassigned
+				report.TasksReassigned++
+				report.Tasks = append(report.Tasks, item)
+				continue
+			}
+		}
+
+		var remaining int64
+		tx.Model(&models.TaskAssignment{}).Where("task_id = ? AND is_active = ?", task.ID, true).Count(&remaining)
+		if remaining == 0 {
+			if err := tx.Model(task).Update("status", models.TaskStatusBacklog).Error; err != nil {
+				return nil, err
+			}
+			item.Action = models.OffboardingReturnedToBacklog
+			report.TasksReturnedToBacklog++
+		} else {
+			item.Action = models.OffboardingUnassigned
+			report.TasksUnassigned++
+		}
+		report.Tasks = append(report.Tasks, item)
+	}
+
+	for i := range projects {
+		project := &projects[i]
+		item := projectItem(project)
+
+		// The recipient takes over the departing member's role
+		role, ok := models.ProjectMemberRole(tx, project.ID, user.ID)
+		if !ok {
+			role = models.MemberRoleContributor
+		}
+		if err := unassignProjectFrom(tx, project.ID, user.ID, actorID); err != nil {
+			return nil, err
+		}
+
+		if recipient != nil {
+			item.Reason = handoverBlocker(tx, subject.CanManageProject(project), recipient, models.ProjectAreaIDs(tx, project))
+			if item.Reason == "" {
+				if err := assignProjectTo(tx, project.ID, recipient.ID, actorID, role); err != nil {
+					return nil, err
+				}
+				item.Action = models.OffboardingReassigned
+				report.ProjectsReassigned++
+				report.Projects = append(report.Projects, item)
+				continue
+			}
+		}
+
+		var remaining int64
+		tx.Model(&models.ProjectAssignment{}).Where("project_id = ? AND is_active = ?", project.ID, true).Count(&remaining)
+		if remaining == 0 {
+			if err := tx.Model(project).Update("status", models.ProjectStatusUnassigned).Error; err != nil {
+				return nil, err
+			}
+			item.Action = models.OffboardingReturnedToBacklog
+		} else {
+			item.Action = models.OffboardingUnassigned
+		}
+		report.ProjectsUnassigned++
+		report.Projects = append(report.Projects, item)
+	}
+
+	user.SetActive(false, models.DeactivatedByOffboarding)
+	updates := user.ActivationUpdates()
+	updates["tokens_revoked_at"] = now
+	if err := tx.Model(user).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	report.Deactivated = true
+	report.TokensRevoked = true
+	report.OffboardedAt = &now
+
+	return report, nil
+}
+
+// handoverBlocker explains why work cannot go to the recipient, or returns "" when it can
+func handoverBlocker(tx *gorm.DB, canManage bool, recipient *models.User, areaIDs []uint) string {
+	if !canManage {
+		return "Outside the areas you manage"
+	}
+	if len(areaIDs) > 0 && !recipient.BelongsToAnyArea(tx, areaIDs) {
+		return "Recipient does not belong to the project's areas"
+	}
+	return ""
+}
+
+func taskItem(task *models.Task) models.OffboardingItem {
+	return models.OffboardingItem{
+		ID:        task.ID,
+		Name:      task.Name,
+		Status:    string(task.Status),
+		ProjectID: &task.ProjectID,
+	}
+}
+
+func projectItem(project *models.Project) models.OffboardingItem {
+	return models.OffboardingItem{
+		ID:     project.ID,
+		Name:   project.Name,
+		Status: string(project.Status),
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/jaliko05/time-flow/models"
+)
+
+func TestHandoverBlocker(t *testing.T) {
+	recipient := &models.User{ID: 2}
+
+	if got := handoverBlocker(nil, false, recipient, nil); got != "Outside the areas you manage" {
+		t.Errorf("handoverBlocker() without permission = %q", got)
+	}
+	if got := handoverBlocker(nil, true, recipient, nil); got != "" {
+		t.Errorf("handoverBlocker() for work outside any area = %q, want no blocker", got)
+	}
+}
+
+func TestOffboardingItems(t *testing.T) {
+	task := &models.Task{ID: 5, Name: "Deploy", Status: models.TaskStatusInProgress, ProjectID: 9}
+	item := taskItem(task)
+	if item.ID != 5 || item.Name != "Deploy" || item.Status != string(models.TaskStatusInProgress) || item.ProjectID == nil || *item.ProjectID != 9 {
+		t.Errorf("taskItem() = %+v", item)
+	}
+
+	project := &models.Project{ID: 9, Name: "Website", Status: models.ProjectStatusInProgress}
+	if item := projectItem(project); item.ID != 9 || item.Name != "Website" || item.ProjectID != nil {
+		t.Errorf("projectItem() = %+v", item)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// AddProjectArea godoc
+// @Summary Share project with an area
+// @Description Add a participating area to an area project. Members of the area can then be assigned to the project,
+// @Description and the area's project managers can assign their own people.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param area body ShareProjectRequest true "Area to share with"
+// @Success 201 {object} utils.Response{data=[]models.ProjectArea}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/areas [post]
+func AddProjectArea(c *gin.Context) {
+	var req models.ShareProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "You don't have permission to share this project")
+		return
+	}
+	if project.ProjectType != models.ProjectTypeArea {
+		utils.ErrorResponse(c, 400, "Only area projects can be shared with other areas")
+		return
+	}
+	if msg := checkSharedArea(project.AreaID, req.AreaID); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.ProjectArea{}).Where("project_id = ? AND area_id = ?", project.ID, req.AreaID).Count(&count)
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "The project is already shared with this area")
+		return
+	}
+
+	if err := config.DB.Create(&models.ProjectArea{ProjectID: project.ID, AreaID: req.AreaID, AddedBy: subject.UserID}).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to share project")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Project shared successfully", sharedAreas(project.ID))
+}
+
+// RemoveProjectArea godoc
+// @Summary Stop sharing project with an area
+// @Description Remove a participating area from a project. Members who belong to none of the remaining areas are unassigned from it and its tasks.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param areaId path int true "Area ID"
+// @Success 200 {object} utils.Response{data=[]models.ProjectArea}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/areas/{areaId} [delete]
+func RemoveProjectArea(c *gin.Context) {
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "You don't have permission to share this project")
+		return
+	}
+
+	var shared models.ProjectArea
+	if err := config.DB.Where("project_id = ? AND area_id = ?", project.ID, c.Param("areaId")).First(&shared).Error; err != nil {
+		utils.ErrorResponse(c, 404, "The project is not shared with this area")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&shared).Error; err != nil {
+			return err
+		}
+
+		remaining := models.ProjectAreaIDs(tx, &project)
+		var members []models.ProjectAssignment
+		tx.Preload("User").Where("project_id = ? AND is_active = ?", project.ID, true).Find(&members)
+		for _, member := range members {
+			if member.User.BelongsToAnyArea(tx, remaining) {
+				continue
+			}
+			if err := unassignProjectFrom(tx, project.ID, member.UserID, subject.UserID); err != nil {
+				return err
+			}
+			var taskIDs []uint
+			tx.Model(&models.TaskAssignment{}).
+				Where("user_id = ? AND is_active = ? AND task_id IN (?)", member.UserID, true, tx.Model(&models.Task{}).Select("id").Where("project_id = ?", project.ID)).
+				Pluck("task_id", &taskIDs)
+			for _, taskID := range taskIDs {
+				if err := unassignTaskFrom(tx, taskID, member.UserID, subject.UserID); err != nil {
+					return err
+				}
+			}
+		}
+
+		var active int64
+		tx.Model(&models.ProjectAssignment{}).Where("project_id = ? AND is_active = ?", project.ID, true).Count(&active)
+		if active == 0 && project.Status == models.ProjectStatusAssigned {
+			return tx.Model(&project).Update("status", models.ProjectStatusUnassigned).Error
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to stop sharing project")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project area removed successfully", sharedAreas(project.ID))
+}
+
+// checkSharedArea checks a project hosted in hostAreaID can be shared with the area, returning an error message or ""
+func checkSharedArea(hostAreaID *uint, areaID uint) string {
+	if hostAreaID != nil && *hostAreaID == areaID {
+		return "The host area cannot also be a shared area"
+	}
+	var area models.Area
+	if err := config.DB.Where("is_active = ?", true).First(&area, areaID).Error; err != nil {
+		return "Shared area not found"
+	}
+	return ""
+}
+
+func sharedAreas(projectID uint) []models.ProjectArea {
+	var areas []models.ProjectArea
+	config.DB.Preload("Area").Where("project_id = ?", projectID).Order("id ASC").Find(&areas)
+	return areas
+}
@@ -0,0 +1,837 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetProjectTemplates godoc
+// @Summary Get project templates
+// @Description Get the project templates. With area_id, returns the templates available in that area (shared and area templates).
+// @Tags project-templates
+// @Produce json
+// @Security BearerAuth
+// @Param area_id query int false "Filter by area ID"
+// @Param search query string false "Search by name"
+// @Success 200 {object} utils.Response{data=[]models.ProjectTemplate}
+// @Failure 401 {object} utils.Response
+// @Router /project-templates [get]
+func GetProjectTemplates(c *gin.Context) {
+	query := config.DB.Model(&models.ProjectTemplate{})
+
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = query.Where("area_id IS NULL OR area_id = ?", uint(areaID))
+		}
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	var templates []models.ProjectTemplate
+	if err := query.Preload("Tasks", orderTemplateTasks).Order("name ASC").Find(&templates).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve project templates")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project templates retrieved successfully", templates)
+}
+
+// GetProjectTemplate godoc
+// @Summary Get project template by ID
+// @Description Get a project template with its tasks
+// @Tags project-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.Response{data=models.ProjectTemplate}
+// @Failure 404 {object} utils.Response
+// @Router /project-templates/{id} [get]
+func GetProjectTemplate(c *gin.Context) {
+	var template models.ProjectTemplate
+	if err := config.DB.Preload("Tasks", orderTemplateTasks).First(&template, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project template not found")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project template retrieved successfully", template)
+}
+
+// CreateProjectTemplate godoc
+// @Summary Create project template
+// @Description Create a project template for an area, or one shared by every area (requires the global projects.manage permission).
+// @Description Tasks depend on each other by key; keys default to the task's position (1, 2, ...).
+// @Tags project-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param template body CreateProjectTemplateRequest true "Template data"
+// @Success 201 {object} utils.Response{data=models.ProjectTemplate}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /project-templates [post]
+func CreateProjectTemplate(c *gin.Context) {
+	var req models.CreateProjectTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermProjectsManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only add templates to areas you manage")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if templateNameTaken(name, req.AreaID, 0) {
+		utils.ErrorResponse(c, 400, "A template with this name already exists")
+		return
+	}
+	tasks, msg := templateTasks(req.Tasks)
+	if msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	priority := req.Priority
+	if priority == "" {
+		priority = models.ProjectPriorityMedium
+	}
+	template := models.ProjectTemplate{
+		Name:         name,
+		Description:  req.Description,
+		AreaID:       req.AreaID,
+		Priority:     priority,
+		DurationDays: req.DurationDays,
+		Billable:     req.Billable,
+		CreatedBy:    c.MustGet("user_id").(uint),
+		Tasks:        tasks,
+	}
+	if err := config.DB.Create(&template).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create project template")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Project template created successfully", template)
+}
+
+// UpdateProjectTemplate godoc
+// @Summary Update project template
+// @Description Update a project template. Sending tasks replaces all of them; projects already created from it are not changed.
+// @Tags project-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param template body UpdateProjectTemplateRequest true "Template data"
+// @Success 200 {object} utils.Response{data=models.ProjectTemplate}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /project-templates/{id} [put]
+func UpdateProjectTemplate(c *gin.Context) {
+	var req models.UpdateProjectTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var template models.ProjectTemplate
+	if err := config.DB.First(&template, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project template not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermProjectsManage, template.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if templateNameTaken(name, template.AreaID, template.ID) {
+			utils.ErrorResponse(c, 400, "A template with this name already exists")
+			return
+		}
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Priority != "" {
+		updates["priority"] = req.Priority
+	}
+	if req.DurationDays != nil {
+		updates["duration_days"] = *req.DurationDays
+	}
+	if req.Billable != nil {
+		updates["billable"] = *req.Billable
+	}
+
+	var tasks []models.ProjectTemplateTask
+	if req.Tasks != nil {
+		var msg string
+		if tasks, msg = templateTasks(req.Tasks); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&template).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.Tasks == nil {
+			return nil
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&models.ProjectTemplateTask{}).Error; err != nil {
+			return err
+		}
+		for i := range tasks {
+			tasks[i].TemplateID = template.ID
+			if err := tx.Create(&tasks[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update project template")
+		return
+	}
+
+	config.DB.Preload("Tasks", orderTemplateTasks).First(&template, template.ID)
+
+	utils.SuccessResponse(c, 200, "Project template updated successfully", template)
+}
+
+// DeleteProjectTemplate godoc
+// @Summary Delete project template
+// @Description Soft delete a project template. Projects created from it are not affected.
+// @Tags project-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /project-templates/{id} [delete]
+func DeleteProjectTemplate(c *gin.Context) {
+	var template models.ProjectTemplate
+	if err := config.DB.First(&template, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project template not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermProjectsManage, template.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	if err := config.DB.Delete(&template).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete project template")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Project template deleted successfully", nil)
+}
+
+// SaveProjectAsTemplate godoc
+// @Summary Save project as template
+// @Description Create a template from a project's active tasks: priorities, estimates, dependencies and due dates as offsets from the project start.
+// @Description Assignee roles are left empty to be filled in on the template.
+// @Tags project-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param template body SaveProjectAsTemplateRequest true "Template data"
+// @Success 201 {object} utils.Response{data=models.ProjectTemplate}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/save-as-template [post]
+func SaveProjectAsTemplate(c *gin.Context) {
+	var req models.SaveProjectAsTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanViewProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	areaID := project.AreaID
+	if req.AreaID != nil {
+		areaID = req.AreaID
+	}
+	if !subject.Can(models.PermProjectsManage, areaID) {
+		utils.ErrorResponse(c, 403, "You can only add templates to areas you manage")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if templateNameTaken(name, areaID, 0) {
+		utils.ErrorResponse(c, 400, "A template with this name already exists")
+		return
+	}
+
+	tasks, dependsOn := projectTasks(project.ID)
+	keys := make(map[uint]string, len(tasks))
+	for i, t := range tasks {
+		keys[t.ID] = strconv.Itoa(i + 1)
+	}
+
+	start := projectStart(&project)
+	template := models.ProjectTemplate{
+		Name:        name,
+		Description: req.Description,
+		AreaID:      areaID,
+		Priority:    project.Priority,
+		Billable:    project.Billable,
+		CreatedBy:   subject.UserID,
+	}
+	if project.DueDate != nil {
+		template.DurationDays = max(daysBetween(start, *project.DueDate), 0)
+	}
+	for i, t := range tasks {
+		templateTask := models.ProjectTemplateTask{
+			Key:            keys[t.ID],
+			Name:           t.Name,
+			Description:    t.Description,
+			Priority:       t.Priority,
+			EstimatedHours: t.EstimatedHours,
+			Order:          i,
+			DependsOn:      []string{},
+		}
+		if t.DueDate != nil {
+			offset := max(daysBetween(start, *t.DueDate), 0)
+			templateTask.DueOffsetDays = &offset
+		}
+		for _, id := range dependsOn[t.ID] {
+			templateTask.DependsOn = append(templateTask.DependsOn, keys[id])
+		}
+		template.Tasks = append(template.Tasks, templateTask)
+	}
+
+	if err := config.DB.Create(&template).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create project template")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Project template created successfully", template)
+}
+
+// InstantiateProjectTemplate godoc
+// @Summary Create project from template
+// @Description Create an area project from a template starting on start_date. Task due dates are computed from their offsets
+// @Description and each task is assigned to the users given for its assignee role; those users become the project's members.
+// @Tags project-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param project body InstantiateTemplateRequest true "Project data"
+// @Success 201 {object} utils.Response{data=models.Project}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /project-templates/{id}/instantiate [post]
+func InstantiateProjectTemplate(c *gin.Context) {
+	var req models.InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var template models.ProjectTemplate
+	if err := config.DB.Preload("Tasks", orderTemplateTasks).First(&template, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project template not found")
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid start_date format. Use YYYY-MM-DD")
+		return
+	}
+
+	// The project goes to the requested area, the template's, or the caller's primary area
+	areaID := template.AreaID
+	if req.AreaID != nil {
+		areaID = req.AreaID
+	}
+	if areaID == nil {
+		userAreaID, _ := c.Get("user_area_id")
+		areaID, _ = userAreaID.(*uint)
+	}
+	if areaID == nil {
+		utils.ErrorResponse(c, 400, "Area ID is required")
+		return
+	}
+	if template.AreaID != nil && *template.AreaID != *areaID {
+		utils.ErrorResponse(c, 400, "The template belongs to another area")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.Can(models.PermProjectsManage, areaID) {
+		utils.ErrorResponse(c, 403, "You don't have permission to create area projects")
+		return
+	}
+
+	// Assignees by role: known roles only, and users from the area unless the caller manages projects everywhere
+	roles := make(map[string]bool)
+	for _, t := range template.Tasks {
+		if t.AssigneeRole != "" {
+			roles[t.AssigneeRole] = true
+		}
+	}
+	assignees := make(map[string][]uint, len(req.Assignees))
+	var members []uint
+	for role, userIDs := range req.Assignees {
+		if !roles[role] {
+			utils.ErrorResponse(c, 400, "The template has no tasks for role "+role)
+			return
+		}
+		assignees[role] = uniqueIDs(userIDs)
+		members = append(members, assignees[role]...)
+	}
+	members = uniqueIDs(members)
+	for _, userID := range members {
+		var user models.User
+		if err := config.DB.Where("is_active = ?", true).First(&user, userID).Error; err != nil {
+			utils.ErrorResponse(c, 404, "Assigned user not found: "+strconv.FormatUint(uint64(userID), 10))
+			return
+		}
+		if !subject.IsGlobal(models.PermProjectsManage) && !user.BelongsToArea(config.DB, *areaID) {
+			utils.ErrorResponse(c, 403, "Can only assign users from your area")
+			return
+		}
+	}
+
+	draft := projectDraft{
+		project: models.Project{
+			Name:        req.Name,
+			Description: template.Description,
+			CreatedBy:   subject.UserID,
+			AreaID:      areaID,
+			ProjectType: models.ProjectTypeArea,
+			Status:      models.ProjectStatusUnassigned,
+			Priority:    template.Priority,
+			StartDate:   &startDate,
+			IsActive:    true,
+			Billable:    template.Billable,
+		},
+		members: make([]models.ProjectAssignment, 0, len(members)),
+	}
+	for _, userID := range members {
+		draft.members = append(draft.members, models.ProjectAssignment{UserID: userID, Role: models.MemberRoleContributor})
+	}
+	if template.DurationDays > 0 {
+		dueDate := startDate.AddDate(0, 0, template.DurationDays)
+		draft.project.DueDate = &dueDate
+	}
+
+	positions := make(map[string]int, len(template.Tasks))
+	for i, t := range template.Tasks {
+		positions[t.Key] = i
+	}
+	for _, t := range template.Tasks {
+		task := taskDraft{
+			task: models.Task{
+				Name:           t.Name,
+				Description:    t.Description,
+				Priority:       t.Priority,
+				EstimatedHours: t.EstimatedHours,
+				Order:          t.Order,
+			},
+			assignees: assignees[t.AssigneeRole],
+		}
+		if t.DueOffsetDays != nil {
+			dueDate := startDate.AddDate(0, 0, *t.DueOffsetDays)
+			task.task.DueDate = &dueDate
+		}
+		for _, key := range t.DependsOn {
+			if position, ok := positions[key]; ok {
+				task.dependsOn = append(task.dependsOn, position)
+			}
+		}
+		draft.project.EstimatedHours += t.EstimatedHours
+		draft.tasks = append(draft.tasks, task)
+	}
+
+	if err := createProjectDraft(&draft, subject.UserID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create project from template")
+		return
+	}
+
+	project := draft.project
+	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("Area").Preload("Tasks").First(&project, project.ID)
+
+	utils.SuccessResponse(c, 201, "Project created from template successfully", project)
+}
+
+// CloneProject godoc
+// @Summary Clone project
+// @Description Copy a project with its active tasks, estimates, dependencies, milestones and required skills into a new project starting on start_date.
+// @Description Task and milestone due dates keep their distance from the start. With include_assignments, members (with their roles) and task assignees are copied too.
+// @Description Logged hours, activities and comments are not copied, and every task starts over.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param project body CloneProjectRequest true "Clone options"
+// @Success 201 {object} utils.Response{data=models.Project}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/clone [post]
+func CloneProject(c *gin.Context) {
+	var req models.CloneProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var source models.Project
+	if err := config.DB.First(&source, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if !subject.CanCloneProject(&source) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Invalid start_date format. Use YYYY-MM-DD")
+		return
+	}
+	shift := daysBetween(projectStart(&source), startDate)
+
+	draft := projectDraft{
+		project: models.Project{
+			Name:             req.Name,
+			Description:      source.Description,
+			CreatedBy:        subject.UserID,
+			AreaID:           source.AreaID,
+			ClientID:         source.ClientID,
+			BudgetHours:      source.BudgetHours,
+			BudgetAmount:     source.BudgetAmount,
+			BudgetThresholds: source.BudgetThresholds,
+			ProjectType:      source.ProjectType,
+			Status:           models.ProjectStatusUnassigned,
+			Priority:         source.Priority,
+			EstimatedHours:   source.EstimatedHours,
+			StartDate:        &startDate,
+			DueDate:          shiftDate(source.DueDate, shift),
+			IsActive:         true,
+			Billable:         source.Billable,
+		},
+	}
+	config.DB.Where("project_id = ?", source.ID).Find(&draft.skills)
+	draft.areas = models.SharedAreaIDs(config.DB, source.ID)
+
+	// Personal projects belong to whoever clones them, like the ones they create
+	if source.ProjectType == models.ProjectTypePersonal {
+		draft.project.Status = models.ProjectStatusInProgress
+		draft.members = []models.ProjectAssignment{{UserID: subject.UserID, Role: models.MemberRoleOwner}}
+	} else if req.IncludeAssignments {
+		config.DB.Select("project_assignments.user_id", "project_assignments.role").
+			Joins("JOIN users ON users.id = project_assignments.user_id AND users.is_active = ?", true).
+			Where("project_assignments.project_id = ? AND project_assignments.is_active = ?", source.ID, true).
+			Find(&draft.members)
+	}
+
+	var milestones []models.Milestone
+	config.DB.Where("project_id = ?", source.ID).Order("due_date ASC, id ASC").Find(&milestones)
+	milestonePositions := make(map[uint]int, len(milestones))
+	for i, m := range milestones {
+		milestonePositions[m.ID] = i
+		m.DueDate = m.DueDate.AddDate(0, 0, shift)
+		draft.milestones = append(draft.milestones, m)
+	}
+
+	tasks, dependsOn := projectTasks(source.ID)
+	positions := make(map[uint]int, len(tasks))
+	for i, t := range tasks {
+		positions[t.ID] = i
+	}
+	for _, t := range tasks {
+		task := taskDraft{
+			task: models.Task{
+				Name:           t.Name,
+				Description:    t.Description,
+				Priority:       t.Priority,
+				EstimatedHours: t.EstimatedHours,
+				Order:          t.Order,
+				DueDate:        shiftDate(t.DueDate, shift),
+			},
+		}
+		config.DB.Where("task_id = ?", t.ID).Find(&task.skills)
+		if req.IncludeAssignments {
+			config.DB.Model(&models.TaskAssignment{}).
+				Joins("JOIN users ON users.id = task_assignments.user_id AND users.is_active = ?", true).
+				Where("task_assignments.task_id = ? AND task_assignments.is_active = ?", t.ID, true).
+				Pluck("task_assignments.user_id", &task.assignees)
+		}
+		if t.MilestoneID != nil {
+			if position, ok := milestonePositions[*t.MilestoneID]; ok {
+				task.milestone = &position
+			}
+		}
+		for _, id := range dependsOn[t.ID] {
+			task.dependsOn = append(task.dependsOn, positions[id])
+		}
+		draft.tasks = append(draft.tasks, task)
+	}
+
+	if err := createProjectDraft(&draft, subject.UserID); err != nil {
+		utils.ErrorResponse(c, 500, "Failed to clone project")
+		return
+	}
+
+	project := draft.project
+	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("Area").Preload("Client").Preload("Tasks").First(&project, project.ID)
+
+	utils.SuccessResponse(c, 201, "Project cloned successfully", project)
+}
+
+// projectDraft is a project to create with its tasks, built from a template or copied from another project
+type projectDraft struct {
+	project    models.Project
+	members    []models.ProjectAssignment // User and role of each member
+	areas      []uint                     // Areas the project is shared with
+	skills     []models.ProjectSkill
+	milestones []models.Milestone
+	tasks      []taskDraft
+}
+
+type taskDraft struct {
+	task      models.Task
+	assignees []uint
+	skills    []models.TaskSkill
+	dependsOn []int // Positions in projectDraft.tasks
+	milestone *int  // Position in projectDraft.milestones
+}
+
+// createProjectDraft creates the project with its members, skills, milestones, tasks, task assignees and dependencies in one transaction.
+// Tasks with assignees start assigned and the rest in the backlog.
+func createProjectDraft(draft *projectDraft, assignedBy uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		project := &draft.project
+		if len(draft.members) > 0 && project.Status == models.ProjectStatusUnassigned {
+			project.Status = models.ProjectStatusAssigned
+		}
+		project.RemainingHours = project.EstimatedHours
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+
+		for _, member := range draft.members {
+			if err := assignProjectTo(tx, project.ID, member.UserID, assignedBy, member.Role); err != nil {
+				return err
+			}
+		}
+		for _, areaID := range draft.areas {
+			if err := tx.Create(&models.ProjectArea{ProjectID: project.ID, AreaID: areaID, AddedBy: assignedBy}).Error; err != nil {
+				return err
+			}
+		}
+		for _, s := range draft.skills {
+			if err := tx.Create(&models.ProjectSkill{ProjectID: project.ID, SkillID: s.SkillID, MinLevel: s.MinLevel}).Error; err != nil {
+				return err
+			}
+		}
+
+		milestoneIDs := make([]uint, len(draft.milestones))
+		for i, m := range draft.milestones {
+			milestone := models.Milestone{
+				ProjectID:   project.ID,
+				Name:        m.Name,
+				Description: m.Description,
+				DueDate:     m.DueDate,
+				CreatedBy:   assignedBy,
+			}
+			if err := tx.Create(&milestone).Error; err != nil {
+				return err
+			}
+			milestoneIDs[i] = milestone.ID
+		}
+
+		taskIDs := make([]uint, len(draft.tasks))
+		for i := range draft.tasks {
+			td := &draft.tasks[i]
+			task := &td.task
+			task.ProjectID = project.ID
+			task.CreatedBy = assignedBy
+			task.IsActive = true
+			task.Status = models.TaskStatusBacklog
+			if len(td.assignees) > 0 {
+				task.Status = models.TaskStatusAssigned
+			}
+			if task.Priority == "" {
+				task.Priority = models.TaskPriorityMedium
+			}
+			if td.milestone != nil {
+				task.MilestoneID = &milestoneIDs[*td.milestone]
+			}
+			if err := tx.Create(task).Error; err != nil {
+				return err
+			}
+			taskIDs[i] = task.ID
+
+			for _, userID := range td.assignees {
+				if err := assignTaskTo(tx, task.ID, userID, assignedBy); err != nil {
+					return err
+				}
+			}
+			for _, s := range td.skills {
+				if err := tx.Create(&models.TaskSkill{TaskID: task.ID, SkillID: s.SkillID, MinLevel: s.MinLevel}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for i, t := range draft.tasks {
+			for _, position := range t.dependsOn {
+				if err := tx.Create(&models.TaskDependency{TaskID: taskIDs[i], DependsOnTaskID: taskIDs[position]}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// projectTasks returns a project's active tasks in board order and, for each, the active tasks it depends on
+func projectTasks(projectID uint) ([]models.Task, map[uint][]uint) {
+	var tasks []models.Task
+	config.DB.Where("project_id = ? AND is_active = ?", projectID, true).Order("\"order\" ASC, id ASC").Find(&tasks)
+
+	ids := make([]uint, 0, len(tasks))
+	active := make(map[uint]bool, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+		active[t.ID] = true
+	}
+
+	var dependencies []models.TaskDependency
+	config.DB.Where("task_id IN ?", models.NonEmptyIDs(ids)).Order("id ASC").Find(&dependencies)
+	dependsOn := make(map[uint][]uint, len(tasks))
+	for _, d := range dependencies {
+		if active[d.DependsOnTaskID] {
+			dependsOn[d.TaskID] = append(dependsOn[d.TaskID], d.DependsOnTaskID)
+		}
+	}
+	return tasks, dependsOn
+}
+
+// templateTasks validates template task inputs and converts them, returning an error message or ""
+func templateTasks(inputs []models.TemplateTaskInput) ([]models.ProjectTemplateTask, string) {
+	tasks := make([]models.ProjectTemplateTask, 0, len(inputs))
+	positions := make(map[string]uint, len(inputs))
+	for i, in := range inputs {
+		key := strings.TrimSpace(in.Key)
+		if key == "" {
+			key = strconv.Itoa(i + 1)
+		}
+		if _, ok := positions[key]; ok {
+			return nil, "Duplicate task key: " + key
+		}
+		positions[key] = uint(i + 1)
+
+		priority := in.Priority
+		if priority == "" {
+			priority = models.TaskPriorityMedium
+		}
+		dependsOn := in.DependsOn
+		if dependsOn == nil {
+			dependsOn = []string{}
+		}
+		tasks = append(tasks, models.ProjectTemplateTask{
+			Key:            key,
+			Name:           strings.TrimSpace(in.Name),
+			Description:    in.Description,
+			Priority:       priority,
+			EstimatedHours: in.EstimatedHours,
+			Order:          i,
+			DueOffsetDays:  in.DueOffsetDays,
+			AssigneeRole:   strings.TrimSpace(in.AssigneeRole),
+			DependsOn:      dependsOn,
+		})
+	}
+
+	graph := make(map[uint][]uint, len(tasks))
+	for i, t := range tasks {
+		for _, key := range t.DependsOn {
+			position, ok := positions[key]
+			if !ok {
+				return nil, "Unknown dependency key: " + key
+			}
+			graph[uint(i+1)] = append(graph[uint(i+1)], position)
+		}
+	}
+	if models.HasDependencyCycle(graph) {
+		return nil, "Task dependencies would create a cycle"
+	}
+	return tasks, ""
+}
+
+func templateNameTaken(name string, areaID *uint, excludeID uint) bool {
+	query := config.DB.Model(&models.ProjectTemplate{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID)
+	if areaID == nil {
+		query = query.Where("area_id IS NULL")
+	} else {
+		query = query.Where("area_id = ?", *areaID)
+	}
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+func orderTemplateTasks(db *gorm.DB) *gorm.DB {
+	return db.Order("\"order\" ASC, id ASC")
+}
+
+// projectStart returns the date a project started: its start date, or its creation date
+func projectStart(project *models.Project) time.Time {
+	if project.StartDate != nil {
+		return *project.StartDate
+	}
+	return project.CreatedAt
+}
+
+// daysBetween returns the number of calendar days from one date to another
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func shiftDate(date *time.Time, days int) *time.Time {
+	if date == nil {
+		return nil
+	}
+	shifted := date.AddDate(0, 0, days)
+	return &shifted
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysBetween(t *testing.T) {
+	bogota := time.FixedZone("COT", -5*60*60)
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		madrid = time.FixedZone("CET", 60*60)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"same day", time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), 0},
+		{"next day", time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 1, 0, 0, 0, time.UTC), 1},
+		{"backwards", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), -7},
+		{"across months", time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), 31},
+		{"across leap day", time.Date(2028, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC), 2},
+		{"calendar dates in other zones", time.Date(2026, 3, 2, 22, 0, 0, 0, bogota), time.Date(2026, 3, 3, 0, 30, 0, 0, time.UTC), 1},
+		{"across daylight saving change", time.Date(2026, 3, 28, 0, 0, 0, 0, madrid), time.Date(2026, 3, 30, 0, 0, 0, 0, madrid), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("daysBetween() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftDate(t *testing.T) {
+	if shiftDate(nil, 5) != nil {
+		t.Error("shiftDate(nil) should stay nil")
+	}
+
+	date := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	shifted := shiftDate(&date, 31)
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !shifted.Equal(want) {
+		t.Errorf("shiftDate() = %v, want %v", shifted, want)
+	}
+	if !date.Equal(time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Error("shiftDate() changed the original date")
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
 )
 
 // GetProjects godoc
@@ -17,36 +19,30 @@ import (
 // @Produce json
 // @Security BearerAuth
 // @Param area_id query int false "Filter by area ID (SuperAdmin only)"
+// @Param client_id query int false "Filter by client ID"
 // @Param assigned_user_id query int false "Filter by assigned user ID"
 // @Param active query bool false "Filter by active status"
 // @Success 200 {object} utils.Response{data=[]models.Project}
 // @Failure 401 {object} utils.Response
 // @Router /projects [get]
 func GetProjects(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
+	subject := policy.FromContext(c)
 
-	query := config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area")
-
-	// Apply filters based on role
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		// Regular users see projects assigned to them through project_assignments
-		query = query.Joins("LEFT JOIN project_assignments ON project_assignments.project_id = projects.id").
-			Where("project_assignments.user_id = ? AND project_assignments.is_active = ?", userID, true)
-	} else if role == models.RoleAdmin {
-		// Admins see projects from their area
-		if userAreaID != nil {
-			query = query.Where("area_id = ?", userAreaID)
-		}
-	}
-	// SuperAdmin sees all projects by default
+	query := config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").Preload("Client")
+
+	// Restrict to projects the user can see (area permissions, assignments and personal projects)
+	query = subject.ScopeProjects(query)
 
 	// Optional filters
-	if areaIDStr := c.Query("area_id"); areaIDStr != "" && role == models.RoleSuperAdmin {
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
 		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
-			query = query.Where("area_id = ?", uint(areaID))
+			query = query.Where("projects.area_id = ?", uint(areaID))
+		}
+	}
+
+	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
+		if clientID, err := strconv.ParseUint(clientIDStr, 10, 32); err == nil {
+			query = query.Where("projects.client_id = ?", uint(clientID))
 		}
 	}
 
@@ -59,14 +55,14 @@ func GetProjects(c *gin.Context) {
 
 	if activeStr := c.Query("active"); activeStr != "" {
 		if activeStr == "true" {
-			query = query.Where("is_active = ?", true)
+			query = query.Where("projects.is_active = ?", true)
 		} else if activeStr == "false" {
-			query = query.Where("is_active = ?", false)
+			query = query.Where("projects.is_active = ?", false)
 		}
 	}
 
 	var projects []models.Project
-	if err := query.Distinct().Order("created_at DESC").Find(&projects).Error; err != nil {
+	if err := query.Distinct().Order("projects.created_at DESC").Find(&projects).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to retrieve projects")
 		return
 	}
@@ -76,7 +72,7 @@ func GetProjects(c *gin.Context) {
 
 // GetProject godoc
 // @Summary Get project by ID
-// @Description Get a specific project
+// @Description Get a specific project, with its milestones and their progress
 // @Tags projects
 // @Produce json
 // @Security BearerAuth
@@ -87,12 +83,9 @@ func GetProjects(c *gin.Context) {
 // @Router /projects/{id} [get]
 func GetProject(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var project models.Project
-	query := config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area")
+	query := config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").Preload("SharedAreas.Area").Preload("Client").Preload("RequiredSkills.Skill")
 
 	if err := query.First(&project, id).Error; err != nil {
 		utils.ErrorResponse(c, 404, "Project not found")
@@ -100,35 +93,21 @@ func GetProject(c *gin.Context) {
 	}
 
 	// Check access permissions
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		// Users can only see projects assigned to them through project_assignments
-		var assignment models.ProjectAssignment
-		err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, userID.(uint), true).
-			First(&assignment).Error
-		if err != nil {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
-	}
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if project.AreaID == nil || *project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
+	if !policy.FromContext(c).CanViewProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
 	}
 
+	// Milestones with their progress
+	config.DB.Where("project_id = ?", project.ID).Order("due_date ASC, id ASC").Find(&project.Milestones)
+	evaluateMilestones(project.Milestones, currentUserSettings(c).Today())
+
 	utils.SuccessResponse(c, 200, "Project retrieved successfully", project)
 }
 
 // CreateProject godoc
 // @Summary Create new project
-// @Description Create a new project. Users and Admins can create personal projects. Admins can also create area projects and assign them to users in their area.
+// @Description Create a new project. Users and Admins can create personal projects. Admins can also create area projects, share them with other areas (shared_area_ids) and assign them to users of those areas.
 // @Tags projects
 // @Accept json
 // @Produce json
@@ -141,8 +120,8 @@ func GetProject(c *gin.Context) {
 // @Router /projects [post]
 func CreateProject(c *gin.Context) {
 	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
 	userAreaID, _ := c.Get("user_area_id")
+	subject := policy.FromContext(c)
 
 	var req models.CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -150,19 +129,36 @@ func CreateProject(c *gin.Context) {
 		return
 	}
 
-	role := userRole.(models.Role)
+	// Area projects go to the requested area, defaulting to the creator's primary area
+	areaID, _ := userAreaID.(*uint)
+	if req.AreaID != nil {
+		areaID = req.AreaID
+	}
 
 	// Validation for project type
 	if req.ProjectType == models.ProjectTypeArea {
-		// Only admins can create area projects
-		if role == models.RoleUser {
-			utils.ErrorResponse(c, 403, "Only admins can create area projects")
+		// Area projects must have area_id
+		if areaID == nil {
+			utils.ErrorResponse(c, 400, "Area ID is required for area projects")
 			return
 		}
 
-		// Area projects must have area_id
-		if userAreaID == nil {
-			utils.ErrorResponse(c, 400, "Area ID is required for area projects")
+		// Only users allowed to manage the area's projects can create area projects
+		if !subject.Can(models.PermProjectsManage, areaID) {
+			utils.ErrorResponse(c, 403, "You don't have permission to create area projects")
+			return
+		}
+	}
+
+	// Area projects can be shared with other areas, whose members can then be assigned
+	sharedAreaIDs := uniqueIDs(req.SharedAreaIDs)
+	if len(sharedAreaIDs) > 0 && req.ProjectType != models.ProjectTypeArea {
+		utils.ErrorResponse(c, 400, "Only area projects can be shared with other areas")
+		return
+	}
+	for _, sharedAreaID := range sharedAreaIDs {
+		if msg := checkSharedArea(areaID, sharedAreaID); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
 			return
 		}
 	}
@@ -187,14 +183,10 @@ func CreateProject(c *gin.Context) {
 	// Validate assigned users if provided
 	var validatedUserIDs []uint
 	if len(userIDsToAssign) > 0 {
-		var adminAreaID *uint
-		if role == models.RoleAdmin && req.ProjectType == models.ProjectTypeArea {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			adminAreaID = areaID
+		// Area projects only accept users from their host and shared areas, unless the creator manages projects everywhere
+		var restrictToAreaIDs []uint
+		if req.ProjectType == models.ProjectTypeArea && !subject.IsGlobal(models.PermProjectsManage) {
+			restrictToAreaIDs = append([]uint{*areaID}, sharedAreaIDs...)
 		}
 
 		// Validate each user
@@ -205,10 +197,10 @@ func CreateProject(c *gin.Context) {
 				return
 			}
 
-			// If admin creating area project, check that assigned user belongs to the same area
-			if adminAreaID != nil {
-				if assignedUser.AreaID == nil || *assignedUser.AreaID != *adminAreaID {
-					utils.ErrorResponse(c, 403, "Can only assign users from your area")
+			// Check that assigned user belongs to one of the project's areas
+			if restrictToAreaIDs != nil {
+				if !assignedUser.BelongsToAnyArea(config.DB, restrictToAreaIDs) {
+					utils.ErrorResponse(c, 403, "Can only assign users from the project's areas")
 					return
 				}
 			}
@@ -225,10 +217,8 @@ func CreateProject(c *gin.Context) {
 
 	// Set area_id for area projects, nil for personal
 	var projectAreaID *uint
-	if req.ProjectType == models.ProjectTypeArea && userAreaID != nil {
-		if areaID, ok := userAreaID.(*uint); ok && areaID != nil {
-			projectAreaID = areaID
-		}
+	if req.ProjectType == models.ProjectTypeArea {
+		projectAreaID = areaID
 	}
 
 	// Set default priority if not provided
@@ -256,6 +246,13 @@ func CreateProject(c *gin.Context) {
 		dueDate = &parsed
 	}
 
+	if req.ClientID != nil {
+		if msg := checkProjectClient(*req.ClientID, projectAreaID); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+	}
+
 	project := models.Project{
 		Name:              req.Name,
 		Description:       req.Description,
@@ -271,33 +268,43 @@ func CreateProject(c *gin.Context) {
 		StartDate:         startDate,
 		DueDate:           dueDate,
 		IsActive:          true,
+		Billable:          req.Billable,
+		ClientID:          req.ClientID,
+		BudgetHours:       req.BudgetHours,
+		BudgetAmount:      req.BudgetAmount,
+		BudgetThresholds:  req.Thresholds,
 	}
 
-	if err := config.DB.Create(&project).Error; err != nil {
-		utils.ErrorResponse(c, 500, "Failed to create project")
-		return
-	}
-
-	// Create assignments for all validated users
+	// Create the project and assignments for all validated users
 	currentUserID := userID.(uint)
-	for _, assignedUserID := range validatedUserIDs {
-		assignment := models.ProjectAssignment{
-			ProjectID:  project.ID,
-			UserID:     assignedUserID,
-			AssignedBy: currentUserID,
-			IsActive:   true,
-			CanModify:  true,
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
 		}
-		if err := config.DB.Create(&assignment).Error; err != nil {
-			// Log error but don't fail the project creation
-			config.DB.Delete(&project) // Rollback
-			utils.ErrorResponse(c, 500, "Failed to create project assignments")
-			return
+		for _, sharedAreaID := range sharedAreaIDs {
+			if err := tx.Create(&models.ProjectArea{ProjectID: project.ID, AreaID: sharedAreaID, AddedBy: currentUserID}).Error; err != nil {
+				return err
+			}
+		}
+		for _, assignedUserID := range validatedUserIDs {
+			role := models.MemberRoleContributor
+			// The creator owns their personal projects
+			if project.ProjectType == models.ProjectTypePersonal && assignedUserID == currentUserID {
+				role = models.MemberRoleOwner
+			}
+			if err := assignProjectTo(tx, project.ID, assignedUserID, currentUserID, role); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create project")
+		return
 	}
 
 	// Reload to get relations including assigned users
-	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").First(&project, project.ID)
+	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").Preload("Client").First(&project, project.ID)
 
 	utils.SuccessResponse(c, 201, "Project created successfully", project)
 }
@@ -319,8 +326,7 @@ func CreateProject(c *gin.Context) {
 // @Router /projects/{id} [put]
 func UpdateProject(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
+	subject := policy.FromContext(c)
 
 	var req models.UpdateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -334,25 +340,12 @@ func UpdateProject(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: only admin/superadmin can update
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		utils.ErrorResponse(c, 403, "Only admins can update projects")
+	// Check permissions: only project managers of the area can update
+	if !subject.CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "You don't have permission to update this project")
 		return
 	}
 
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if project.AreaID == nil || *project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Can only update projects in your area")
-			return
-		}
-	}
-
 	// Merge single and multiple assignments for backward compatibility
 	userIDsToAssign := req.AssignedUserIDs
 	if req.AssignedUserID != nil {
@@ -362,32 +355,16 @@ func UpdateProject(c *gin.Context) {
 	// Validate assigned users if provided
 	var validatedUserIDs []uint
 	if len(userIDsToAssign) > 0 {
-		var adminAreaID *uint
-		if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil {
-				utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-				return
-			}
-			adminAreaID = areaID
-		}
+		// Only users from the project's host and shared areas can be assigned, unless the editor manages projects everywhere
+		all, areaIDs := subject.AssignableAreas(&project)
 
 		// Validate each user
 		for _, userIDToAssign := range userIDsToAssign {
-			var assignedUser models.User
-			if err := config.DB.First(&assignedUser, userIDToAssign).Error; err != nil {
-				utils.ErrorResponse(c, 404, "Assigned user not found: "+strconv.FormatUint(uint64(userIDToAssign), 10))
+			if code, msg := checkProjectMember(userIDToAssign, all, areaIDs); msg != "" {
+				utils.ErrorResponse(c, code, msg)
 				return
 			}
 
-			// If admin, check that assigned user belongs to the same area
-			if adminAreaID != nil {
-				if assignedUser.AreaID == nil || *assignedUser.AreaID != *adminAreaID {
-					utils.ErrorResponse(c, 403, "Can only assign users from your area")
-					return
-				}
-			}
-
 			validatedUserIDs = append(validatedUserIDs, userIDToAssign)
 		}
 	}
@@ -428,54 +405,71 @@ func UpdateProject(c *gin.Context) {
 	if req.IsActive != nil {
 		project.IsActive = *req.IsActive
 	}
+	if req.Billable != nil {
+		project.Billable = req.Billable
+	}
+	if req.BudgetHours != nil {
+		project.BudgetHours = nonZero(*req.BudgetHours)
+	}
+	if req.BudgetAmount != nil {
+		project.BudgetAmount = nonZero(*req.BudgetAmount)
+	}
+	if req.Thresholds != nil {
+		project.BudgetThresholds = req.Thresholds
+	}
+	if req.ClientID != nil && *req.ClientID == 0 {
+		project.ClientID = nil
+	} else if req.ClientID != nil {
+		if msg := checkProjectClient(*req.ClientID, project.AreaID); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+		project.ClientID = req.ClientID
+	}
 
 	if err := config.DB.Save(&project).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update project")
 		return
 	}
 
-	// Update assignments if provided
-	if len(validatedUserIDs) > 0 {
-		// Deactivate all current assignments
-		config.DB.Model(&models.ProjectAssignment{}).
-			Where("project_id = ?", project.ID).
-			Update("is_active", false)
+	// A smaller budget or estimate may cross thresholds without new activities
+	checkBudgetThresholds(&project)
 
-		// Create new assignments
+	// Update assignments if provided: members left out are unassigned, new ones join as contributors
+	if len(validatedUserIDs) > 0 {
 		currentUserID := c.MustGet("user_id").(uint)
-		for _, assignedUserID := range validatedUserIDs {
-			// Check if assignment already exists
-			var existingAssignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ?", project.ID, assignedUserID).
-				First(&existingAssignment).Error
-
-			if err == nil {
-				// Reactivate existing assignment
-				existingAssignment.IsActive = true
-				existingAssignment.AssignedBy = currentUserID
-				config.DB.Save(&existingAssignment)
-			} else {
-				// Create new assignment
-				assignment := models.ProjectAssignment{
-					ProjectID:  project.ID,
-					UserID:     assignedUserID,
-					AssignedBy: currentUserID,
-					IsActive:   true,
-					CanModify:  true,
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			var currentIDs []uint
+			tx.Model(&models.ProjectAssignment{}).Where("project_id = ? AND is_active = ?", project.ID, true).Pluck("user_id", &currentIDs)
+			kept := make(map[uint]bool, len(validatedUserIDs))
+			for _, assignedUserID := range validatedUserIDs {
+				kept[assignedUserID] = true
+			}
+			for _, memberID := range currentIDs {
+				if !kept[memberID] {
+					if err := unassignProjectFrom(tx, project.ID, memberID, currentUserID); err != nil {
+						return err
+					}
 				}
-				config.DB.Create(&assignment)
 			}
-		}
-
-		// Update project status
-		if len(validatedUserIDs) > 0 {
-			project.Status = models.ProjectStatusAssigned
-			config.DB.Save(&project)
+			for _, assignedUserID := range validatedUserIDs {
+				if err := assignProjectTo(tx, project.ID, assignedUserID, currentUserID, models.MemberRoleContributor); err != nil {
+					return err
+				}
+			}
+			if project.Status == models.ProjectStatusUnassigned {
+				return tx.Model(&project).Update("status", models.ProjectStatusAssigned).Error
+			}
+			return nil
+		})
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to update project assignments")
+			return
 		}
 	}
 
 	// Reload to get relations including assigned users
-	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").First(&project, project.ID)
+	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("ProjectAssignments.User").Preload("Area").Preload("Client").First(&project, project.ID)
 
 	utils.SuccessResponse(c, 200, "Project updated successfully", project)
 }
@@ -494,8 +488,6 @@ func UpdateProject(c *gin.Context) {
 // @Router /projects/{id} [delete]
 func DeleteProject(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var project models.Project
 	if err := config.DB.First(&project, id).Error; err != nil {
@@ -503,25 +495,12 @@ func DeleteProject(c *gin.Context) {
 		return
 	}
 
-	// Check permissions: only admin/superadmin can delete
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		utils.ErrorResponse(c, 403, "Only admins can delete projects")
+	// Check permissions: only project managers of the area can delete
+	if !policy.FromContext(c).CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "You don't have permission to delete this project")
 		return
 	}
 
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if project.AreaID == nil || *project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Can only delete projects in your area")
-			return
-		}
-	}
-
 	if err := config.DB.Delete(&project).Error; err != nil {
 		utils.ErrorResponse(c, 500, "Failed to delete project")
 		return
@@ -547,9 +526,6 @@ func DeleteProject(c *gin.Context) {
 // @Router /projects/{id}/status [patch]
 func UpdateProjectStatus(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var req models.UpdateProjectStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -563,33 +539,8 @@ func UpdateProjectStatus(c *gin.Context) {
 		return
 	}
 
-	role := userRole.(models.Role)
-
-	// Check permissions
-	canUpdate := false
-	if role == models.RoleSuperAdmin {
-		canUpdate = true
-	} else if role == models.RoleAdmin {
-		// Admin can update area projects in their area
-		if project.ProjectType == models.ProjectTypeArea {
-			if areaID, ok := userAreaID.(*uint); ok && areaID != nil && project.AreaID != nil && *project.AreaID == *areaID {
-				canUpdate = true
-			}
-		}
-	} else if role == models.RoleUser {
-		// User can update their own personal projects or projects assigned to them
-		if project.CreatedBy == userID.(uint) && project.ProjectType == models.ProjectTypePersonal {
-			canUpdate = true
-		} else {
-			var assignment models.ProjectAssignment
-			err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, userID.(uint), true).First(&assignment).Error
-			if err == nil {
-				canUpdate = true
-			}
-		}
-	}
-
-	if !canUpdate {
+	// Check permissions: project managers and owners, the owner of a personal project or members other than viewers
+	if !policy.FromContext(c).CanUpdateProjectStatus(&project) {
 		utils.ErrorResponse(c, 403, "You don't have permission to update this project's status")
 		return
 	}
@@ -603,7 +554,77 @@ func UpdateProjectStatus(c *gin.Context) {
 	}
 
 	// Reload to get relations
-	config.DB.Preload("Creator").Preload("AssignedUser").Preload("Area").First(&project, project.ID)
+	config.DB.Preload("Creator").Preload("AssignedUsers").Preload("Area").First(&project, project.ID)
 
 	utils.SuccessResponse(c, 200, "Project status updated successfully", project)
 }
+
+// UpdateProjectMember godoc
+// @Summary Change project member role
+// @Description Change the role of an active member of a project: owner (manages the project and its members), manager (creates and assigns tasks),
+// @Description contributor (works on tasks and logs time) or viewer (read only, cannot log time)
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param userId path int true "User ID"
+// @Param member body UpdateProjectMemberRequest true "Member role"
+// @Success 200 {object} utils.Response{data=models.ProjectAssignment}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/members/{userId} [patch]
+func UpdateProjectMember(c *gin.Context) {
+	var req models.UpdateProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "You don't have permission to manage this project's members")
+		return
+	}
+
+	var assignment models.ProjectAssignment
+	if err := config.DB.Where("project_id = ? AND user_id = ? AND is_active = ?", project.ID, c.Param("userId"), true).
+		First(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project member not found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&assignment).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AssignmentEvent{
+			ProjectID: &project.ID,
+			UserID:    assignment.UserID,
+			Action:    models.AssignmentActionRoleChanged,
+			Role:      req.Role,
+			ActorID:   policy.FromContext(c).UserID,
+		}).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update project member")
+		return
+	}
+
+	config.DB.Preload("User").First(&assignment, assignment.ID)
+
+	utils.SuccessResponse(c, 200, "Project member updated successfully", assignment)
+}
+
+// nonZero returns nil for 0, which clears an optional amount
+func nonZero(v float64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
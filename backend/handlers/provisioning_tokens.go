@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/identity"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetProvisioningTokens godoc
+// @Summary Get provisioning tokens
+// @Description Get the tokens that identity systems use to call the SCIM endpoints
+// @Tags provisioning
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.ProvisioningToken}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /provisioning-tokens [get]
+func GetProvisioningTokens(c *gin.Context) {
+	var tokens []models.ProvisioningToken
+	if err := config.DB.Order("created_at DESC").Find(&tokens).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve provisioning tokens")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Provisioning tokens retrieved successfully", tokens)
+}
+
+// CreateProvisioningToken godoc
+// @Summary Create provisioning token
+// @Description Create a SCIM provisioning token. The token is only returned once.
+// @Description Users provisioned with it sign in through auth_provider.
+// @Tags provisioning
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body CreateProvisioningTokenRequest true "Token data"
+// @Success 201 {object} utils.Response{data=models.ProvisioningTokenResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /provisioning-tokens [post]
+func CreateProvisioningToken(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateProvisioningTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	if req.AuthProvider == "" {
+		req.AuthProvider = identity.MicrosoftProviderName
+	}
+	if _, err := identity.Get(req.AuthProvider); err != nil {
+		utils.ErrorResponse(c, 400, "Unknown identity provider: "+req.AuthProvider)
+		return
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate provisioning token")
+		return
+	}
+
+	provisioningToken := models.ProvisioningToken{
+		Name:         req.Name,
+		TokenHash:    utils.HashToken(token),
+		AuthProvider: req.AuthProvider,
+		CreatedBy:    userID.(uint),
+	}
+	if err := config.DB.Create(&provisioningToken).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create provisioning token")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Provisioning token created successfully", models.ProvisioningTokenResponse{
+		ProvisioningToken: provisioningToken,
+		Token:             token,
+		SCIMBaseURL:       scimBaseURL(c),
+	})
+}
+
+// RevokeProvisioningToken godoc
+// @Summary Revoke provisioning token
+// @Description Revoke a SCIM provisioning token. Requests using it are rejected from then on.
+// @Tags provisioning
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /provisioning-tokens/{id} [delete]
+func RevokeProvisioningToken(c *gin.Context) {
+	var token models.ProvisioningToken
+	if err := config.DB.First(&token, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Provisioning token not found")
+		return
+	}
+
+	if token.RevokedAt != nil {
+		utils.ErrorResponse(c, 400, "Provisioning token is already revoked")
+		return
+	}
+
+	if err := config.DB.Model(&token).Update("revoked_at", time.Now()).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke provisioning token")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Provisioning token revoked successfully", nil)
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetRates godoc
+// @Summary Get hourly rates
+// @Description Get the cost and bill rates of users and projects in the areas where the caller holds rates.manage (role rates are visible to everyone holding it)
+// @Tags rates
+// @Produce json
+// @Security BearerAuth
+// @Param scope query string false "Filter by scope (role, user, project)"
+// @Param user_id query int false "Filter by user ID"
+// @Param project_id query int false "Filter by project ID"
+// @Param date query string false "Only rates in effect on this date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response{data=[]models.HourlyRate}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /rates [get]
+func GetRates(c *gin.Context) {
+	subject := policy.FromContext(c)
+	query := config.DB.Preload("User").Preload("Project")
+
+	global, areaIDs := subject.AreaScope(models.PermRatesManage)
+	if !global {
+		members := config.DB.Model(&models.User{}).Select("id").
+			Where("area_id IN ? OR id IN (SELECT user_id FROM user_areas WHERE area_id IN ?)", models.NonEmptyIDs(areaIDs), models.NonEmptyIDs(areaIDs))
+		projects := config.DB.Model(&models.Project{}).Select("id").Where("area_id IN ?", models.NonEmptyIDs(areaIDs))
+		query = query.Where("scope = ? OR (scope = ? AND user_id IN (?)) OR (scope = ? AND project_id IN (?))",
+			models.RateScopeRole, models.RateScopeUser, members, models.RateScopeProject, projects)
+	}
+
+	if scope := c.Query("scope"); scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if userID, err := strconv.ParseUint(userIDStr, 10, 32); err == nil {
+			query = query.Where("user_id = ?", uint(userID))
+		}
+	}
+	if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+		if projectID, err := strconv.ParseUint(projectIDStr, 10, 32); err == nil {
+			query = query.Where("project_id = ?", uint(projectID))
+		}
+	}
+	if date := c.Query("date"); date != "" {
+		if parsedDate, err := time.Parse("2006-01-02", date); err == nil {
+			query = query.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", parsedDate, parsedDate)
+		}
+	}
+
+	var rates []models.HourlyRate
+	if err := query.Order("scope ASC, effective_from DESC").Find(&rates).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve rates")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Rates retrieved successfully", rates)
+}
+
+// CreateRate godoc
+// @Summary Create hourly rate
+// @Description Create an effective-dated cost and/or bill rate for a role, a user or a project (optionally for one user on it).
+// @Description The most specific rate wins: project and user, project, user, role. Activities keep the rates in effect when they were logged.
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rate body CreateRateRequest true "Rate data"
+// @Success 201 {object} utils.Response{data=models.HourlyRate}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /rates [post]
+func CreateRate(c *gin.Context) {
+	var req models.CreateRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	rate := models.HourlyRate{
+		Scope:     req.Scope,
+		CostRate:  req.CostRate,
+		BillRate:  req.BillRate,
+		CreatedBy: c.MustGet("user_id").(uint),
+	}
+	switch req.Scope {
+	case models.RateScopeRole:
+		if req.Role == nil || (*req.Role != models.RoleUser && *req.Role != models.RoleAdmin && *req.Role != models.RoleSuperAdmin) {
+			utils.ErrorResponse(c, 400, "role rates need a valid role")
+			return
+		}
+		rate.Role = req.Role
+	case models.RateScopeUser:
+		if req.UserID == nil {
+			utils.ErrorResponse(c, 400, "user rates need a user_id")
+			return
+		}
+		rate.UserID = req.UserID
+	case models.RateScopeProject:
+		if req.ProjectID == nil {
+			utils.ErrorResponse(c, 400, "project rates need a project_id")
+			return
+		}
+		rate.ProjectID = req.ProjectID
+		rate.UserID = req.UserID
+	}
+	if rate.CostRate == nil && rate.BillRate == nil {
+		utils.ErrorResponse(c, 400, "Set cost_rate, bill_rate or both")
+		return
+	}
+
+	if msg := setRatePeriod(&rate, &req.EffectiveFrom, req.EffectiveTo); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	if status, msg := checkRateAccess(policy.FromContext(c), &rate); status != 0 {
+		utils.ErrorResponse(c, status, msg)
+		return
+	}
+	if rateOverlaps(&rate) {
+		utils.ErrorResponse(c, 400, "Another rate for the same target is in effect during this period")
+		return
+	}
+
+	if err := config.DB.Create(&rate).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create rate")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Rate created successfully", rate)
+}
+
+// UpdateRate godoc
+// @Summary Update hourly rate
+// @Description Update the amounts or the period of a rate. Activities already logged keep the rates they were logged with.
+// @Tags rates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rate ID"
+// @Param rate body UpdateRateRequest true "Rate data"
+// @Success 200 {object} utils.Response{data=models.HourlyRate}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /rates/{id} [put]
+func UpdateRate(c *gin.Context) {
+	var req models.UpdateRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var rate models.HourlyRate
+	if err := config.DB.First(&rate, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Rate not found")
+		return
+	}
+	if status, msg := checkRateAccess(policy.FromContext(c), &rate); status != 0 {
+		utils.ErrorResponse(c, status, msg)
+		return
+	}
+
+	if req.CostRate != nil {
+		rate.CostRate = req.CostRate
+	}
+	if req.BillRate != nil {
+		rate.BillRate = req.BillRate
+	}
+	if req.EffectiveFrom != nil || req.EffectiveTo != nil {
+		effectiveTo := req.EffectiveTo
+		if effectiveTo == nil && rate.EffectiveTo != nil {
+			current := rate.EffectiveTo.Format("2006-01-02")
+			effectiveTo = &current
+		}
+		if msg := setRatePeriod(&rate, req.EffectiveFrom, effectiveTo); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
+		}
+		if rateOverlaps(&rate) {
+			utils.ErrorResponse(c, 400, "Another rate for the same target is in effect during this period")
+			return
+		}
+	}
+
+	if err := config.DB.Save(&rate).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update rate")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Rate updated successfully", rate)
+}
+
+// DeleteRate godoc
+// @Summary Delete hourly rate
+// @Description Delete a rate. Activities already logged keep the rates they were logged with.
+// @Tags rates
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rate ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /rates/{id} [delete]
+func DeleteRate(c *gin.Context) {
+	var rate models.HourlyRate
+	if err := config.DB.First(&rate, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Rate not found")
+		return
+	}
+	if status, msg := checkRateAccess(policy.FromContext(c), &rate); status != 0 {
+		utils.ErrorResponse(c, status, msg)
+		return
+	}
+
+	if err := config.DB.Delete(&rate).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete rate")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Rate deleted successfully", nil)
+}
+
+// checkRateAccess checks the rate's target exists and is managed by the subject.
+// Returns 0 when allowed, otherwise the HTTP status and message.
+func checkRateAccess(subject *policy.Subject, rate *models.HourlyRate) (int, string) {
+	switch rate.Scope {
+	case models.RateScopeProject:
+		var project models.Project
+		if err := config.DB.First(&project, *rate.ProjectID).Error; err != nil {
+			return 404, "Project not found"
+		}
+		if !subject.Can(models.PermRatesManage, project.AreaID) {
+			return 403, "You can only set rates of projects in areas you manage"
+		}
+		if rate.UserID != nil {
+			var count int64
+			config.DB.Model(&models.User{}).Where("id = ?", *rate.UserID).Count(&count)
+			if count == 0 {
+				return 404, "User not found"
+			}
+		}
+	case models.RateScopeUser:
+		var user models.User
+		if err := config.DB.First(&user, *rate.UserID).Error; err != nil {
+			return 404, "User not found"
+		}
+		if subject.Can(models.PermRatesManage, user.AreaID) {
+			return 0, ""
+		}
+		for _, areaID := range models.UserAreaIDs(config.DB, user.ID) {
+			if subject.Can(models.PermRatesManage, &areaID) {
+				return 0, ""
+			}
+		}
+		return 403, "You can only set rates of users in areas you manage"
+	default:
+		if !subject.IsGlobal(models.PermRatesManage) {
+			return 403, "Only global rate managers can set role rates"
+		}
+	}
+	return 0, ""
+}
+
+// setRatePeriod parses and applies the effective dates, returning an error message or ""
+func setRatePeriod(rate *models.HourlyRate, from, to *string) string {
+	if from != nil {
+		parsed, err := time.Parse("2006-01-02", *from)
+		if err != nil {
+			return "Invalid effective_from format. Use YYYY-MM-DD"
+		}
+		rate.EffectiveFrom = parsed
+	}
+	rate.EffectiveTo = nil
+	if to != nil && *to != "" {
+		parsed, err := time.Parse("2006-01-02", *to)
+		if err != nil {
+			return "Invalid effective_to format. Use YYYY-MM-DD"
+		}
+		if parsed.Before(rate.EffectiveFrom) {
+			return "effective_to cannot be before effective_from"
+		}
+		rate.EffectiveTo = &parsed
+	}
+	return ""
+}
+
+// rateOverlaps checks if another rate for the same target is in effect during part of the rate's period
+func rateOverlaps(rate *models.HourlyRate) bool {
+	query := config.DB.Model(&models.HourlyRate{}).Where("scope = ? AND id <> ?", rate.Scope, rate.ID)
+	query = whereRateTarget(query, "role", rate.Role)
+	query = whereRateTarget(query, "user_id", rate.UserID)
+	query = whereRateTarget(query, "project_id", rate.ProjectID)
+
+	query = query.Where("effective_to IS NULL OR effective_to >= ?", rate.EffectiveFrom)
+	if rate.EffectiveTo != nil {
+		query = query.Where("effective_from <= ?", *rate.EffectiveTo)
+	}
+
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+func whereRateTarget[T any](query *gorm.DB, column string, value *T) *gorm.DB {
+	if value == nil {
+		return query.Where(column + " IS NULL")
+	}
+	return query.Where(column+" = ?", *value)
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetPermissions godoc
+// @Summary Get permission catalog
+// @Description Get every permission that can be granted to a custom role
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.PermissionInfo}
+// @Failure 401 {object} utils.Response
+// @Router /permissions [get]
+func GetPermissions(c *gin.Context) {
+	utils.SuccessResponse(c, 200, "Permissions retrieved successfully", models.PermissionCatalog)
+}
+
+// GetRoles godoc
+// @Summary Get custom roles
+// @Description Get all custom roles with their permissions
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.RoleDefinition}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /roles [get]
+func GetRoles(c *gin.Context) {
+	var roles []models.RoleDefinition
+	if err := config.DB.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve roles")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Roles retrieved successfully", roles)
+}
+
+// CreateRole godoc
+// @Summary Create custom role
+// @Description Create a custom role made of permissions from the catalog
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role body CreateRoleRequest true "Role data"
+// @Success 201 {object} utils.Response{data=models.RoleDefinition}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /roles [post]
+func CreateRole(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	permissions, ok := rolePermissions(c, req.Permissions)
+	if !ok {
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.RoleDefinition{}).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "Role name already exists")
+		return
+	}
+
+	role := models.RoleDefinition{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID.(uint),
+		Permissions: permissions,
+	}
+
+	if err := config.DB.Create(&role).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create role")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Role created successfully", role)
+}
+
+// UpdateRole godoc
+// @Summary Update custom role
+// @Description Update a custom role. When permissions are sent they replace the current set.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Param role body UpdateRoleRequest true "Role data"
+// @Success 200 {object} utils.Response{data=models.RoleDefinition}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /roles/{id} [put]
+func UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var role models.RoleDefinition
+	if err := config.DB.First(&role, id).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Role not found")
+		return
+	}
+
+	if req.Name != "" && req.Name != role.Name {
+		var count int64
+		config.DB.Model(&models.RoleDefinition{}).Where("name = ? AND id <> ?", req.Name, role.ID).Count(&count)
+		if count > 0 {
+			utils.ErrorResponse(c, 400, "Role name already exists")
+			return
+		}
+		role.Name = req.Name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	var permissions []models.RolePermission
+	if req.Permissions != nil {
+		var ok bool
+		if permissions, ok = rolePermissions(c, req.Permissions); !ok {
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(&role).Error; err != nil {
+			return err
+		}
+		if req.Permissions == nil {
+			return nil
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for i := range permissions {
+			permissions[i].RoleID = role.ID
+		}
+		return tx.Create(&permissions).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update role")
+		return
+	}
+
+	config.DB.Preload("Permissions").First(&role, role.ID)
+
+	utils.SuccessResponse(c, 200, "Role updated successfully", role)
+}
+
+// DeleteRole godoc
+// @Summary Delete custom role
+// @Description Soft delete a custom role and remove it from every user it was assigned to
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /roles/{id} [delete]
+func DeleteRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var role models.RoleDefinition
+	if err := config.DB.First(&role, id).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Role not found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RoleAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete role")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Role deleted successfully", nil)
+}
+
+// GetUserRoles godoc
+// @Summary Get user role assignments
+// @Description Get the custom roles assigned to a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=[]models.RoleAssignment}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/roles [get]
+func GetUserRoles(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermRolesAssign, user.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var assignments []models.RoleAssignment
+	if err := config.DB.Preload("Role.Permissions").Preload("Area").
+		Where("user_id = ?", user.ID).
+		Order("created_at ASC").
+		Find(&assignments).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve role assignments")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Role assignments retrieved successfully", assignments)
+}
+
+// AssignRole godoc
+// @Summary Assign role to user
+// @Description Assign a custom role to a user for one area, or for every area when area_id is omitted.
+// @Description The caller must hold roles.assign and every permission of the role in that scope.
+// @Description Roles with areas.manage, roles.manage or users.approve can only be assigned for every area.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param assignment body AssignRoleRequest true "Role and scope"
+// @Success 201 {object} utils.Response{data=models.RoleAssignment}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/roles [post]
+func AssignRole(c *gin.Context) {
+	grantedBy, _ := c.Get("user_id")
+
+	var req models.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	var role models.RoleDefinition
+	if err := config.DB.Preload("Permissions").First(&role, req.RoleID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Role not found")
+		return
+	}
+
+	if req.AreaID != nil {
+		var area models.Area
+		if err := config.DB.First(&area, *req.AreaID).Error; err != nil {
+			utils.ErrorResponse(c, 404, "Area not found")
+			return
+		}
+	}
+
+	// Permissions over areas, roles and approvals would act everywhere, so they are only granted globally
+	if req.AreaID != nil {
+		for _, p := range role.PermissionNames() {
+			if p.IsGlobalOnly() {
+				utils.ErrorResponse(c, 400, "Roles with "+string(p)+" can only be assigned for every area")
+				return
+			}
+		}
+	}
+
+	// Nobody can hand out more than they hold in the target scope
+	subject := policy.FromContext(c)
+	if !subject.Can(models.PermRolesAssign, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You cannot assign roles in this scope")
+		return
+	}
+	if !subject.CanAll(role.PermissionNames(), req.AreaID) {
+		utils.ErrorResponse(c, 403, "You cannot assign a role with permissions you don't hold")
+		return
+	}
+
+	query := config.DB.Model(&models.RoleAssignment{}).Where("user_id = ? AND role_id = ?", user.ID, role.ID)
+	if req.AreaID == nil {
+		query = query.Where("area_id IS NULL")
+	} else {
+		query = query.Where("area_id = ?", *req.AreaID)
+	}
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		utils.ErrorResponse(c, 400, "Role is already assigned to this user in this scope")
+		return
+	}
+
+	assignment := models.RoleAssignment{
+		UserID:    user.ID,
+		RoleID:    role.ID,
+		AreaID:    req.AreaID,
+		GrantedBy: grantedBy.(uint),
+	}
+
+	if err := config.DB.Create(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to assign role")
+		return
+	}
+
+	config.DB.Preload("Role.Permissions").Preload("Area").First(&assignment, assignment.ID)
+
+	utils.SuccessResponse(c, 201, "Role assigned successfully", assignment)
+}
+
+// RevokeRole godoc
+// @Summary Revoke role from user
+// @Description Remove a custom role assignment from a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param assignmentId path int true "Role assignment ID"
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/roles/{assignmentId} [delete]
+func RevokeRole(c *gin.Context) {
+	var assignment models.RoleAssignment
+	if err := config.DB.Where("user_id = ?", c.Param("id")).First(&assignment, c.Param("assignmentId")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Role assignment not found")
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermRolesAssign, assignment.AreaID) {
+		utils.ErrorResponse(c, 403, "You cannot revoke roles in this scope")
+		return
+	}
+
+	if err := config.DB.Delete(&assignment).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to revoke role")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Role revoked successfully", nil)
+}
+
+// rolePermissions validates requested permission names against the catalog and removes duplicates
+func rolePermissions(c *gin.Context, names []models.Permission) ([]models.RolePermission, bool) {
+	seen := make(map[models.Permission]bool)
+	permissions := make([]models.RolePermission, 0, len(names))
+	for _, name := range names {
+		if !models.IsValidPermission(name) {
+			utils.ErrorResponse(c, 400, "Unknown permission: "+string(name))
+			return nil, false
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		permissions = append(permissions, models.RolePermission{Permission: name})
+	}
+	return permissions, true
+}
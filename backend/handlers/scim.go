@@ -0,0 +1,580 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// scimFilterPattern matches the equality filters identity systems use to look resources up
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*(\S+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// errSCIMSuperAdmin is returned when SCIM tries to change a SuperAdmin
+var errSCIMSuperAdmin = errors.New("SuperAdmin accounts are not managed through SCIM")
+
+// SCIMServiceProviderConfig godoc
+// @Summary SCIM service provider configuration
+// @Description Describe the SCIM features supported by TimeFlow
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Success 200 {object} map[string]interface{}
+// @Router /scim/v2/ServiceProviderConfig [get]
+func SCIMServiceProviderConfig(c *gin.Context) {
+	utils.SCIMResponse(c, 200, gin.H{
+		"schemas":        []string{"urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": 1000},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Provisioning token",
+			"description": "Bearer token created by a SuperAdmin in /api/v1/provisioning-tokens",
+			"primary":     true,
+		}},
+	})
+}
+
+// SCIMResourceTypes godoc
+// @Summary SCIM resource types
+// @Description List the SCIM resource types: users and groups (mapped to areas)
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Success 200 {object} models.SCIMListResponse
+// @Router /scim/v2/ResourceTypes [get]
+func SCIMResourceTypes(c *gin.Context) {
+	resources := []interface{}{
+		gin.H{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   utils.SCIMSchemaUser,
+		},
+		gin.H{
+			"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:ResourceType"},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   utils.SCIMSchemaGroup,
+		},
+	}
+	utils.SCIMResponse(c, 200, models.SCIMListResponse{
+		Schemas:      []string{utils.SCIMSchemaListResponse},
+		TotalResults: int64(len(resources)),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// SCIMGetUsers godoc
+// @Summary List SCIM users
+// @Description List users, optionally filtered with `userName eq "..."`, `externalId eq "..."` or `emails.value eq "..."`
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Param filter query string false "SCIM equality filter"
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results (default 100)"
+// @Success 200 {object} models.SCIMListResponse
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Router /scim/v2/Users [get]
+func SCIMGetUsers(c *gin.Context) {
+	query := config.DB.Model(&models.User{})
+
+	if filter := c.Query("filter"); filter != "" {
+		attribute, value, ok := parseSCIMFilter(filter)
+		if !ok {
+			utils.SCIMErrorResponse(c, 400, "invalidFilter", "Only 'attribute eq \"value\"' filters are supported")
+			return
+		}
+		switch attribute {
+		case "username", "emails.value", "emails":
+			query = query.Where("LOWER(email) = ?", strings.ToLower(value))
+		case "externalid":
+			query = query.Where("external_id = ?", value)
+		case "id":
+			query = query.Where("id = ?", scimID(value))
+		default:
+			utils.SCIMErrorResponse(c, 400, "invalidFilter", "Unsupported filter attribute: "+attribute)
+			return
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	startIndex, count := scimPagination(c)
+	var users []models.User
+	if err := query.Order("id ASC").Offset(startIndex - 1).Limit(count).Find(&users).Error; err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to retrieve users")
+		return
+	}
+
+	userIDs := make([]uint, len(users))
+	for i := range users {
+		userIDs[i] = users[i].ID
+	}
+	groups := scimUserGroups(userIDs)
+
+	resources := make([]interface{}, len(users))
+	for i := range users {
+		resources[i] = toSCIMUser(c, &users[i], groups[users[i].ID])
+	}
+
+	utils.SCIMResponse(c, 200, models.SCIMListResponse{
+		Schemas:      []string{utils.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// SCIMGetUser godoc
+// @Summary Get SCIM user
+// @Description Get a user as a SCIM resource
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "User ID"
+// @Success 200 {object} models.SCIMUser
+// @Failure 401 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Users/{id} [get]
+func SCIMGetUser(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "User not found")
+		return
+	}
+
+	utils.SCIMResponse(c, 200, toSCIMUser(c, &user, scimUserGroups([]uint{user.ID})[user.ID]))
+}
+
+// SCIMCreateUser godoc
+// @Summary Provision SCIM user
+// @Description Create an approved user that signs in through the provisioning token's identity provider
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param user body models.SCIMUser true "SCIM user"
+// @Success 201 {object} models.SCIMUser
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 409 {object} utils.SCIMError
+// @Router /scim/v2/Users [post]
+func SCIMCreateUser(c *gin.Context) {
+	authProvider, _ := c.Get("scim_auth_provider")
+
+	var resource models.SCIMUser
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	user := models.User{
+		Role:           models.RoleUser,
+		AuthProvider:   authProvider.(string),
+		IsActive:       true,
+		ApprovalStatus: models.ApprovalStatusApproved,
+	}
+	if !applySCIMUser(c, &user, &resource) {
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.User{}).Where("LOWER(email) = ?", user.Email).Count(&count)
+	if count > 0 {
+		utils.SCIMErrorResponse(c, 409, "uniqueness", "A user with this userName already exists")
+		return
+	}
+
+	if err := config.DB.Create(&user).Error; err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to create user")
+		return
+	}
+
+	utils.SCIMResponse(c, 201, toSCIMUser(c, &user, nil))
+}
+
+// SCIMReplaceUser godoc
+// @Summary Replace SCIM user
+// @Description Replace a user's attributes. Setting active to false deactivates the account.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "User ID"
+// @Param user body models.SCIMUser true "SCIM user"
+// @Success 200 {object} models.SCIMUser
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 403 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Failure 409 {object} utils.SCIMError
+// @Router /scim/v2/Users/{id} [put]
+func SCIMReplaceUser(c *gin.Context) {
+	user, ok := loadSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	var resource models.SCIMUser
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+	if resource.Active == nil {
+		active := true // Omitted attributes take their default on replace
+		resource.Active = &active
+	}
+	if !applySCIMUser(c, user, &resource) {
+		return
+	}
+
+	saveSCIMUser(c, user)
+}
+
+// SCIMPatchUser godoc
+// @Summary Patch SCIM user
+// @Description Apply SCIM PATCH operations to a user (userName, displayName, name, emails, externalId, active)
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "User ID"
+// @Param patch body models.SCIMPatchRequest true "Patch operations"
+// @Success 200 {object} models.SCIMUser
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 403 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Users/{id} [patch]
+func SCIMPatchUser(c *gin.Context) {
+	user, ok := loadSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	// Start from the current state so operations only change what they target
+	active := user.IsActive
+	resource := models.SCIMUser{
+		UserName:    user.Email,
+		DisplayName: user.FullName,
+		Active:      &active,
+		Name:        &models.SCIMName{},
+	}
+	if user.ExternalID != nil {
+		resource.ExternalID = *user.ExternalID
+	}
+
+	for _, op := range req.Operations {
+		if err := patchSCIMUser(&resource, op); err != nil {
+			utils.SCIMErrorResponse(c, 400, "invalidValue", err.Error())
+			return
+		}
+	}
+	if resource.Name.GivenName != "" && resource.Name.FamilyName != "" && resource.DisplayName == user.FullName && resource.Name.Formatted == "" {
+		resource.Name.Formatted = resource.Name.GivenName + " " + resource.Name.FamilyName
+	}
+
+	if !applySCIMUser(c, user, &resource) {
+		return
+	}
+
+	saveSCIMUser(c, user)
+}
+
+// SCIMDeleteUser godoc
+// @Summary Deprovision SCIM user
+// @Description Deactivate a user. The account is kept so past activities stay intact.
+// @Tags scim
+// @Security ProvisioningToken
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 401 {object} utils.SCIMError
+// @Failure 403 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Users/{id} [delete]
+func SCIMDeleteUser(c *gin.Context) {
+	user, ok := loadSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	user.SetActive(false, models.DeactivatedBySCIM)
+	if err := config.DB.Model(user).Updates(user.ActivationUpdates()).Error; err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to deactivate user")
+		return
+	}
+
+	c.Status(204)
+}
+
+// loadSCIMUser loads the user of the :id parameter, refusing SuperAdmins
+func loadSCIMUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := config.DB.First(&user, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "User not found")
+		return nil, false
+	}
+	if user.Role == models.RoleSuperAdmin {
+		utils.SCIMErrorResponse(c, 403, "", errSCIMSuperAdmin.Error())
+		return nil, false
+	}
+	return &user, true
+}
+
+// saveSCIMUser stores the attributes SCIM manages and replies with the updated resource
+func saveSCIMUser(c *gin.Context, user *models.User) {
+	var count int64
+	config.DB.Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", user.Email, user.ID).Count(&count)
+	if count > 0 {
+		utils.SCIMErrorResponse(c, 409, "uniqueness", "A user with this userName already exists")
+		return
+	}
+
+	updates := user.ActivationUpdates()
+	updates["email"] = user.Email
+	updates["full_name"] = user.FullName
+	updates["external_id"] = user.ExternalID
+	if err := config.DB.Model(user).Updates(updates).Error; err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to update user")
+		return
+	}
+
+	utils.SCIMResponse(c, 200, toSCIMUser(c, user, scimUserGroups([]uint{user.ID})[user.ID]))
+}
+
+// applySCIMUser copies the SCIM attributes TimeFlow stores onto the user
+func applySCIMUser(c *gin.Context, user *models.User, resource *models.SCIMUser) bool {
+	email := resource.UserName
+	for _, e := range resource.Emails {
+		if e.Primary || email == "" {
+			email = e.Value
+		}
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", "userName or a primary email address is required")
+		return false
+	}
+
+	fullName := resource.DisplayName
+	if resource.Name != nil {
+		if resource.Name.Formatted != "" {
+			fullName = resource.Name.Formatted
+		} else if fullName == "" {
+			fullName = strings.TrimSpace(resource.Name.GivenName + " " + resource.Name.FamilyName)
+		}
+	}
+	if fullName == "" {
+		fullName = email
+	}
+
+	user.Email = email
+	user.FullName = fullName
+	if resource.ExternalID != "" {
+		user.ExternalID = &resource.ExternalID
+	}
+	if resource.Active != nil {
+		user.SetActive(*resource.Active, models.DeactivatedBySCIM)
+	}
+	return true
+}
+
+// patchSCIMUser applies one PATCH operation to the working copy of a user
+func patchSCIMUser(resource *models.SCIMUser, op models.SCIMPatchOperation) error {
+	operation := strings.ToLower(op.Op)
+	if operation != "add" && operation != "replace" && operation != "remove" {
+		return errors.New("unsupported operation: " + op.Op)
+	}
+
+	// Without a path the value holds several attributes
+	if op.Path == "" {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return errors.New("value must be an object when path is omitted")
+		}
+		for path, value := range attributes {
+			if err := patchSCIMUser(resource, models.SCIMPatchOperation{Op: op.Op, Path: path, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path := strings.ToLower(op.Path)
+	if operation == "remove" {
+		// Only optional attributes can be removed; required ones are kept
+		if path == "externalid" {
+			resource.ExternalID = ""
+		}
+		return nil
+	}
+
+	switch {
+	case path == "active":
+		active, err := scimBool(op.Value)
+		if err != nil {
+			return err
+		}
+		resource.Active = &active
+	case path == "username":
+		return json.Unmarshal(op.Value, &resource.UserName)
+	case path == "displayname":
+		return json.Unmarshal(op.Value, &resource.DisplayName)
+	case path == "externalid":
+		return json.Unmarshal(op.Value, &resource.ExternalID)
+	case path == "name":
+		return json.Unmarshal(op.Value, resource.Name)
+	case path == "name.formatted":
+		return json.Unmarshal(op.Value, &resource.Name.Formatted)
+	case path == "name.givenname":
+		return json.Unmarshal(op.Value, &resource.Name.GivenName)
+	case path == "name.familyname":
+		return json.Unmarshal(op.Value, &resource.Name.FamilyName)
+	case path == "emails":
+		return json.Unmarshal(op.Value, &resource.Emails)
+	case strings.HasPrefix(path, "emails[") && strings.HasSuffix(path, "].value"):
+		var email string
+		if err := json.Unmarshal(op.Value, &email); err != nil {
+			return err
+		}
+		resource.Emails = []models.SCIMMultiValue{{Value: email, Primary: true}}
+	}
+	// Attributes TimeFlow does not store (phone numbers, enterprise extension, ...) are ignored
+	return nil
+}
+
+// toSCIMUser converts a user to its SCIM representation
+func toSCIMUser(c *gin.Context, user *models.User, groups []models.SCIMMultiValue) models.SCIMUser {
+	id := strconv.FormatUint(uint64(user.ID), 10)
+	active := user.IsActive
+	resource := models.SCIMUser{
+		Schemas:     []string{utils.SCIMSchemaUser},
+		ID:          id,
+		UserName:    user.Email,
+		Name:        &models.SCIMName{Formatted: user.FullName},
+		DisplayName: user.FullName,
+		Emails:      []models.SCIMMultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      groups,
+		Meta: &models.SCIMMeta{
+			ResourceType: "User",
+			Created:      &user.CreatedAt,
+			LastModified: &user.UpdatedAt,
+			Location:     scimLocation(c, "/Users/"+id),
+		},
+	}
+	if user.ExternalID != nil {
+		resource.ExternalID = *user.ExternalID
+	}
+	return resource
+}
+
+// scimUserGroups returns the SCIM groups of each user, through their area memberships
+func scimUserGroups(userIDs []uint) map[uint][]models.SCIMMultiValue {
+	groups := make(map[uint][]models.SCIMMultiValue)
+	if len(userIDs) == 0 {
+		return groups
+	}
+
+	var rows []struct {
+		UserID      uint
+		GroupID     uint
+		DisplayName string
+	}
+	config.DB.Table("user_areas").
+		Select("user_areas.user_id, scim_groups.id AS group_id, scim_groups.display_name").
+		Joins("JOIN scim_groups ON scim_groups.area_id = user_areas.area_id AND scim_groups.deleted_at IS NULL").
+		Where("user_areas.user_id IN ?", userIDs).
+		Scan(&rows)
+
+	for _, row := range rows {
+		groups[row.UserID] = append(groups[row.UserID], models.SCIMMultiValue{
+			Value:   strconv.FormatUint(uint64(row.GroupID), 10),
+			Display: row.DisplayName,
+		})
+	}
+	return groups
+}
+
+// parseSCIMFilter parses an `attribute eq "value"` filter. The attribute is lowercased.
+func parseSCIMFilter(filter string) (attribute, value string, ok bool) {
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", false
+	}
+	value = strings.ReplaceAll(strings.ReplaceAll(match[2], `\"`, `"`), `\\`, `\`)
+	return strings.ToLower(match[1]), value, true
+}
+
+// scimPagination reads the 1-based startIndex and count parameters
+func scimPagination(c *gin.Context) (startIndex, count int) {
+	startIndex, count = 1, 100
+	if v, err := strconv.Atoi(c.Query("startIndex")); err == nil && v > 1 {
+		startIndex = v
+	}
+	if v, err := strconv.Atoi(c.Query("count")); err == nil && v >= 0 {
+		count = v
+	}
+	if count > 1000 {
+		count = 1000
+	}
+	return startIndex, count
+}
+
+// scimID parses a SCIM resource ID; invalid IDs become 0, which matches nothing
+func scimID(value string) uint64 {
+	id, _ := strconv.ParseUint(value, 10, 64)
+	return id
+}
+
+// scimBool reads a boolean sent either as JSON boolean or as string ("True"/"False")
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, errors.New("active must be a boolean")
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// scimBaseURL returns the absolute URL of the SCIM API as seen by the caller
+func scimBaseURL(c *gin.Context) string {
+	scheme := "https"
+	if c.Request.TLS == nil && c.GetHeader("X-Forwarded-Proto") != "https" {
+		scheme = "http"
+	}
+	return scheme + "://" + c.Request.Host + "/scim/v2"
+}
+
+// scimLocation builds the absolute URL of a SCIM resource
+func scimLocation(c *gin.Context, path string) string {
+	return scimBaseURL(c) + path
+}
@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// scimMemberPathPattern matches PATCH paths that target one member, e.g. members[value eq "12"]
+var scimMemberPathPattern = regexp.MustCompile(`(?i)^members\[value eq "([^"]*)"\]$`)
+
+var errSCIMMemberNotFound = errors.New("member not found")
+
+// SCIMGetGroups godoc
+// @Summary List SCIM groups
+// @Description List provisioned groups (each mapped to an area), optionally filtered with `displayName eq "..."` or `externalId eq "..."`
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Param filter query string false "SCIM equality filter"
+// @Param excludedAttributes query string false "Use 'members' to omit members"
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results (default 100)"
+// @Success 200 {object} models.SCIMListResponse
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Router /scim/v2/Groups [get]
+func SCIMGetGroups(c *gin.Context) {
+	query := config.DB.Model(&models.ScimGroup{})
+
+	if filter := c.Query("filter"); filter != "" {
+		attribute, value, ok := parseSCIMFilter(filter)
+		if !ok {
+			utils.SCIMErrorResponse(c, 400, "invalidFilter", "Only 'attribute eq \"value\"' filters are supported")
+			return
+		}
+		switch attribute {
+		case "displayname":
+			query = query.Where("LOWER(display_name) = ?", strings.ToLower(value))
+		case "externalid":
+			query = query.Where("external_id = ?", value)
+		case "id":
+			query = query.Where("id = ?", scimID(value))
+		default:
+			utils.SCIMErrorResponse(c, 400, "invalidFilter", "Unsupported filter attribute: "+attribute)
+			return
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	startIndex, count := scimPagination(c)
+	var groups []models.ScimGroup
+	if err := query.Order("id ASC").Offset(startIndex - 1).Limit(count).Find(&groups).Error; err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to retrieve groups")
+		return
+	}
+
+	withMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	resources := make([]interface{}, len(groups))
+	for i := range groups {
+		resources[i] = toSCIMGroup(c, &groups[i], withMembers)
+	}
+
+	utils.SCIMResponse(c, 200, models.SCIMListResponse{
+		Schemas:      []string{utils.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// SCIMGetGroup godoc
+// @Summary Get SCIM group
+// @Description Get a provisioned group with the members of its area
+// @Tags scim
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "Group ID"
+// @Success 200 {object} models.SCIMGroup
+// @Failure 401 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Groups/{id} [get]
+func SCIMGetGroup(c *gin.Context) {
+	var group models.ScimGroup
+	if err := config.DB.First(&group, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "Group not found")
+		return
+	}
+
+	withMembers := !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	utils.SCIMResponse(c, 200, toSCIMGroup(c, &group, withMembers))
+}
+
+// SCIMCreateGroup godoc
+// @Summary Provision SCIM group
+// @Description Map a group to the area with the same name, creating the area when none exists, and add its members to the area
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param group body models.SCIMGroup true "SCIM group"
+// @Success 201 {object} models.SCIMGroup
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 409 {object} utils.SCIMError
+// @Router /scim/v2/Groups [post]
+func SCIMCreateGroup(c *gin.Context) {
+	var resource models.SCIMGroup
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	displayName := strings.TrimSpace(resource.DisplayName)
+	if displayName == "" {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", "displayName is required")
+		return
+	}
+
+	memberIDs, ok := scimMemberIDs(c, resource.Members)
+	if !ok {
+		return
+	}
+
+	var area models.Area
+	if err := config.DB.Where("LOWER(name) = ?", strings.ToLower(displayName)).First(&area).Error; err == nil {
+		var count int64
+		config.DB.Model(&models.ScimGroup{}).Where("area_id = ?", area.ID).Count(&count)
+		if count > 0 {
+			utils.SCIMErrorResponse(c, 409, "uniqueness", "A group is already mapped to this area")
+			return
+		}
+	}
+
+	group := models.ScimGroup{
+		ExternalID:  resource.ExternalID,
+		DisplayName: displayName,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if area.ID == 0 {
+			area = models.Area{
+				Name:        displayName,
+				Description: "Provisioned through SCIM",
+				IsActive:    true,
+			}
+			if err := tx.Create(&area).Error; err != nil {
+				return err
+			}
+		}
+		group.AreaID = area.ID
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return addSCIMMembers(tx, area.ID, memberIDs)
+	})
+	if errors.Is(err, errSCIMMemberNotFound) {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", "Member not found")
+		return
+	}
+	if err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to create group")
+		return
+	}
+
+	utils.SCIMResponse(c, 201, toSCIMGroup(c, &group, true))
+}
+
+// SCIMReplaceGroup godoc
+// @Summary Replace SCIM group
+// @Description Replace a group's name and members. Members removed from the group leave the area.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "Group ID"
+// @Param group body models.SCIMGroup true "SCIM group"
+// @Success 200 {object} models.SCIMGroup
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Groups/{id} [put]
+func SCIMReplaceGroup(c *gin.Context) {
+	var group models.ScimGroup
+	if err := config.DB.First(&group, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "Group not found")
+		return
+	}
+
+	var resource models.SCIMGroup
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	memberIDs, ok := scimMemberIDs(c, resource.Members)
+	if !ok {
+		return
+	}
+
+	if name := strings.TrimSpace(resource.DisplayName); name != "" {
+		group.DisplayName = name
+	}
+	group.ExternalID = resource.ExternalID
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Area").Save(&group).Error; err != nil {
+			return err
+		}
+		return replaceSCIMMembers(tx, group.AreaID, memberIDs)
+	})
+	if errors.Is(err, errSCIMMemberNotFound) {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", "Member not found")
+		return
+	}
+	if err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to update group")
+		return
+	}
+
+	utils.SCIMResponse(c, 200, toSCIMGroup(c, &group, true))
+}
+
+// SCIMPatchGroup godoc
+// @Summary Patch SCIM group
+// @Description Apply SCIM PATCH operations to a group: add, remove or replace members, or rename it
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Security ProvisioningToken
+// @Param id path string true "Group ID"
+// @Param patch body models.SCIMPatchRequest true "Patch operations"
+// @Success 200 {object} models.SCIMGroup
+// @Failure 400 {object} utils.SCIMError
+// @Failure 401 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Groups/{id} [patch]
+func SCIMPatchGroup(c *gin.Context) {
+	var group models.ScimGroup
+	if err := config.DB.First(&group, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "Group not found")
+		return
+	}
+
+	var req models.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SCIMErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, op := range req.Operations {
+			if err := patchSCIMGroup(tx, &group, op); err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Area").Save(&group).Error
+	})
+	if errors.Is(err, errSCIMMemberNotFound) {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", "Member not found")
+		return
+	}
+	var invalid *scimInvalidValue
+	if errors.As(err, &invalid) {
+		utils.SCIMErrorResponse(c, 400, "invalidValue", invalid.Error())
+		return
+	}
+	if err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to update group")
+		return
+	}
+
+	utils.SCIMResponse(c, 200, toSCIMGroup(c, &group, true))
+}
+
+// SCIMDeleteGroup godoc
+// @Summary Deprovision SCIM group
+// @Description Remove the group mapping and the area memberships it created. The area itself is kept.
+// @Tags scim
+// @Security ProvisioningToken
+// @Param id path string true "Group ID"
+// @Success 204
+// @Failure 401 {object} utils.SCIMError
+// @Failure 404 {object} utils.SCIMError
+// @Router /scim/v2/Groups/{id} [delete]
+func SCIMDeleteGroup(c *gin.Context) {
+	var group models.ScimGroup
+	if err := config.DB.First(&group, scimID(c.Param("id"))).Error; err != nil {
+		utils.SCIMErrorResponse(c, 404, "", "Group not found")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeSCIMMembers(tx, group.AreaID, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		utils.SCIMErrorResponse(c, 500, "", "Failed to delete group")
+		return
+	}
+
+	c.Status(204)
+}
+
+// scimInvalidValue reports a PATCH operation that cannot be applied
+type scimInvalidValue struct{ detail string }
+
+func (e *scimInvalidValue) Error() string { return e.detail }
+
+// patchSCIMGroup applies one PATCH operation to a group
+func patchSCIMGroup(tx *gorm.DB, group *models.ScimGroup, op models.SCIMPatchOperation) error {
+	operation := strings.ToLower(op.Op)
+	if operation != "add" && operation != "replace" && operation != "remove" {
+		return &scimInvalidValue{"unsupported operation: " + op.Op}
+	}
+
+	// Without a path the value holds several attributes
+	if op.Path == "" {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return &scimInvalidValue{"value must be an object when path is omitted"}
+		}
+		for path, value := range attributes {
+			if err := patchSCIMGroup(tx, group, models.SCIMPatchOperation{Op: op.Op, Path: path, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if match := scimMemberPathPattern.FindStringSubmatch(op.Path); match != nil {
+		if operation != "remove" {
+			return &scimInvalidValue{"only remove is supported on a single member"}
+		}
+		return removeSCIMMembers(tx, group.AreaID, []uint{uint(scimID(match[1]))})
+	}
+
+	switch strings.ToLower(op.Path) {
+	case "displayname":
+		var name string
+		if err := json.Unmarshal(op.Value, &name); err != nil || strings.TrimSpace(name) == "" {
+			return &scimInvalidValue{"displayName must be a non-empty string"}
+		}
+		group.DisplayName = strings.TrimSpace(name)
+	case "externalid":
+		if operation == "remove" {
+			group.ExternalID = ""
+			return nil
+		}
+		if err := json.Unmarshal(op.Value, &group.ExternalID); err != nil {
+			return &scimInvalidValue{"externalId must be a string"}
+		}
+	case "members":
+		var members []models.SCIMMultiValue
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return &scimInvalidValue{"members must be a list"}
+			}
+		}
+		memberIDs := make([]uint, 0, len(members))
+		for _, member := range members {
+			memberIDs = append(memberIDs, uint(scimID(member.Value)))
+		}
+
+		switch operation {
+		case "add":
+			return addSCIMMembers(tx, group.AreaID, memberIDs)
+		case "replace":
+			return replaceSCIMMembers(tx, group.AreaID, memberIDs)
+		case "remove":
+			if len(members) == 0 {
+				return removeSCIMMembers(tx, group.AreaID, nil)
+			}
+			return removeSCIMMembers(tx, group.AreaID, memberIDs)
+		}
+	default:
+		return &scimInvalidValue{"unsupported path: " + op.Path}
+	}
+	return nil
+}
+
+// addSCIMMembers adds users to an area as SCIM-managed members. Existing memberships are kept as they are.
+func addSCIMMembers(tx *gorm.DB, areaID uint, userIDs []uint) error {
+	for _, userID := range userIDs {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return errSCIMMemberNotFound
+		}
+		if models.IsAreaMember(tx, userID, areaID) {
+			continue
+		}
+
+		membership := models.UserArea{
+			UserID: userID,
+			AreaID: areaID,
+			Role:   models.RoleUser,
+			Source: models.MembershipSourceSCIM,
+		}
+		if err := tx.Create(&membership).Error; err != nil {
+			return err
+		}
+		if err := models.RefreshPrimaryArea(tx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeSCIMMembers removes SCIM-managed memberships of an area, for the given users or all when nil.
+// Memberships added by hand are not touched.
+func removeSCIMMembers(tx *gorm.DB, areaID uint, userIDs []uint) error {
+	query := tx.Where("area_id = ? AND source = ?", areaID, models.MembershipSourceSCIM)
+	if userIDs != nil {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+
+	var memberships []models.UserArea
+	if err := query.Find(&memberships).Error; err != nil {
+		return err
+	}
+	for i := range memberships {
+		if err := tx.Delete(&memberships[i]).Error; err != nil {
+			return err
+		}
+		if err := models.RefreshPrimaryArea(tx, memberships[i].UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSCIMMembers makes the SCIM-managed members of an area exactly the given users
+func replaceSCIMMembers(tx *gorm.DB, areaID uint, userIDs []uint) error {
+	var current []uint
+	if err := tx.Model(&models.UserArea{}).
+		Where("area_id = ? AND source = ?", areaID, models.MembershipSourceSCIM).
+		Pluck("user_id", &current).Error; err != nil {
+		return err
+	}
+
+	wanted := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	var removed []uint
+	for _, id := range current {
+		if !wanted[id] {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		if err := removeSCIMMembers(tx, areaID, removed); err != nil {
+			return err
+		}
+	}
+	return addSCIMMembers(tx, areaID, userIDs)
+}
+
+// scimMemberIDs reads the user IDs of a members attribute
+func scimMemberIDs(c *gin.Context, members []models.SCIMMultiValue) ([]uint, bool) {
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		id := scimID(member.Value)
+		if id == 0 {
+			utils.SCIMErrorResponse(c, 400, "invalidValue", "Invalid member: "+member.Value)
+			return nil, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, true
+}
+
+// toSCIMGroup converts a provisioned group to its SCIM representation; members are the members of its area
+func toSCIMGroup(c *gin.Context, group *models.ScimGroup, withMembers bool) models.SCIMGroup {
+	id := strconv.FormatUint(uint64(group.ID), 10)
+	resource := models.SCIMGroup{
+		Schemas:     []string{utils.SCIMSchemaGroup},
+		ID:          id,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Meta: &models.SCIMMeta{
+			ResourceType: "Group",
+			Created:      &group.CreatedAt,
+			LastModified: &group.UpdatedAt,
+			Location:     scimLocation(c, "/Groups/"+id),
+		},
+	}
+
+	if withMembers {
+		var members []struct {
+			ID       uint
+			FullName string
+		}
+		config.DB.Table("users").
+			Select("users.id, users.full_name").
+			Joins("JOIN user_areas ON user_areas.user_id = users.id").
+			Where("user_areas.area_id = ? AND users.deleted_at IS NULL", group.AreaID).
+			Order("users.id ASC").
+			Scan(&members)
+
+		resource.Members = make([]models.SCIMMultiValue, 0, len(members))
+		for _, member := range members {
+			memberID := strconv.FormatUint(uint64(member.ID), 10)
+			resource.Members = append(resource.Members, models.SCIMMultiValue{
+				Value:   memberID,
+				Display: member.FullName,
+				Ref:     scimLocation(c, "/Users/"+memberID),
+			})
+		}
+	}
+	return resource
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jaliko05/time-flow/models"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter    string
+		attribute string
+		value     string
+		ok        bool
+	}{
+		{`userName eq "ana@example.com"`, "username", "ana@example.com", true},
+		{`  externalId EQ "abc-123"  `, "externalid", "abc-123", true},
+		{`displayName eq "Ana \"La Jefa\" Ruiz"`, "displayname", `Ana "La Jefa" Ruiz`, true},
+		{`displayName eq "back\\slash"`, "displayname", `back\slash`, true},
+		{`userName eq ""`, "username", "", true},
+		{`userName co "ana"`, "", "", false},
+		{`userName eq ana@example.com`, "", "", false},
+		{`userName eq "a" and active eq "true"`, "", "", false},
+		{``, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			attribute, value, ok := parseSCIMFilter(tt.filter)
+			if ok != tt.ok || attribute != tt.attribute || value != tt.value {
+				t.Errorf("parseSCIMFilter() = (%q, %q, %v), want (%q, %q, %v)", attribute, value, ok, tt.attribute, tt.value, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSCIMBool(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{`true`, true, false},
+		{`false`, false, false},
+		{`"True"`, true, false},
+		{`"False"`, false, false},
+		{`"yes"`, false, true},
+		{`1`, false, true},
+		{`null`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := scimBool(json.RawMessage(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scimBool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("scimBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchSCIMUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      models.SCIMPatchOperation
+		wantErr bool
+		check   func(t *testing.T, resource *models.SCIMUser)
+	}{
+		{
+			name: "deactivate",
+			op:   models.SCIMPatchOperation{Op: "Replace", Path: "active", Value: json.RawMessage(`false`)},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if *resource.Active {
+					t.Error("user still active")
+				}
+			},
+		},
+		{
+			name: "deactivate with string",
+			op:   models.SCIMPatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"False"`)},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if *resource.Active {
+					t.Error("user still active")
+				}
+			},
+		},
+		{
+			name:    "invalid active",
+			op:      models.SCIMPatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)},
+			wantErr: true,
+		},
+		{
+			name: "attributes without path",
+			op: models.SCIMPatchOperation{Op: "replace", Value: json.RawMessage(
+				`{"active": false, "displayName": "Ana María Ruiz", "name.givenName": "Ana María"}`)},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if *resource.Active || resource.DisplayName != "Ana María Ruiz" || resource.Name.GivenName != "Ana María" {
+					t.Errorf("attributes not applied: %+v", resource)
+				}
+			},
+		},
+		{
+			name:    "value without path must be an object",
+			op:      models.SCIMPatchOperation{Op: "replace", Value: json.RawMessage(`false`)},
+			wantErr: true,
+		},
+		{
+			name: "paths ignore case",
+			op:   models.SCIMPatchOperation{Op: "add", Path: "userName", Value: json.RawMessage(`"ana.ruiz@example.com"`)},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if resource.UserName != "ana.ruiz@example.com" {
+					t.Errorf("UserName = %q", resource.UserName)
+				}
+			},
+		},
+		{
+			name: "primary email",
+			op:   models.SCIMPatchOperation{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"ana.ruiz@example.com"`)},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if len(resource.Emails) != 1 || resource.Emails[0].Value != "ana.ruiz@example.com" || !resource.Emails[0].Primary {
+					t.Errorf("Emails = %+v", resource.Emails)
+				}
+			},
+		},
+		{
+			name: "remove external ID",
+			op:   models.SCIMPatchOperation{Op: "remove", Path: "externalId"},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if resource.ExternalID != "" {
+					t.Errorf("ExternalID = %q", resource.ExternalID)
+				}
+			},
+		},
+		{
+			name: "required attributes are not removed",
+			op:   models.SCIMPatchOperation{Op: "remove", Path: "userName"},
+			check: func(t *testing.T, resource *models.SCIMUser) {
+				if resource.UserName != "ana@example.com" {
+					t.Errorf("UserName = %q", resource.UserName)
+				}
+			},
+		},
+		{
+			name: "unknown attributes are ignored",
+			op:   models.SCIMPatchOperation{Op: "replace", Path: "phoneNumbers", Value: json.RawMessage(`[{"value": "555"}]`)},
+		},
+		{
+			name:    "unsupported operation",
+			op:      models.SCIMPatchOperation{Op: "move", Path: "active", Value: json.RawMessage(`false`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The same working copy SCIMPatchUser starts from
+			active := true
+			resource := &models.SCIMUser{
+				UserName:    "ana@example.com",
+				DisplayName: "Ana Ruiz",
+				ExternalID:  "abc-123",
+				Active:      &active,
+				Name:        &models.SCIMName{},
+			}
+			err := patchSCIMUser(resource, tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patchSCIMUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, resource)
+			}
+		})
+	}
+}
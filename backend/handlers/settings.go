@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// GetMySettings godoc
+// @Summary Get my settings
+// @Description Get the timezone, locale, week start and default activity type of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.UserSettings}
+// @Failure 401 {object} utils.Response
+// @Router /auth/me/settings [get]
+func GetMySettings(c *gin.Context) {
+	utils.SuccessResponse(c, 200, "Settings retrieved successfully", currentUserSettings(c))
+}
+
+// UpdateMySettings godoc
+// @Summary Update my settings
+// @Description Update the timezone (IANA name), locale, week start and default activity type of the authenticated user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body UpdateUserSettingsRequest true "Settings to change"
+// @Success 200 {object} utils.Response{data=models.UserSettings}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/me/settings [put]
+func UpdateMySettings(c *gin.Context) {
+	var req models.UpdateUserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	settings := currentUserSettings(c)
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			utils.ErrorResponse(c, 400, "Invalid timezone. Use an IANA name such as America/Bogota")
+			return
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		if !localePattern.MatchString(*req.Locale) {
+			utils.ErrorResponse(c, 400, "Invalid locale. Use a language code such as es or es-CO")
+			return
+		}
+		settings.Locale = *req.Locale
+	}
+	if req.WeekStart != nil {
+		settings.WeekStart = *req.WeekStart
+	}
+	if req.DefaultActivityType != nil {
+		if *req.DefaultActivityType != "" {
+			var count int64
+			models.ScopeActivityTypesFor(config.DB.Model(&models.ActivityTypeDefinition{}), models.UserAreaIDs(config.DB, settings.UserID)).
+				Where("code = ?", *req.DefaultActivityType).
+				Count(&count)
+			if count == 0 {
+				utils.ErrorResponse(c, 400, "Activity type is not available in your areas")
+				return
+			}
+		}
+		settings.DefaultActivityType = *req.DefaultActivityType
+	}
+
+	if err := config.DB.Save(settings).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update settings")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Settings updated successfully", settings)
+}
+
+// currentUserSettings returns the settings of the authenticated user
+func currentUserSettings(c *gin.Context) *models.UserSettings {
+	return loadUserSettings(c.MustGet("user_id").(uint))
+}
+
+// loadUserSettings returns the saved settings of a user, or the defaults when there are none.
+// DEFAULT_TIMEZONE sets the timezone of users without settings (UTC by default).
+func loadUserSettings(userID uint) *models.UserSettings {
+	var settings models.UserSettings
+	if err := config.DB.Where("user_id = ?", userID).First(&settings).Error; err == nil {
+		return &settings
+	}
+
+	timezone := os.Getenv("DEFAULT_TIMEZONE")
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		timezone = models.DefaultTimezone
+	}
+	return &models.UserSettings{
+		UserID:    userID,
+		Timezone:  timezone,
+		Locale:    models.DefaultLocale,
+		WeekStart: models.DefaultWeekStart,
+	}
+}
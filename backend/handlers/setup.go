@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// GetSetupStatus godoc
+// @Summary Get setup status
+// @Description Check whether the server is waiting for the first super admin to be created
+// @Tags setup
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /setup/status [get]
+func GetSetupStatus(c *gin.Context) {
+	utils.SuccessResponse(c, 200, "Setup status retrieved successfully", gin.H{
+		"setup_required": config.IsSetupRequired(),
+	})
+}
+
+// CompleteSetup godoc
+// @Summary Create first super admin
+// @Description Create the first super admin using the one-time setup token printed at startup. Only available in first-run mode.
+// @Tags setup
+// @Accept json
+// @Produce json
+// @Param setup body SetupRequest true "Setup token and super admin data"
+// @Success 201 {object} LoginResponse
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /setup [post]
+func CompleteSetup(c *gin.Context) {
+	var req models.SetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	user, err := config.CompleteSetup(req.SetupToken, req.Email, req.Password, req.FullName)
+	if errors.Is(err, config.ErrSetupAlreadyCompleted) {
+		utils.ErrorResponse(c, 409, "Setup has already been completed")
+		return
+	}
+	if errors.Is(err, config.ErrInvalidSetupToken) {
+		utils.ErrorResponse(c, 401, "Invalid setup token")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create super admin: "+err.Error())
+		return
+	}
+
+	token, err := utils.GenerateToken(user)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate token")
+		return
+	}
+
+	response := models.LoginResponse{
+		Token: token,
+		User: models.UserResponse{
+			ID:       user.ID,
+			Email:    user.Email,
+			FullName: user.FullName,
+			Role:     user.Role,
+			IsActive: user.IsActive,
+		},
+	}
+
+	utils.SuccessResponse(c, 201, "Setup completed successfully", response)
+}
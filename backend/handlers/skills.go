@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetSkills godoc
+// @Summary Get skills catalog
+// @Description Get the skills catalog. With area_id, returns the skills available in that area (global and area skills).
+// @Tags skills
+// @Produce json
+// @Security BearerAuth
+// @Param area_id query int false "Filter by area ID"
+// @Param category query string false "Filter by category"
+// @Param include_inactive query bool false "Include inactive skills"
+// @Success 200 {object} utils.Response{data=[]models.Skill}
+// @Failure 401 {object} utils.Response
+// @Router /skills [get]
+func GetSkills(c *gin.Context) {
+	query := config.DB.Model(&models.Skill{})
+
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = query.Where("area_id IS NULL OR area_id = ?", uint(areaID))
+		}
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if c.Query("include_inactive") != "true" {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var skills []models.Skill
+	if err := query.Order("category ASC, name ASC").Find(&skills).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve skills")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Skills retrieved successfully", skills)
+}
+
+// CreateSkill godoc
+// @Summary Create skill
+// @Description Add a skill to the catalog. Global skills require the global skills.manage permission.
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param skill body CreateSkillRequest true "Skill data"
+// @Success 201 {object} utils.Response{data=models.Skill}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /skills [post]
+func CreateSkill(c *gin.Context) {
+	var req models.CreateSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	if !policy.FromContext(c).Can(models.PermSkillsManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only add skills to areas you manage")
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if skillNameTaken(name, req.AreaID, 0) {
+		utils.ErrorResponse(c, 400, "A skill with this name already exists")
+		return
+	}
+
+	skill := models.Skill{
+		Name:        name,
+		Category:    strings.TrimSpace(req.Category),
+		Description: req.Description,
+		AreaID:      req.AreaID,
+		IsActive:    true,
+		CreatedBy:   c.MustGet("user_id").(uint),
+	}
+	if err := config.DB.Create(&skill).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to create skill")
+		return
+	}
+
+	utils.SuccessResponse(c, 201, "Skill created successfully", skill)
+}
+
+// UpdateSkill godoc
+// @Summary Update skill
+// @Description Update a skill of the catalog
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Param skill body UpdateSkillRequest true "Skill data"
+// @Success 200 {object} utils.Response{data=models.Skill}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /skills/{id} [put]
+func UpdateSkill(c *gin.Context) {
+	var req models.UpdateSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var skill models.Skill
+	if err := config.DB.First(&skill, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Skill not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermSkillsManage, skill.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if skillNameTaken(name, skill.AreaID, skill.ID) {
+			utils.ErrorResponse(c, 400, "A skill with this name already exists")
+			return
+		}
+		updates["name"] = name
+	}
+	if req.Category != nil {
+		updates["category"] = strings.TrimSpace(*req.Category)
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if err := config.DB.Model(&skill).Updates(updates).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update skill")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Skill updated successfully", skill)
+}
+
+// DeleteSkill godoc
+// @Summary Delete skill
+// @Description Remove a skill from the catalog, together with the user levels and requirements that use it
+// @Tags skills
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Success 200 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /skills/{id} [delete]
+func DeleteSkill(c *gin.Context) {
+	var skill models.Skill
+	if err := config.DB.First(&skill, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Skill not found")
+		return
+	}
+	if !policy.FromContext(c).Can(models.PermSkillsManage, skill.AreaID) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.UserSkill{}, &models.ProjectSkill{}, &models.TaskSkill{}} {
+			if err := tx.Where("skill_id = ?", skill.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&skill).Error
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to delete skill")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Skill deleted successfully", nil)
+}
+
+// GetUserSkills godoc
+// @Summary Get user skills
+// @Description Get the skills and proficiency levels of a user
+// @Tags skills
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=[]models.UserSkill}
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/skills [get]
+func GetUserSkills(c *gin.Context) {
+	var user models.User
+	subject := policy.FromContext(c)
+	query := config.DB
+	if c.Param("id") != strconv.FormatUint(uint64(subject.UserID), 10) {
+		query = subject.ScopeUsers(query)
+	}
+	if err := query.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	var skills []models.UserSkill
+	if err := config.DB.Preload("Skill").Where("user_id = ?", user.ID).Order("level DESC").Find(&skills).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve user skills")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User skills retrieved successfully", skills)
+}
+
+// SetUserSkills godoc
+// @Summary Set user skills
+// @Description Replace the skills of a user with their proficiency level (1 basic - 5 expert). Users can set their own skills.
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param skills body SetUserSkillsRequest true "Skills and levels"
+// @Success 200 {object} utils.Response{data=[]models.UserSkill}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/skills [put]
+func SetUserSkills(c *gin.Context) {
+	var req models.SetUserSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+	subject := policy.FromContext(c)
+	if user.ID != subject.UserID && !subject.CanManageUser(&user) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	skillIDs := make([]uint, 0, len(req.Skills))
+	for _, s := range req.Skills {
+		skillIDs = append(skillIDs, s.SkillID)
+	}
+	if msg := validateSkillIDs(skillIDs, nil); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserSkill{}).Error; err != nil {
+			return err
+		}
+		for _, s := range req.Skills {
+			if err := tx.Create(&models.UserSkill{UserID: user.ID, SkillID: s.SkillID, Level: s.Level}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update user skills")
+		return
+	}
+
+	var skills []models.UserSkill
+	config.DB.Preload("Skill").Where("user_id = ?", user.ID).Order("level DESC").Find(&skills)
+
+	utils.SuccessResponse(c, 200, "User skills updated successfully", skills)
+}
+
+// SetProjectSkills godoc
+// @Summary Set project required skills
+// @Description Replace the skills required to work on a project, with the minimum level of each
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Project ID"
+// @Param skills body SetRequiredSkillsRequest true "Required skills"
+// @Success 200 {object} utils.Response{data=[]models.ProjectSkill}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /projects/{id}/skills [put]
+func SetProjectSkills(c *gin.Context) {
+	var req models.SetRequiredSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var project models.Project
+	if err := config.DB.First(&project, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Project not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageProject(&project) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+	if msg := validateRequiredSkills(req.Skills, project.AreaID); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectSkill{}).Error; err != nil {
+			return err
+		}
+		for _, s := range req.Skills {
+			if err := tx.Create(&models.ProjectSkill{ProjectID: project.ID, SkillID: s.SkillID, MinLevel: minLevel(s.MinLevel)}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update project skills")
+		return
+	}
+
+	var skills []models.ProjectSkill
+	config.DB.Preload("Skill").Where("project_id = ?", project.ID).Find(&skills)
+
+	utils.SuccessResponse(c, 200, "Project skills updated successfully", skills)
+}
+
+// SetTaskSkills godoc
+// @Summary Set task required skills
+// @Description Replace the skills required to work on a task, with the minimum level of each. Tasks without skills use the project's.
+// @Tags skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param skills body SetRequiredSkillsRequest true "Required skills"
+// @Success 200 {object} utils.Response{data=[]models.TaskSkill}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/skills [put]
+func SetTaskSkills(c *gin.Context) {
+	var req models.SetRequiredSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+	if msg := validateRequiredSkills(req.Skills, task.Project.AreaID); msg != "" {
+		utils.ErrorResponse(c, 400, msg)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskSkill{}).Error; err != nil {
+			return err
+		}
+		for _, s := range req.Skills {
+			if err := tx.Create(&models.TaskSkill{TaskID: task.ID, SkillID: s.SkillID, MinLevel: minLevel(s.MinLevel)}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update task skills")
+		return
+	}
+
+	var skills []models.TaskSkill
+	config.DB.Preload("Skill").Where("task_id = ?", task.ID).Find(&skills)
+
+	utils.SuccessResponse(c, 200, "Task skills updated successfully", skills)
+}
+
+// skillNameTaken checks if another skill of the same scope (global or area) has the name
+func skillNameTaken(name string, areaID *uint, excludeID uint) bool {
+	query := config.DB.Model(&models.Skill{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID)
+	if areaID == nil {
+		query = query.Where("area_id IS NULL")
+	} else {
+		query = query.Where("area_id = ?", *areaID)
+	}
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+// validateRequiredSkills checks the skills of a requirement list, returning an error message or ""
+func validateRequiredSkills(skills []models.RequiredSkillInput, areaID *uint) string {
+	skillIDs := make([]uint, 0, len(skills))
+	for _, s := range skills {
+		skillIDs = append(skillIDs, s.SkillID)
+	}
+	return validateSkillIDs(skillIDs, areaID)
+}
+
+// validateSkillIDs checks that the skills exist, are active, are not repeated and, when an area
+// is given, are available in it. Returns an error message or "".
+func validateSkillIDs(skillIDs []uint, areaID *uint) string {
+	if len(skillIDs) == 0 {
+		return ""
+	}
+	seen := make(map[uint]bool, len(skillIDs))
+	for _, id := range skillIDs {
+		if seen[id] {
+			return "Each skill can only appear once"
+		}
+		seen[id] = true
+	}
+
+	var skills []models.Skill
+	config.DB.Where("id IN ? AND is_active = ?", skillIDs, true).Find(&skills)
+	if len(skills) != len(skillIDs) {
+		return "One or more skills were not found or are inactive"
+	}
+	if areaID != nil {
+		for i := range skills {
+			if !skills[i].AvailableIn(areaID) {
+				return "Skill '" + skills[i].Name + "' belongs to another area"
+			}
+		}
+	}
+	return ""
+}
+
+func minLevel(level int) int {
+	if level < models.MinSkillLevel {
+		return models.MinSkillLevel
+	}
+	return level
+}
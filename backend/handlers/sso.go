@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/identity"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errEmailInUse = errors.New("email belongs to an account with another sign-in method")
+
+// GetAuthProviders godoc
+// @Summary Get identity providers
+// @Description Get the configured identity providers with what a client needs to start their login flow
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.Response{data=[]identity.ProviderInfo}
+// @Router /auth/providers [get]
+func GetAuthProviders(c *gin.Context) {
+	providers := identity.All()
+	infos := make([]identity.ProviderInfo, 0, len(providers))
+	for _, provider := range providers {
+		infos = append(infos, provider.Info(c.Request.Context()))
+	}
+
+	utils.SuccessResponse(c, 200, "Identity providers retrieved successfully", infos)
+}
+
+// SSOLogin godoc
+// @Summary Login with an identity provider
+// @Description Authenticate user with credentials from a configured identity provider.
+// @Description OIDC providers accept an ID token, or an authorization code that the server exchanges.
+// @Description New accounts wait for SuperAdmin approval.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name (see /auth/providers)"
+// @Param credentials body SSOLoginRequest true "Provider credentials"
+// @Success 200 {object} LoginResponse
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /auth/sso/{provider} [post]
+func SSOLogin(c *gin.Context) {
+	var req models.SSOLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	ssoLogin(c, c.Param("provider"), identity.Credentials{
+		IDToken:      req.IDToken,
+		AccessToken:  req.AccessToken,
+		Code:         req.Code,
+		RedirectURI:  req.RedirectURI,
+		CodeVerifier: req.CodeVerifier,
+		Nonce:        req.Nonce,
+	})
+}
+
+// ssoLogin authenticates against a provider and signs the linked user in. On first login the account is
+// created pending approval unless an identity mapping rule matches.
+func ssoLogin(c *gin.Context, providerName string, creds identity.Credentials) {
+	provider, err := identity.Get(providerName)
+	if err != nil {
+		utils.ErrorResponse(c, 404, "Identity provider not found")
+		return
+	}
+
+	ident, err := provider.Authenticate(c.Request.Context(), creds)
+	if err != nil {
+		utils.ErrorResponse(c, 401, "Invalid "+providerName+" credentials: "+err.Error())
+		return
+	}
+
+	user, err := findSSOUser(ident)
+	if errors.Is(err, errEmailInUse) {
+		utils.ErrorResponse(c, 409, "An account with this email already exists. Sign in with your usual method")
+		return
+	}
+	created := false
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// User doesn't exist, create new user (mapping rules may approve it right away)
+		user = &models.User{
+			Email:          ident.Email,
+			FullName:       ident.Name,
+			Role:           models.RoleUser, // Default role
+			AuthProvider:   ident.Provider,
+			IsActive:       false, // Pending SuperAdmin approval
+			ApprovalStatus: models.ApprovalStatusPending,
+		}
+		if ident.Provider == identity.MicrosoftProviderName {
+			user.MicrosoftID = &ident.Subject
+			user.MicrosoftAccessToken = &ident.AccessToken
+		}
+
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(user).Error; err != nil {
+				return err
+			}
+			if err := linkIdentity(tx, user.ID, ident); err != nil {
+				return err
+			}
+			_, err := syncIdentityMappings(tx, user, ident)
+			return err
+		})
+		if err != nil {
+			utils.ErrorResponse(c, 500, "Failed to create user")
+			return
+		}
+		created = true
+	} else if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve user")
+		return
+	} else if user.IsPendingApproval() || (user.IsActive && user.ApprovalStatus == models.ApprovalStatusApproved) {
+		// Follow directory changes, such as people moving teams, on every login
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			_, err := syncIdentityMappings(tx, user, ident)
+			return err
+		})
+		if err != nil {
+			log.Printf("Error applying identity mappings to user %d: %v", user.ID, err)
+			utils.ErrorResponse(c, 500, "Failed to apply identity mappings")
+			return
+		}
+	}
+
+	// Reload to get Area relation
+	user.Area = nil
+	config.DB.Preload("Area").First(user, user.ID)
+
+	// Applicants see the state of their request until a SuperAdmin reviews it
+	if user.IsPendingApproval() {
+		message := "Account is still waiting for administrator approval"
+		if created {
+			message = "Account created. Waiting for administrator approval"
+		}
+		pendingApprovalResponse(c, user, message)
+		return
+	}
+	if user.ApprovalStatus == models.ApprovalStatusRejected {
+		message := "Account request was rejected"
+		if user.RejectionReason != "" {
+			message += ": " + user.RejectionReason
+		}
+		utils.ErrorResponse(c, 403, message)
+		return
+	}
+
+	// User exists, check if active
+	if !user.IsActive {
+		utils.ErrorResponse(c, 401, "User account is inactive")
+		return
+	}
+
+	updates := map[string]interface{}{"auth_provider": ident.Provider}
+	if ident.Provider == identity.MicrosoftProviderName {
+		// Always update the access token on login so the calendar keeps working
+		if user.MicrosoftID == nil || *user.MicrosoftID == "" {
+			updates["microsoft_id"] = ident.Subject
+		}
+		updates["microsoft_access_token"] = ident.AccessToken
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		return linkIdentity(tx, user.ID, ident)
+	})
+	if err != nil {
+		log.Printf("Error updating user %d after %s login: %v", user.ID, ident.Provider, err)
+		utils.ErrorResponse(c, 500, "Failed to update user token")
+		return
+	}
+
+	// Generate JWT token
+	token, err := utils.GenerateToken(user)
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to generate token")
+		return
+	}
+
+	response := models.LoginResponse{
+		Token: token,
+		User: models.UserResponse{
+			ID:           user.ID,
+			Email:        user.Email,
+			FullName:     user.FullName,
+			Role:         user.Role,
+			AreaID:       user.AreaID,
+			Area:         user.Area,
+			WorkSchedule: user.WorkSchedule,
+			LunchBreak:   user.LunchBreak,
+			IsActive:     user.IsActive,
+		},
+	}
+
+	utils.SuccessResponse(c, 200, "Login successful", response)
+}
+
+// findSSOUser returns the user linked to the identity, falling back to an account with the same
+// email that already signs in through this provider. Accounts using another method are not taken over.
+func findSSOUser(ident *identity.Identity) (*models.User, error) {
+	var user models.User
+
+	var link models.UserIdentity
+	if err := config.DB.Where("provider = ? AND subject = ?", ident.Provider, ident.Subject).First(&link).Error; err == nil {
+		if err := config.DB.Preload("Area").First(&user, link.UserID).Error; err == nil {
+			return &user, nil
+		}
+	}
+
+	if err := config.DB.Preload("Area").Where("LOWER(email) = ?", ident.Email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	if user.AuthProvider != ident.Provider {
+		return nil, errEmailInUse
+	}
+	return &user, nil
+}
+
+// linkIdentity records the provider account of a user and when it last signed in
+func linkIdentity(tx *gorm.DB, userID uint, ident *identity.Identity) error {
+	now := time.Now()
+	link := models.UserIdentity{
+		UserID:      userID,
+		Provider:    ident.Provider,
+		Subject:     ident.Subject,
+		Email:       ident.Email,
+		LastLoginAt: &now,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}, {Name: "subject"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "email", "last_login_at", "updated_at"}),
+	}).Create(&link).Error
+}
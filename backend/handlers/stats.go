@@ -2,16 +2,20 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
 )
 
 // GetAreasSummary godoc
 // @Summary Get summary by areas
-// @Description Get aggregated statistics by area (SuperAdmin only)
+// @Description Get aggregated statistics for the areas where the caller holds stats.view.
+// @Description Each area includes a roll-up over all its descendant areas (e.g. a directorate including its teams).
 // @Tags stats
 // @Produce json
 // @Security BearerAuth
@@ -21,16 +25,7 @@ import (
 // @Failure 403 {object} utils.Response
 // @Router /stats/areas [get]
 func GetAreasSummary(c *gin.Context) {
-	userRole, _ := c.Get("user_role")
-
-	// Only SuperAdmin can see all areas summary
-	role := userRole.(models.Role)
-	if role != models.RoleSuperAdmin {
-		utils.ErrorResponse(c, 403, "Only SuperAdmin can access this endpoint")
-		return
-	}
-
-	areaQuery := config.DB.Model(&models.Area{})
+	areaQuery := policy.FromContext(c).ScopeAreas(config.DB.Model(&models.Area{}), models.PermStatsView, "areas.id")
 
 	// Filter by area if specified
 	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
@@ -42,45 +37,58 @@ func GetAreasSummary(c *gin.Context) {
 	var areas []models.Area
 	areaQuery.Find(&areas)
 
+	tree := models.LoadAreaTree(config.DB)
 	var summaries []models.AreaSummary
 
 	for _, area := range areas {
-		summary := models.AreaSummary{
-			AreaID:   area.ID,
-			AreaName: area.Name,
-		}
+		// Direct figures for the area itself, plus a roll-up over every descendant area
+		direct := areaTotals([]uint{area.ID})
+		rollup := areaTotals(tree.Descendants(area.ID))
+
+		summaries = append(summaries, models.AreaSummary{
+			AreaID:            area.ID,
+			AreaName:          area.Name,
+			TotalUsers:        direct.TotalUsers,
+			TotalProjects:     direct.TotalProjects,
+			ActiveProjects:    direct.ActiveProjects,
+			TotalActivities:   direct.TotalActivities,
+			TotalHours:        direct.TotalHours,
+			AverageCompletion: direct.AverageCompletion,
+			ParentID:          area.ParentID,
+			Depth:             tree.Depth(area.ID),
+			Rollup:            &rollup,
+		})
+	}
 
-		// Count users in area
-		config.DB.Model(&models.User{}).Where("area_id = ? AND role = ?", area.ID, models.RoleUser).Count(&summary.TotalUsers)
+	utils.SuccessResponse(c, 200, "Area summaries retrieved successfully", summaries)
+}
 
-		// Count projects in area
-		config.DB.Model(&models.Project{}).Where("area_id = ?", area.ID).Count(&summary.TotalProjects)
-		config.DB.Model(&models.Project{}).Where("area_id = ? AND is_active = ?", area.ID, true).Count(&summary.ActiveProjects)
+// areaTotals aggregates users, projects and activities across a set of areas
+func areaTotals(areaIDs []uint) models.AreaTotals {
+	totals := models.AreaTotals{AreaIDs: areaIDs}
 
-		// Count activities and hours in area
-		config.DB.Model(&models.Activity{}).Where("area_id = ?", area.ID).Count(&summary.TotalActivities)
-		config.DB.Model(&models.Activity{}).Where("area_id = ?", area.ID).Select("COALESCE(SUM(execution_time), 0)").Scan(&summary.TotalHours)
+	// Count users in the areas (members are counted once even if they belong to several)
+	config.DB.Model(&models.User{}).
+		Where("(users.area_id IN ? OR users.id IN (SELECT user_id FROM user_areas WHERE area_id IN ?)) AND role = ?", areaIDs, areaIDs, models.RoleUser).
+		Count(&totals.TotalUsers)
 
-		// Calculate average completion
-		var projects []models.Project
-		config.DB.Where("area_id = ?", area.ID).Find(&projects)
-		if len(projects) > 0 {
-			totalCompletion := 0.0
-			for _, p := range projects {
-				totalCompletion += p.CompletionPercent
-			}
-			summary.AverageCompletion = totalCompletion / float64(len(projects))
-		}
+	// Count projects in the areas
+	config.DB.Model(&models.Project{}).Where("area_id IN ?", areaIDs).Count(&totals.TotalProjects)
+	config.DB.Model(&models.Project{}).Where("area_id IN ? AND is_active = ?", areaIDs, true).Count(&totals.ActiveProjects)
 
-		summaries = append(summaries, summary)
-	}
+	// Count activities and hours in the areas
+	config.DB.Model(&models.Activity{}).Where("area_id IN ?", areaIDs).Count(&totals.TotalActivities)
+	config.DB.Model(&models.Activity{}).Where("area_id IN ?", areaIDs).Select("COALESCE(SUM(execution_time), 0)").Scan(&totals.TotalHours)
 
-	utils.SuccessResponse(c, 200, "Area summaries retrieved successfully", summaries)
+	// Calculate average completion
+	config.DB.Model(&models.Project{}).Where("area_id IN ?", areaIDs).Select("COALESCE(AVG(completion_percent), 0)").Scan(&totals.AverageCompletion)
+
+	return totals
 }
 
 // GetUsersSummary godoc
 // @Summary Get summary by users
-// @Description Get aggregated statistics by users. SuperAdmin sees all, Admin sees users in their area.
+// @Description Get aggregated statistics by users in the areas where the caller holds stats.view
 // @Tags stats
 // @Produce json
 // @Security BearerAuth
@@ -90,27 +98,25 @@ func GetAreasSummary(c *gin.Context) {
 // @Failure 401 {object} utils.Response
 // @Router /stats/users [get]
 func GetUsersSummary(c *gin.Context) {
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
-
 	userQuery := config.DB.Model(&models.User{}).Where("role = ?", models.RoleUser)
 
-	// Apply role-based filters
-	role := userRole.(models.Role)
-	if role == models.RoleAdmin {
-		// Admin only sees users in their area
-		if userAreaID != nil {
-			userQuery = userQuery.Where("area_id = ?", userAreaID)
-		}
-	} else if role == models.RoleUser {
-		utils.ErrorResponse(c, 403, "Users cannot access this endpoint")
-		return
+	// Restrict to areas where the caller can view statistics
+	subject := policy.FromContext(c)
+	userQuery = subject.ScopeAreaMembers(userQuery, models.PermStatsView)
+
+	// Activity totals only count the areas the caller can see (or the requested area)
+	activityQuery := func() *gorm.DB {
+		return subject.ScopeAreas(config.DB.Model(&models.Activity{}), models.PermStatsView, "activities.area_id")
 	}
 
 	// Optional filters
-	if areaIDStr := c.Query("area_id"); areaIDStr != "" && role == models.RoleSuperAdmin {
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
 		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
-			userQuery = userQuery.Where("area_id = ?", uint(areaID))
+			userQuery = whereAreaMember(userQuery, uint(areaID))
+			scoped := activityQuery
+			activityQuery = func() *gorm.DB {
+				return scoped().Where("activities.area_id = ?", uint(areaID))
+			}
 		}
 	}
 
@@ -133,15 +139,17 @@ func GetUsersSummary(c *gin.Context) {
 		}
 
 		// Count activities and hours for this user
-		config.DB.Model(&models.Activity{}).Where("user_id = ?", user.ID).Count(&summary.TotalActivities)
-		config.DB.Model(&models.Activity{}).Where("user_id = ?", user.ID).Select("COALESCE(SUM(execution_time), 0)").Scan(&summary.TotalHours)
+		activityQuery().Where("user_id = ?", user.ID).Count(&summary.TotalActivities)
+		activityQuery().Where("user_id = ?", user.ID).Select("COALESCE(SUM(execution_time), 0)").Scan(&summary.TotalHours)
 
 		// Count assigned projects
-		config.DB.Model(&models.Project{}).Where("assigned_user_id = ?", user.ID).Count(&summary.AssignedProjects)
+		assignedProjects := config.DB.Table("project_assignments").Select("project_id").
+			Where("user_id = ? AND is_active = ? AND deleted_at IS NULL", user.ID, true)
+		config.DB.Model(&models.Project{}).Where("id IN (?)", assignedProjects).Count(&summary.AssignedProjects)
 
 		// Calculate average completion of assigned projects
 		var projects []models.Project
-		config.DB.Where("assigned_user_id = ?", user.ID).Find(&projects)
+		config.DB.Where("id IN (?)", assignedProjects).Find(&projects)
 		if len(projects) > 0 {
 			totalCompletion := 0.0
 			for _, p := range projects {
@@ -158,44 +166,31 @@ func GetUsersSummary(c *gin.Context) {
 
 // GetProjectsSummary godoc
 // @Summary Get summary of projects
-// @Description Get detailed statistics of projects. SuperAdmin sees all, Admin sees their area's projects.
+// @Description Get detailed statistics of projects hosted in or shared with the areas where the caller holds stats.view,
+// @Description including the hours each area contributed
 // @Tags stats
 // @Produce json
 // @Security BearerAuth
-// @Param area_id query int false "Filter by area ID"
+// @Param area_id query int false "Filter by host or shared area ID"
 // @Param assigned_user_id query int false "Filter by assigned user ID"
 // @Param active query bool false "Filter by active status"
 // @Success 200 {object} utils.Response{data=[]models.ProjectSummary}
 // @Failure 401 {object} utils.Response
 // @Router /stats/projects [get]
 func GetProjectsSummary(c *gin.Context) {
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
-
-	projectQuery := config.DB.Model(&models.Project{}).Preload("AssignedUser")
-
-	// Apply role-based filters
-	role := userRole.(models.Role)
-	if role == models.RoleAdmin {
-		// Admin only sees projects in their area
-		if userAreaID != nil {
-			projectQuery = projectQuery.Where("area_id = ?", userAreaID)
-		}
-	} else if role == models.RoleUser {
-		utils.ErrorResponse(c, 403, "Users cannot access this endpoint")
-		return
-	}
+	// Restrict to projects hosted in or shared with areas where the caller can view statistics
+	projectQuery := policy.FromContext(c).ScopeProjectAreas(config.DB.Model(&models.Project{}), models.PermStatsView)
 
 	// Optional filters
-	if areaIDStr := c.Query("area_id"); areaIDStr != "" && role == models.RoleSuperAdmin {
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
 		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
-			projectQuery = projectQuery.Where("area_id = ?", uint(areaID))
+			projectQuery = models.ScopeProjectsInAreas(projectQuery, []uint{uint(areaID)})
 		}
 	}
 
 	if assignedUserIDStr := c.Query("assigned_user_id"); assignedUserIDStr != "" {
 		if assignedUserID, err := strconv.ParseUint(assignedUserIDStr, 10, 32); err == nil {
-			projectQuery = projectQuery.Where("assigned_user_id = ?", uint(assignedUserID))
+			projectQuery = projectQuery.Where("id IN (SELECT project_id FROM project_assignments WHERE user_id = ? AND is_active = ? AND deleted_at IS NULL)", uint(assignedUserID), true)
 		}
 	}
 
@@ -209,6 +204,7 @@ func GetProjectsSummary(c *gin.Context) {
 
 	var projects []models.Project
 	projectQuery.Find(&projects)
+	hoursByArea := projectHoursByArea(projects)
 
 	var summaries []models.ProjectSummary
 
@@ -221,6 +217,12 @@ func GetProjectsSummary(c *gin.Context) {
 			RemainingHours:    project.RemainingHours,
 			CompletionPercent: project.CompletionPercent,
 			IsActive:          project.IsActive,
+			AreaID:            project.AreaID,
+			SharedAreaIDs:     models.SharedAreaIDs(config.DB, project.ID),
+			HoursByArea:       hoursByArea[project.ID],
+		}
+		if summary.HoursByArea == nil {
+			summary.HoursByArea = []models.AreaHours{}
 		}
 
 		// Load assigned users from junction table
@@ -242,3 +244,130 @@ func GetProjectsSummary(c *gin.Context) {
 
 	utils.SuccessResponse(c, 200, "Project summaries retrieved successfully", summaries)
 }
+
+// projectHoursByArea sums the hours logged on each project per area the activities count towards
+func projectHoursByArea(projects []models.Project) map[uint][]models.AreaHours {
+	projectIDs := make([]uint, 0, len(projects))
+	for _, p := range projects {
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	var rows []struct {
+		ProjectID uint
+		AreaID    *uint
+		AreaName  string
+		Hours     float64
+	}
+	config.DB.Model(&models.Activity{}).
+		Select("activities.project_id, activities.area_id, COALESCE(MAX(areas.name), '') AS area_name, SUM(activities.execution_time) AS hours").
+		Joins("LEFT JOIN areas ON areas.id = activities.area_id").
+		Where("activities.project_id IN ?", models.NonEmptyIDs(projectIDs)).
+		Group("activities.project_id, activities.area_id").
+		Order("hours DESC").
+		Scan(&rows)
+
+	byProject := make(map[uint][]models.AreaHours, len(projects))
+	for _, row := range rows {
+		byProject[row.ProjectID] = append(byProject[row.ProjectID], models.AreaHours{
+			AreaID:   row.AreaID,
+			AreaName: row.AreaName,
+			Hours:    roundHours(row.Hours),
+		})
+	}
+	return byProject
+}
+
+// GetBillingReport godoc
+// @Summary Get cost and revenue report
+// @Description Get hours, cost, revenue and margin per project, area or month for the areas where the caller holds stats.view.
+// @Description Amounts come from the rates snapshotted when each activity was logged, so later rate changes do not alter them.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string false "Group by project, area or month (default project)"
+// @Param date_from query string false "Start date (YYYY-MM-DD)"
+// @Param date_to query string false "End date (YYYY-MM-DD)"
+// @Param area_id query int false "Filter by area ID"
+// @Param project_id query int false "Filter by project ID"
+// @Success 200 {object} utils.Response{data=models.BillingReport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /stats/billing [get]
+func GetBillingReport(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "project")
+
+	query := policy.FromContext(c).ScopeAreas(config.DB.Model(&models.Activity{}), models.PermStatsView, "activities.area_id")
+	switch groupBy {
+	case "project":
+		query = query.Joins("LEFT JOIN projects ON projects.id = activities.project_id").
+			Select("COALESCE(CAST(activities.project_id AS TEXT), '') AS key, COALESCE(MAX(projects.name), 'No project') AS label, " + billingTotalsSelect).
+			Group("activities.project_id")
+	case "area":
+		query = query.Joins("LEFT JOIN areas ON areas.id = activities.area_id").
+			Select("COALESCE(CAST(activities.area_id AS TEXT), '') AS key, COALESCE(MAX(areas.name), 'No area') AS label, " + billingTotalsSelect).
+			Group("activities.area_id")
+	case "month":
+		query = query.Select("TO_CHAR(activities.date, 'YYYY-MM') AS key, TO_CHAR(activities.date, 'YYYY-MM') AS label, " + billingTotalsSelect).
+			Group("TO_CHAR(activities.date, 'YYYY-MM')")
+	default:
+		utils.ErrorResponse(c, 400, "group_by must be project, area or month")
+		return
+	}
+
+	if dateFrom := c.Query("date_from"); dateFrom != "" {
+		parsedDate, err := time.Parse("2006-01-02", dateFrom)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid date_from format. Use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("activities.date >= ?", parsedDate)
+	}
+	if dateTo := c.Query("date_to"); dateTo != "" {
+		parsedDate, err := time.Parse("2006-01-02", dateTo)
+		if err != nil {
+			utils.ErrorResponse(c, 400, "Invalid date_to format. Use YYYY-MM-DD")
+			return
+		}
+		query = query.Where("activities.date <= ?", parsedDate)
+	}
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = query.Where("activities.area_id = ?", uint(areaID))
+		}
+	}
+	if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+		if projectID, err := strconv.ParseUint(projectIDStr, 10, 32); err == nil {
+			query = query.Where("activities.project_id = ?", uint(projectID))
+		}
+	}
+
+	rows := []models.BillingReportRow{}
+	if err := query.Order("key ASC").Scan(&rows).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to build billing report")
+		return
+	}
+
+	report := models.BillingReport{
+		GroupBy:  groupBy,
+		Currency: utils.GetBillingCurrency(),
+		Rows:     rows,
+		Totals:   models.BillingReportRow{Key: "total", Label: "Total"},
+	}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		row.Margin = row.Revenue - row.Cost
+		report.Totals.Hours += row.Hours
+		report.Totals.BillableHours += row.BillableHours
+		report.Totals.Cost += row.Cost
+		report.Totals.Revenue += row.Revenue
+	}
+	report.Totals.Margin = report.Totals.Revenue - report.Totals.Cost
+
+	utils.SuccessResponse(c, 200, "Billing report retrieved successfully", report)
+}
+
+// billingTotalsSelect sums the hours and the snapshotted amounts of a group of activities
+const billingTotalsSelect = `COALESCE(SUM(activities.execution_time), 0) AS hours,
+	COALESCE(SUM(CASE WHEN activities.billable THEN activities.execution_time ELSE 0 END), 0) AS billable_hours,
+	COALESCE(SUM(activities.cost_amount), 0) AS cost,
+	COALESCE(SUM(activities.revenue_amount), 0) AS revenue`
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetTaskDependencies godoc
+// @Summary Get task dependencies
+// @Description Get the tasks a task depends on
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} utils.Response{data=[]models.TaskDependency}
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/dependencies [get]
+func GetTaskDependencies(c *gin.Context) {
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	if !policy.FromContext(c).CanViewTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	var dependencies []models.TaskDependency
+	config.DB.Preload("DependsOn").Where("task_id = ?", task.ID).Find(&dependencies)
+
+	utils.SuccessResponse(c, 200, "Task dependencies retrieved successfully", dependencies)
+}
+
+// SetTaskDependencies godoc
+// @Summary Set task dependencies
+// @Description Replace the tasks a task depends on. They must belong to the same project and must not create a cycle.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param dependencies body SetTaskDependenciesRequest true "Tasks it depends on"
+// @Success 200 {object} utils.Response{data=[]models.TaskDependency}
+// @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tasks/{id}/dependencies [put]
+func SetTaskDependencies(c *gin.Context) {
+	var req models.SetTaskDependenciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var task models.Task
+	if err := config.DB.Preload("Project").First(&task, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Task not found")
+		return
+	}
+	if !policy.FromContext(c).CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
+	}
+
+	dependsOn := uniqueIDs(req.DependsOnTaskIDs)
+	for _, id := range dependsOn {
+		if id == task.ID {
+			utils.ErrorResponse(c, 400, "A task cannot depend on itself")
+			return
+		}
+	}
+	if len(dependsOn) > 0 {
+		var count int64
+		config.DB.Model(&models.Task{}).Where("id IN ? AND project_id = ?", dependsOn, task.ProjectID).Count(&count)
+		if int(count) != len(dependsOn) {
+			utils.ErrorResponse(c, 400, "Dependencies must be tasks of the same project")
+			return
+		}
+	}
+
+	// The project's dependency graph with the new edges must stay acyclic
+	var existing []models.TaskDependency
+	config.DB.Joins("JOIN tasks ON tasks.id = task_dependencies.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.project_id = ? AND task_dependencies.task_id <> ?", task.ProjectID, task.ID).
+		Find(&existing)
+	graph := map[uint][]uint{task.ID: dependsOn}
+	for _, d := range existing {
+		graph[d.TaskID] = append(graph[d.TaskID], d.DependsOnTaskID)
+	}
+	if models.HasDependencyCycle(graph) {
+		utils.ErrorResponse(c, 400, "The dependencies would create a cycle")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskDependency{}).Error; err != nil {
+			return err
+		}
+		for _, id := range dependsOn {
+			if err := tx.Create(&models.TaskDependency{TaskID: task.ID, DependsOnTaskID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update task dependencies")
+		return
+	}
+
+	var dependencies []models.TaskDependency
+	config.DB.Preload("DependsOn").Where("task_id = ?", task.ID).Find(&dependencies)
+
+	utils.SuccessResponse(c, 200, "Task dependencies updated successfully", dependencies)
+}
+
+// uniqueIDs returns the IDs without zeros and duplicates, keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
 )
 
 // GetTasks godoc
@@ -24,46 +26,30 @@ import (
 // @Failure 401 {object} utils.Response
 // @Router /tasks [get]
 func GetTasks(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
-
-	query := config.DB.Preload("Project").Preload("Project.Area").Preload("AssignedUser").Preload("Creator")
-
-	role := userRole.(models.Role)
-
-	// Apply role-based filters
-	if role == models.RoleUser {
-		// Regular users only see tasks assigned to them or unassigned tasks from their projects
-		query = query.Where("assigned_user_id = ? OR (assigned_user_id IS NULL AND project_id IN (SELECT id FROM projects WHERE assigned_user_id = ?))", userID, userID)
-	} else if role == models.RoleAdmin {
-		// Admins see tasks from projects in their area
-		if userAreaID != nil {
-			query = query.Joins("JOIN projects ON tasks.project_id = projects.id").
-				Where("projects.area_id = ?", userAreaID)
-		}
-	}
-	// SuperAdmin sees all tasks
+	query := config.DB.Preload("Project").Preload("Project.Area").Preload("AssignedUsers").Preload("Creator")
+
+	// Restrict to tasks the user can see (area permissions, assignments and personal projects)
+	query = policy.FromContext(c).ScopeTasks(query)
 
 	// Apply query filters
 	if projectIDStr := c.Query("project_id"); projectIDStr != "" {
 		if projectID, err := strconv.ParseUint(projectIDStr, 10, 32); err == nil {
-			query = query.Where("project_id = ?", uint(projectID))
+			query = query.Where("tasks.project_id = ?", uint(projectID))
 		}
 	}
 
 	if assignedUserIDStr := c.Query("assigned_user_id"); assignedUserIDStr != "" {
 		if assignedUserID, err := strconv.ParseUint(assignedUserIDStr, 10, 32); err == nil {
-			query = query.Where("assigned_user_id = ?", uint(assignedUserID))
+			query = query.Where("tasks.id IN (SELECT task_id FROM task_assignments WHERE user_id = ? AND is_active = ?)", uint(assignedUserID), true)
 		}
 	}
 
 	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+		query = query.Where("tasks.status = ?", status)
 	}
 
 	if priority := c.Query("priority"); priority != "" {
-		query = query.Where("priority = ?", priority)
+		query = query.Where("tasks.priority = ?", priority)
 	}
 
 	var tasks []models.Task
@@ -88,12 +74,9 @@ func GetTasks(c *gin.Context) {
 // @Router /tasks/{id} [get]
 func GetTask(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var task models.Task
-	query := config.DB.Preload("Project").Preload("Project.Area").Preload("AssignedUser").Preload("Creator")
+	query := config.DB.Preload("Project").Preload("Project.Area").Preload("AssignedUsers").Preload("Creator").Preload("RequiredSkills.Skill").Preload("Dependencies")
 
 	if err := query.First(&task, id).Error; err != nil {
 		utils.ErrorResponse(c, 404, "Task not found")
@@ -101,32 +84,9 @@ func GetTask(c *gin.Context) {
 	}
 
 	// Check access permissions
-	role := userRole.(models.Role)
-	if role == models.RoleUser {
-		// Users can only see tasks assigned to them
-		var assignment models.TaskAssignment
-		err := config.DB.Where("task_id = ? AND user_id = ? AND is_active = ?", task.ID, userID.(uint), true).First(&assignment).Error
-		if err != nil {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
-	}
-	if role == models.RoleAdmin {
-		// Load project to check area
-		var project models.Project
-		if err := config.DB.First(&project, task.ProjectID).Error; err != nil {
-			utils.ErrorResponse(c, 500, "Failed to verify permissions")
-			return
-		}
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if project.AreaID == nil || *project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
+	if !policy.FromContext(c).CanViewTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
 	}
 
 	utils.SuccessResponse(c, 200, "Task retrieved successfully", task)
@@ -134,7 +94,8 @@ func GetTask(c *gin.Context) {
 
 // CreateTask godoc
 // @Summary Create new task
-// @Description Create a new task within a project. Admins can create tasks for their area's projects, SuperAdmins for any project.
+// @Description Create a new task within a project. Admins can create tasks for their area's projects, SuperAdmins for any project,
+// @Description and the project's owners and managers for that project. Project viewers cannot be assigned tasks.
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -153,8 +114,6 @@ func CreateTask(c *gin.Context) {
 	}
 
 	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	// Verify project exists and user has access
 	var project models.Project
@@ -164,33 +123,23 @@ func CreateTask(c *gin.Context) {
 	}
 
 	// Check permissions
-	role := userRole.(models.Role)
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if project.AreaID == nil || *project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Cannot create task for project outside your area")
-			return
-		}
+	if !policy.FromContext(c).CanManageTasksIn(&project) {
+		utils.ErrorResponse(c, 403, "Cannot create task for this project")
+		return
 	}
 
-	// If assigning to a user, verify the user exists and has access to the project's area
+	// If assigning to a user, verify the user exists and can work on the project
 	if req.AssignedUserID != nil {
-		var assignedUser models.User
-		if err := config.DB.First(&assignedUser, *req.AssignedUserID).Error; err != nil {
-			utils.ErrorResponse(c, 404, "Assigned user not found")
+		if code, msg := checkTaskAssignee(&project, *req.AssignedUserID); msg != "" {
+			utils.ErrorResponse(c, code, msg)
 			return
 		}
+	}
 
-		// Verify user belongs to the project's area (if project has an area)
-		if project.AreaID != nil {
-			if assignedUser.AreaID == nil || *assignedUser.AreaID != *project.AreaID {
-				utils.ErrorResponse(c, 400, "Assigned user must belong to the project's area")
-				return
-			}
+	if req.MilestoneID != nil {
+		if msg := checkTaskMilestone(*req.MilestoneID, project.ID); msg != "" {
+			utils.ErrorResponse(c, 400, msg)
+			return
 		}
 	}
 
@@ -203,6 +152,8 @@ func CreateTask(c *gin.Context) {
 		EstimatedHours: req.EstimatedHours,
 		Order:          req.Order,
 		Status:         models.TaskStatusBacklog,
+		MilestoneID:    req.MilestoneID,
+		IsActive:       true,
 	}
 
 	// Parse due date if provided
@@ -220,13 +171,22 @@ func CreateTask(c *gin.Context) {
 		task.Status = models.TaskStatusAssigned
 	}
 
-	if err := config.DB.Create(&task).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if req.AssignedUserID != nil {
+			return assignTaskTo(tx, task.ID, *req.AssignedUserID, userID.(uint))
+		}
+		return nil
+	})
+	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to create task")
 		return
 	}
 
 	// Reload with relations
-	config.DB.Preload("Project").Preload("AssignedUser").Preload("Creator").First(&task, task.ID)
+	config.DB.Preload("Project").Preload("AssignedUsers").Preload("Creator").First(&task, task.ID)
 
 	utils.SuccessResponse(c, 201, "Task created successfully", task)
 }
@@ -247,8 +207,6 @@ func CreateTask(c *gin.Context) {
 // @Router /tasks/{id} [put]
 func UpdateTask(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var task models.Task
 	if err := config.DB.Preload("Project").First(&task, id).Error; err != nil {
@@ -257,17 +215,9 @@ func UpdateTask(c *gin.Context) {
 	}
 
 	// Check permissions
-	role := userRole.(models.Role)
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if task.Project.AreaID == nil || *task.Project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
+	if !policy.FromContext(c).CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
+		return
 	}
 
 	var req models.UpdateTaskRequest
@@ -303,53 +253,63 @@ func UpdateTask(c *gin.Context) {
 		}
 		task.DueDate = &dueDate
 	}
-
-	// Handle assignment changes
-	if req.AssignedUserID != nil {
-		if *req.AssignedUserID == 0 {
-			// Deactivate all task assignments
-			config.DB.Model(&models.TaskAssignment{}).Where("task_id = ?", task.ID).Update("is_active", false)
-			if task.Status == models.TaskStatusAssigned {
-				task.Status = models.TaskStatusBacklog
-			}
+	if req.MilestoneID != nil {
+		if *req.MilestoneID == 0 {
+			task.MilestoneID = nil
 		} else {
-			// Verify new assigned user
-			var assignedUser models.User
-			if err := config.DB.First(&assignedUser, *req.AssignedUserID).Error; err != nil {
-				utils.ErrorResponse(c, 404, "Assigned user not found")
+			if msg := checkTaskMilestone(*req.MilestoneID, task.ProjectID); msg != "" {
+				utils.ErrorResponse(c, 400, msg)
 				return
 			}
+			task.MilestoneID = req.MilestoneID
+		}
+	}
 
-			// Verify user belongs to the project's area
-			if task.Project.AreaID != nil {
-				if assignedUser.AreaID == nil || *assignedUser.AreaID != *task.Project.AreaID {
-					utils.ErrorResponse(c, 400, "Assigned user must belong to the project's area")
-					return
-				}
-			}
+	// Handle assignment changes: the new assignee replaces the current ones, 0 unassigns everyone
+	if req.AssignedUserID != nil && *req.AssignedUserID != 0 {
+		if code, msg := checkTaskAssignee(&task.Project, *req.AssignedUserID); msg != "" {
+			utils.ErrorResponse(c, code, msg)
+			return
+		}
+	}
+	if req.AssignedUserID != nil {
+		if *req.AssignedUserID == 0 && task.Status == models.TaskStatusAssigned {
+			task.Status = models.TaskStatusBacklog
+		} else if *req.AssignedUserID != 0 && task.Status == models.TaskStatusBacklog {
+			task.Status = models.TaskStatusAssigned
+		}
+	}
 
-			// Deactivate old assignments and create new one
-			config.DB.Model(&models.TaskAssignment{}).Where("task_id = ?", task.ID).Update("is_active", false)
-			assignment := models.TaskAssignment{
-				TaskID:   task.ID,
-				UserID:   *req.AssignedUserID,
-				IsActive: true,
+	currentUserID := c.MustGet("user_id").(uint)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		if req.AssignedUserID == nil {
+			return nil
+		}
+		var currentIDs []uint
+		tx.Model(&models.TaskAssignment{}).Where("task_id = ? AND is_active = ?", task.ID, true).Pluck("user_id", &currentIDs)
+		for _, assigneeID := range currentIDs {
+			if assigneeID == *req.AssignedUserID {
+				continue
 			}
-			config.DB.Create(&assignment)
-
-			if task.Status == models.TaskStatusBacklog {
-				task.Status = models.TaskStatusAssigned
+			if err := unassignTaskFrom(tx, task.ID, assigneeID, currentUserID); err != nil {
+				return err
 			}
 		}
-	}
-
-	if err := config.DB.Save(&task).Error; err != nil {
+		if *req.AssignedUserID == 0 {
+			return nil
+		}
+		return assignTaskTo(tx, task.ID, *req.AssignedUserID, currentUserID)
+	})
+	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update task")
 		return
 	}
 
 	// Reload with relations
-	config.DB.Preload("Project").Preload("AssignedUser").Preload("Creator").First(&task, task.ID)
+	config.DB.Preload("Project").Preload("AssignedUsers").Preload("Creator").First(&task, task.ID)
 
 	utils.SuccessResponse(c, 200, "Task updated successfully", task)
 }
@@ -370,9 +330,6 @@ func UpdateTask(c *gin.Context) {
 // @Router /tasks/{id}/status [patch]
 func UpdateTaskStatus(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var task models.Task
 	if err := config.DB.Preload("Project").First(&task, id).Error; err != nil {
@@ -380,27 +337,8 @@ func UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	// Check permissions
-	role := userRole.(models.Role)
-	canUpdate := false
-
-	if role == models.RoleSuperAdmin {
-		canUpdate = true
-	} else if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if ok && areaID != nil && task.Project.AreaID != nil && *task.Project.AreaID == *areaID {
-			canUpdate = true
-		}
-	} else if role == models.RoleUser {
-		// Users can update status of their own tasks
-		var assignment models.TaskAssignment
-		err := config.DB.Where("task_id = ? AND user_id = ? AND is_active = ?", task.ID, userID.(uint), true).First(&assignment).Error
-		if err == nil {
-			canUpdate = true
-		}
-	}
-
-	if !canUpdate {
+	// Check permissions: task managers of the area or users assigned to the task
+	if !policy.FromContext(c).CanUpdateTaskStatus(&task) {
 		utils.ErrorResponse(c, 403, "Access denied")
 		return
 	}
@@ -419,7 +357,7 @@ func UpdateTaskStatus(c *gin.Context) {
 	}
 
 	// Reload with relations
-	config.DB.Preload("Project").Preload("AssignedUser").Preload("Creator").First(&task, task.ID)
+	config.DB.Preload("Project").Preload("AssignedUsers").Preload("Creator").First(&task, task.ID)
 
 	utils.SuccessResponse(c, 200, "Task status updated successfully", task)
 }
@@ -443,8 +381,7 @@ func BulkUpdateTaskOrder(c *gin.Context) {
 		return
 	}
 
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
+	subject := policy.FromContext(c)
 
 	// Update each task's order
 	for _, taskUpdate := range req.Tasks {
@@ -454,12 +391,8 @@ func BulkUpdateTaskOrder(c *gin.Context) {
 		}
 
 		// Check permissions
-		role := userRole.(models.Role)
-		if role == models.RoleAdmin {
-			areaID, ok := userAreaID.(*uint)
-			if !ok || areaID == nil || task.Project.AreaID == nil || *task.Project.AreaID != *areaID {
-				continue // Skip tasks user can't access
-			}
+		if !subject.CanManageTask(&task) {
+			continue // Skip tasks user can't manage
 		}
 
 		task.Order = taskUpdate.Order
@@ -471,7 +404,7 @@ func BulkUpdateTaskOrder(c *gin.Context) {
 
 // DeleteTask godoc
 // @Summary Delete task
-// @Description Soft delete a task (Admin and SuperAdmin only)
+// @Description Soft delete a task (requires tasks.manage on the project's area)
 // @Tags tasks
 // @Security BearerAuth
 // @Param id path int true "Task ID"
@@ -481,8 +414,6 @@ func BulkUpdateTaskOrder(c *gin.Context) {
 // @Router /tasks/{id} [delete]
 func DeleteTask(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var task models.Task
 	if err := config.DB.Preload("Project").First(&task, id).Error; err != nil {
@@ -490,20 +421,9 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	// Check permissions - only Admins and SuperAdmins can delete tasks
-	role := userRole.(models.Role)
-	if role == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if task.Project.AreaID == nil || *task.Project.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Access denied")
-			return
-		}
-	} else if role == models.RoleUser {
-		utils.ErrorResponse(c, 403, "Only administrators can delete tasks")
+	// Check permissions - only task managers of the area can delete tasks
+	if !policy.FromContext(c).CanManageTask(&task) {
+		utils.ErrorResponse(c, 403, "Access denied")
 		return
 	}
 
@@ -511,6 +431,7 @@ func DeleteTask(c *gin.Context) {
 		utils.ErrorResponse(c, 500, "Failed to delete task")
 		return
 	}
+	config.DB.Where("task_id = ? OR depends_on_task_id = ?", task.ID, task.ID).Delete(&models.TaskDependency{})
 
 	utils.SuccessResponse(c, 200, "Task deleted successfully", nil)
 }
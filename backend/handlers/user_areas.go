@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
+)
+
+// GetUserAreas godoc
+// @Summary Get user areas
+// @Description Get every area a user belongs to, with the role held in each and the primary area flag
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=[]models.UserArea}
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/areas [get]
+func GetUserAreas(c *gin.Context) {
+	var user models.User
+	if err := policy.FromContext(c).ScopeUsers(config.DB).First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	var memberships []models.UserArea
+	if err := config.DB.Preload("Area").Where("user_id = ?", user.ID).Order("is_primary DESC, created_at ASC").Find(&memberships).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve user areas")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User areas retrieved successfully", memberships)
+}
+
+// AddUserArea godoc
+// @Summary Add user to area
+// @Description Add a user to another area with a role for that area. Only global user managers can grant the 'admin' role.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param membership body AddUserAreaRequest true "Area, role and primary flag"
+// @Success 201 {object} utils.Response{data=models.UserArea}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/areas [post]
+func AddUserArea(c *gin.Context) {
+	var req models.AddUserAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, c.Param("id")).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	var area models.Area
+	if err := config.DB.Where("is_active = ?", true).First(&area, req.AreaID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Area not found")
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	subject := policy.FromContext(c)
+	if !subject.Can(models.PermUsersManage, &area.ID) {
+		utils.ErrorResponse(c, 403, "You can only add users to areas you manage")
+		return
+	}
+	if !subject.CanAssignBaseRole(role) {
+		utils.ErrorResponse(c, 403, "You can only add users with 'user' role")
+		return
+	}
+
+	if models.IsAreaMember(config.DB, user.ID, area.ID) {
+		utils.ErrorResponse(c, 400, "User already belongs to this area")
+		return
+	}
+
+	membership := models.UserArea{
+		UserID: user.ID,
+		AreaID: area.ID,
+		Role:   role,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&membership).Error; err != nil {
+			return err
+		}
+		// The first area of a user always becomes the primary one
+		if req.IsPrimary || user.AreaID == nil {
+			return membership.MakePrimary(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to add user to area")
+		return
+	}
+
+	config.DB.Preload("Area").First(&membership, membership.ID)
+
+	utils.SuccessResponse(c, 201, "User added to area successfully", membership)
+}
+
+// UpdateUserArea godoc
+// @Summary Update user area membership
+// @Description Change the role a user holds in an area or make it the user's primary area
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param areaId path int true "Area ID"
+// @Param membership body UpdateUserAreaRequest true "Role and primary flag"
+// @Success 200 {object} utils.Response{data=models.UserArea}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/areas/{areaId} [put]
+func UpdateUserArea(c *gin.Context) {
+	var req models.UpdateUserAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	membership, ok := loadManagedUserArea(c)
+	if !ok {
+		return
+	}
+
+	if req.Role != "" && req.Role != membership.Role {
+		subject := policy.FromContext(c)
+		if !subject.CanAssignBaseRole(req.Role) || !subject.CanAssignBaseRole(membership.Role) {
+			utils.ErrorResponse(c, 403, "You cannot change the role users hold in an area")
+			return
+		}
+		membership.Role = req.Role
+	}
+	if req.IsPrimary != nil && !*req.IsPrimary && membership.IsPrimary {
+		utils.ErrorResponse(c, 400, "Make another area primary instead")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(membership).Update("role", membership.Role).Error; err != nil {
+			return err
+		}
+		if req.IsPrimary != nil && *req.IsPrimary {
+			return membership.MakePrimary(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to update user area")
+		return
+	}
+
+	config.DB.Preload("Area").First(membership, membership.ID)
+
+	utils.SuccessResponse(c, 200, "User area updated successfully", membership)
+}
+
+// RemoveUserArea godoc
+// @Summary Remove user from area
+// @Description Remove a user from an area. The primary area cannot be removed until another area is made primary.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param areaId path int true "Area ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/areas/{areaId} [delete]
+func RemoveUserArea(c *gin.Context) {
+	membership, ok := loadManagedUserArea(c)
+	if !ok {
+		return
+	}
+
+	if membership.IsPrimary {
+		utils.ErrorResponse(c, 400, "Cannot remove the primary area. Make another area primary first")
+		return
+	}
+
+	if err := config.DB.Delete(membership).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to remove user from area")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User removed from area successfully", nil)
+}
+
+// loadManagedUserArea loads the membership from the :id and :areaId params and checks the caller manages that area
+func loadManagedUserArea(c *gin.Context) (*models.UserArea, bool) {
+	var membership models.UserArea
+	if err := config.DB.Where("user_id = ? AND area_id = ?", c.Param("id"), c.Param("areaId")).First(&membership).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User does not belong to this area")
+		return nil, false
+	}
+
+	if !policy.FromContext(c).Can(models.PermUsersManage, &membership.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only manage memberships in areas you manage")
+		return nil, false
+	}
+
+	return &membership, true
+}
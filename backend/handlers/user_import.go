@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
+	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	maxUserImportSize = 2 << 20 // 2 MB
+	maxUserImportRows = 1000
+)
+
+// userImportColumns maps accepted CSV headers to the column they fill
+var userImportColumns = map[string]string{
+	"email":         "email",
+	"full_name":     "full_name",
+	"name":          "full_name",
+	"role":          "role",
+	"area":          "area",
+	"area_id":       "area",
+	"work_schedule": "work_schedule",
+	"password":      "password",
+}
+
+// userImportRecord is a validated CSV line ready to be applied
+type userImportRecord struct {
+	row          *models.UserImportRow
+	fullName     string
+	password     string
+	role         models.Role
+	areaID       *uint
+	workSchedule datatypes.JSON
+	existing     *models.User
+}
+
+// ImportUsers godoc
+// @Summary Import users from CSV
+// @Description Create or update users from a CSV file, matching existing users by email.
+// @Description Columns: email (required), full_name, role, area (ID or name), work_schedule (JSON) and password (required for new users).
+// @Description The same rules as creating users apply: area managers can only import 'user' accounts into their own areas.
+// @Description With dry_run=true the file is only validated. Otherwise nothing is written unless every row is valid.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file with a header row"
+// @Param dry_run query bool false "Validate without saving"
+// @Success 200 {object} utils.Response{data=models.UserImportReport}
+// @Failure 400 {object} utils.Response{data=models.UserImportReport}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /users/import [post]
+func ImportUsers(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, 400, "CSV file is required in the 'file' field")
+		return
+	}
+	if file.Size > maxUserImportSize {
+		utils.ErrorResponse(c, 400, "CSV file is too large (max 2 MB)")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		utils.ErrorResponse(c, 400, "Failed to read CSV file")
+		return
+	}
+	defer f.Close()
+
+	columns, lines, err := readUserImportCSV(f)
+	if err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var areas []models.Area
+	config.DB.Find(&areas)
+
+	records, report := validateUserImport(policy.FromContext(c), areas, findImportUser, columns, lines)
+	report.DryRun = dryRun
+
+	if report.Failed > 0 {
+		if dryRun {
+			utils.SuccessResponse(c, 200, "CSV validated with errors", report)
+			return
+		}
+		utils.ValidationErrorResponse(c, report)
+		return
+	}
+	if dryRun {
+		utils.SuccessResponse(c, 200, "CSV validated successfully", report)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if err := applyUserImport(tx, record); err != nil {
+				return fmt.Errorf("line %d: %w", record.row.Line, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to import users: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Users imported successfully", report)
+}
+
+// readUserImportCSV parses the header and data lines, returning the column index of each known field
+func readUserImportCSV(r io.Reader) (map[string]int, [][]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("CSV file is empty or invalid")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		column, ok := userImportColumns[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("Unknown CSV column '%s'", name)
+		}
+		if _, dup := columns[column]; dup {
+			return nil, nil, fmt.Errorf("Duplicated CSV column '%s'", name)
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, nil, errors.New("CSV must have an 'email' column")
+	}
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid CSV: %v", err)
+	}
+	if len(lines) == 0 {
+		return nil, nil, errors.New("CSV file has no data rows")
+	}
+	if len(lines) > maxUserImportRows {
+		return nil, nil, fmt.Errorf("CSV file has more than %d rows", maxUserImportRows)
+	}
+	return columns, lines, nil
+}
+
+// userImportPolicy is what validateUserImport asks the caller's policy subject
+type userImportPolicy interface {
+	Can(p models.Permission, areaID *uint) bool
+	IsGlobal(p models.Permission) bool
+	CanManageUser(user *models.User) bool
+	CanAssignBaseRole(role models.Role) bool
+}
+
+// findImportUser looks up the user with an email, including deleted users
+func findImportUser(email string) (*models.User, error) {
+	var user models.User
+	if err := config.DB.Unscoped().Where("LOWER(email) = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// validateUserImport checks every line against the rules of CreateUser and UpdateUser. areas are every
+// existing area and findUser looks up users by email, returning gorm.ErrRecordNotFound for new ones.
+func validateUserImport(subject userImportPolicy, areas []models.Area, findUser func(email string) (*models.User, error),
+	columns map[string]int, lines [][]string) ([]*userImportRecord, *models.UserImportReport) {
+	areasByName := make(map[string]uint, len(areas))
+	areaIDs := make(map[uint]bool, len(areas))
+	for _, area := range areas {
+		areasByName[strings.ToLower(area.Name)] = area.ID
+		areaIDs[area.ID] = true
+	}
+
+	report := &models.UserImportReport{Rows: make([]models.UserImportRow, len(lines))}
+	records := make([]*userImportRecord, 0, len(lines))
+	seen := make(map[string]int)
+
+	for i, line := range lines {
+		value := func(column string) string {
+			idx, ok := columns[column]
+			if !ok || idx >= len(line) {
+				return ""
+			}
+			return strings.TrimSpace(line[idx])
+		}
+
+		row := &report.Rows[i]
+		row.Line = i + 2
+		row.Email = strings.ToLower(value("email"))
+		record := &userImportRecord{
+			row:      row,
+			fullName: value("full_name"),
+			password: value("password"),
+			role:     models.Role(strings.ToLower(value("role"))),
+		}
+		fail := func(format string, args ...interface{}) {
+			row.Errors = append(row.Errors, fmt.Sprintf(format, args...))
+		}
+
+		if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+			fail("Invalid email")
+		} else if first, dup := seen[row.Email]; dup {
+			fail("Email already appears on line %d", first)
+		} else {
+			seen[row.Email] = row.Line
+		}
+
+		if record.role != "" && record.role != models.RoleUser && record.role != models.RoleAdmin && record.role != models.RoleSuperAdmin {
+			fail("Invalid role '%s'", record.role)
+		}
+		if record.password != "" && len(record.password) < 6 {
+			fail("Password must be at least 6 characters")
+		}
+
+		if area := value("area"); area != "" {
+			if id, err := strconv.ParseUint(area, 10, 64); err == nil && areaIDs[uint(id)] {
+				areaID := uint(id)
+				record.areaID = &areaID
+			} else if id, ok := areasByName[strings.ToLower(area)]; ok {
+				record.areaID = &id
+			} else {
+				fail("Area '%s' not found", area)
+			}
+		}
+
+		if schedule := value("work_schedule"); schedule != "" {
+			if !json.Valid([]byte(schedule)) {
+				fail("work_schedule must be valid JSON")
+			} else {
+				record.workSchedule = datatypes.JSON(schedule)
+			}
+		}
+
+		if len(row.Errors) == 0 {
+			existing, err := findUser(row.Email)
+			switch {
+			case err == nil && existing.DeletedAt.Valid:
+				fail("Email belongs to a deleted user")
+			case err == nil:
+				record.existing = existing
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				fail("Failed to look up user")
+			}
+		}
+
+		if len(row.Errors) == 0 {
+			if record.existing != nil {
+				row.Action = models.UserImportUpdate
+				row.UserID = &record.existing.ID
+				// Same rules as UpdateUser
+				if !subject.CanManageUser(record.existing) {
+					fail("Cannot update users from other areas")
+				}
+				if record.areaID != nil && !subject.Can(models.PermUsersManage, record.areaID) {
+					fail("Cannot move users to areas you don't manage")
+				}
+				if record.role != "" && record.role != record.existing.Role && !subject.IsGlobal(models.PermUsersManage) {
+					fail("You cannot change user roles")
+				}
+			} else {
+				row.Action = models.UserImportCreate
+				if record.role == "" {
+					record.role = models.RoleUser
+				}
+				// Same rules as CreateUser
+				if record.fullName == "" {
+					fail("full_name is required for new users")
+				}
+				if record.password == "" {
+					fail("password is required for new users")
+				}
+				if !subject.CanAssignBaseRole(record.role) {
+					fail("You can only create users with 'user' role")
+				}
+				if !subject.Can(models.PermUsersManage, record.areaID) {
+					fail("You can only create users in areas you manage")
+				}
+			}
+		}
+
+		report.Total++
+		if len(row.Errors) > 0 {
+			report.Failed++
+			continue
+		}
+		if row.Action == models.UserImportCreate {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+		records = append(records, record)
+	}
+
+	return records, report
+}
+
+// applyUserImport creates or updates the user of a validated record
+func applyUserImport(tx *gorm.DB, record *userImportRecord) error {
+	if record.existing == nil {
+		user := models.User{
+			Email:        record.row.Email,
+			Password:     record.password,
+			FullName:     record.fullName,
+			Role:         record.role,
+			AreaID:       record.areaID,
+			WorkSchedule: record.workSchedule,
+			IsActive:     true,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		record.row.UserID = &user.ID
+		return nil
+	}
+
+	user := record.existing
+	if record.fullName != "" {
+		user.FullName = record.fullName
+	}
+	if record.password != "" {
+		if err := user.SetPassword(record.password); err != nil {
+			return err
+		}
+	}
+	if record.role != "" {
+		user.Role = record.role
+	}
+	if record.areaID != nil {
+		user.AreaID = record.areaID
+	}
+	if record.workSchedule != nil {
+		user.WorkSchedule = record.workSchedule
+	}
+
+	if err := tx.Save(user).Error; err != nil {
+		return err
+	}
+	// Keep the primary membership in line with the new area or role
+	if record.areaID != nil || record.role != "" {
+		return models.SyncPrimaryArea(tx, user)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jaliko05/time-flow/models"
+	"gorm.io/gorm"
+)
+
+// importSubject holds users.manage in some areas, or in every area when global,
+// following the same base role rules as policy.Subject
+type importSubject struct {
+	global bool
+	areas  map[uint]bool
+}
+
+func (s importSubject) Can(p models.Permission, areaID *uint) bool {
+	return p == models.PermUsersManage && (s.global || areaID != nil && s.areas[*areaID])
+}
+
+func (s importSubject) IsGlobal(p models.Permission) bool {
+	return p == models.PermUsersManage && s.global
+}
+
+func (s importSubject) CanManageUser(user *models.User) bool {
+	return s.Can(models.PermUsersManage, user.AreaID)
+}
+
+func (s importSubject) CanAssignBaseRole(role models.Role) bool {
+	return role == models.RoleUser || s.IsGlobal(models.PermUsersManage)
+}
+
+var (
+	importAreas      = []models.Area{{ID: 1, Name: "Engineering"}, {ID: 2, Name: "Sales"}}
+	importAreaAdmin  = importSubject{areas: map[uint]bool{1: true}}
+	importSuperAdmin = importSubject{global: true}
+)
+
+func importArea(id uint) *uint {
+	return &id
+}
+
+// importUsers returns a findUser lookup over the given users
+func importUsers(users ...models.User) func(string) (*models.User, error) {
+	return func(email string) (*models.User, error) {
+		for i := range users {
+			if users[i].Email == email {
+				return &users[i], nil
+			}
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+}
+
+func validateImportCSV(t *testing.T, subject userImportPolicy, findUser func(string) (*models.User, error), csv string) ([]*userImportRecord, *models.UserImportReport) {
+	t.Helper()
+	columns, lines, err := readUserImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("readUserImportCSV() error: %v", err)
+	}
+	return validateUserImport(subject, importAreas, findUser, columns, lines)
+}
+
+func TestReadUserImportCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		wantColumns map[string]int
+		wantLines   int
+		wantErr     string
+	}{
+		{
+			name:        "aliases and byte order mark",
+			csv:         "\ufeffEmail, Name ,area_id\nana@example.com,Ana,1\nluis@example.com,Luis,2\n",
+			wantColumns: map[string]int{"email": 0, "full_name": 1, "area": 2},
+			wantLines:   2,
+		},
+		{name: "unknown column", csv: "email,phone\nana@example.com,555\n", wantErr: "Unknown CSV column 'phone'"},
+		{name: "duplicated column", csv: "email,name,full_name\nana@example.com,Ana,Ana\n", wantErr: "Duplicated CSV column 'full_name'"},
+		{name: "missing email", csv: "full_name\nAna\n", wantErr: "CSV must have an 'email' column"},
+		{name: "no data rows", csv: "email,full_name\n", wantErr: "CSV file has no data rows"},
+		{name: "empty file", csv: "", wantErr: "CSV file is empty or invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, lines, err := readUserImportCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("readUserImportCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readUserImportCSV() error: %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("columns = %v, want %v", columns, tt.wantColumns)
+			}
+			if len(lines) != tt.wantLines {
+				t.Errorf("got %d lines, want %d", len(lines), tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestValidateUserImportReport(t *testing.T) {
+	existing := models.User{ID: 10, Email: "eva@example.com", FullName: "Eva", Role: models.RoleUser, AreaID: importArea(1)}
+	deleted := models.User{ID: 11, Email: "old@example.com", Role: models.RoleUser, AreaID: importArea(1),
+		DeletedAt: gorm.DeletedAt{Valid: true}}
+
+	csv := "email,full_name,area,password,work_schedule\n" +
+		"Ana@Example.com,Ana,engineering,secret1,\n" + // line 2: created
+		"eva@example.com,Eva Ruiz,1,,\n" + // line 3: updated
+		"not-an-email,Nobody,1,secret1,\n" + // line 4
+		"ana@example.com,Ana again,1,secret1,\n" + // line 5
+		"luis@example.com,Luis,1,,\n" + // line 6
+		"old@example.com,Old,1,secret1,\n" + // line 7
+		"mia@example.com,Mia,Marketing,secret1,\n" + // line 8
+		"leo@example.com,Leo,1,123,{bad\n" // line 9
+
+	records, report := validateImportCSV(t, importSuperAdmin, importUsers(existing, deleted), csv)
+
+	if report.Total != 8 || report.Created != 1 || report.Updated != 1 || report.Failed != 6 {
+		t.Errorf("report totals = %d/%d/%d/%d (total/created/updated/failed), want 8/1/1/6",
+			report.Total, report.Created, report.Updated, report.Failed)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d valid records, want 2", len(records))
+	}
+	if records[0].areaID == nil || *records[0].areaID != 1 || records[0].role != models.RoleUser {
+		t.Errorf("new user record = area %v role %q, want area 1 by name and the user role", records[0].areaID, records[0].role)
+	}
+	if records[1].existing == nil || records[1].existing.ID != existing.ID {
+		t.Errorf("update record does not point to the existing user")
+	}
+
+	wantRows := []struct {
+		email  string
+		action string
+		errors []string
+	}{
+		{"ana@example.com", models.UserImportCreate, nil},
+		{"eva@example.com", models.UserImportUpdate, nil},
+		{"not-an-email", "", []string{"Invalid email"}},
+		{"ana@example.com", "", []string{"Email already appears on line 2"}},
+		{"luis@example.com", models.UserImportCreate, []string{"password is required for new users"}},
+		{"old@example.com", "", []string{"Email belongs to a deleted user"}},
+		{"mia@example.com", "", []string{"Area 'Marketing' not found"}},
+		{"leo@example.com", "", []string{"Password must be at least 6 characters", "work_schedule must be valid JSON"}},
+	}
+	for i, want := range wantRows {
+		row := report.Rows[i]
+		if row.Line != i+2 || row.Email != want.email || row.Action != want.action || !reflect.DeepEqual(row.Errors, want.errors) {
+			t.Errorf("row %d = line %d %q action %q errors %v, want line %d %q action %q errors %v",
+				i, row.Line, row.Email, row.Action, row.Errors, i+2, want.email, want.action, want.errors)
+		}
+	}
+	if report.Rows[1].UserID == nil || *report.Rows[1].UserID != existing.ID {
+		t.Errorf("update row user_id = %v, want %d", report.Rows[1].UserID, existing.ID)
+	}
+}
+
+func TestValidateUserImportPermissions(t *testing.T) {
+	existing := models.User{ID: 20, Email: "eva@example.com", Role: models.RoleUser, AreaID: importArea(1)}
+	other := models.User{ID: 21, Email: "sam@example.com", Role: models.RoleUser, AreaID: importArea(2)}
+	findUser := importUsers(existing, other)
+
+	tests := []struct {
+		name    string
+		subject importSubject
+		line    string
+		wantErr string
+	}{
+		{"admin creates a user", importAreaAdmin, "new@example.com,New,user,1", ""},
+		{"admin creates without a role", importAreaAdmin, "new@example.com,New,,1", ""},
+		{"admin cannot create an admin", importAreaAdmin, "new@example.com,New,admin,1", "You can only create users with 'user' role"},
+		{"admin cannot create a superadmin", importAreaAdmin, "new@example.com,New,superadmin,1", "You can only create users with 'user' role"},
+		{"admin cannot create in another area", importAreaAdmin, "new@example.com,New,user,2", "You can only create users in areas you manage"},
+		{"admin cannot create without an area", importAreaAdmin, "new@example.com,New,user,", "You can only create users in areas you manage"},
+		{"admin cannot promote", importAreaAdmin, "eva@example.com,Eva,admin,", "You cannot change user roles"},
+		{"admin cannot update other areas", importAreaAdmin, "sam@example.com,Sam,,", "Cannot update users from other areas"},
+		{"admin cannot move users away", importAreaAdmin, "eva@example.com,Eva,,2", "Cannot move users to areas you don't manage"},
+		{"superadmin creates an admin", importSuperAdmin, "new@example.com,New,admin,2", ""},
+		{"superadmin creates without an area", importSuperAdmin, "new@example.com,New,superadmin,", ""},
+		{"superadmin promotes", importSuperAdmin, "sam@example.com,Sam,admin,", ""},
+		{"invalid role", importSuperAdmin, "new@example.com,New,owner,1", "Invalid role 'owner'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report := validateImportCSV(t, tt.subject, findUser, "email,full_name,role,area,password\n"+tt.line+",secret1\n")
+			errs := report.Rows[0].Errors
+			if tt.wantErr == "" {
+				if len(errs) > 0 || report.Failed != 0 {
+					t.Errorf("errors = %v, want none", errs)
+				}
+				return
+			}
+			if !reflect.DeepEqual(errs, []string{tt.wantErr}) || report.Failed != 1 {
+				t.Errorf("errors = %v, want [%s]", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,16 +2,19 @@ package handlers
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
+	"gorm.io/gorm"
 )
 
 // GetUsers godoc
 // @Summary Get all users
-// @Description Get list of users with optional area filter. Admins see only their area, SuperAdmins see all.
+// @Description Get list of users with optional area filter, limited to the areas where the caller holds users.view
 // @Tags users
 // @Produce json
 // @Security BearerAuth
@@ -21,24 +24,12 @@ import (
 // @Failure 403 {object} utils.Response
 // @Router /users [get]
 func GetUsers(c *gin.Context) {
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
+	query := policy.FromContext(c).ScopeUsers(config.DB.Preload("Area").Preload("Areas.Area"))
 
-	query := config.DB.Preload("Area")
-
-	// Apply area filter based on role
-	if userRole == models.RoleAdmin {
-		if userAreaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		query = query.Where("area_id = ?", userAreaID)
-	} else if userRole == models.RoleSuperAdmin {
-		// SuperAdmin can optionally filter by area
-		if areaIDStr := c.Query("area_id"); areaIDStr != "" {
-			if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
-				query = query.Where("area_id = ?", uint(areaID))
-			}
+	// Optional area filter (any member of the area, not only users whose primary area it is)
+	if areaIDStr := c.Query("area_id"); areaIDStr != "" {
+		if areaID, err := strconv.ParseUint(areaIDStr, 10, 32); err == nil {
+			query = whereAreaMember(query, uint(areaID))
 		}
 	}
 
@@ -64,20 +55,10 @@ func GetUsers(c *gin.Context) {
 // @Router /users/{id} [get]
 func GetUser(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var user models.User
-	query := config.DB.Preload("Area")
-
-	// Admin can only see users in their area
-	if userRole == models.RoleAdmin {
-		if userAreaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		query = query.Where("area_id = ?", userAreaID)
-	}
+	// Only users in areas where the caller holds users.view are visible
+	query := policy.FromContext(c).ScopeUsers(config.DB.Preload("Area"))
 
 	if err := query.First(&user, id).Error; err != nil {
 		utils.ErrorResponse(c, 404, "User not found")
@@ -89,7 +70,7 @@ func GetUser(c *gin.Context) {
 
 // CreateUser godoc
 // @Summary Create new user
-// @Description Create a new user. SuperAdmin can create any role in any area. Admin can only create 'user' role in their own area.
+// @Description Create a new user. Holders of the global users.manage permission can create any role in any area; area-scoped holders can only create 'user' role in their areas.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -108,26 +89,15 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
-	userRole, roleExists := c.Get("user_role")
-	if !roleExists {
-		utils.ErrorResponse(c, 401, "Authentication required")
+	// Validate role permissions
+	subject := policy.FromContext(c)
+	if !subject.CanAssignBaseRole(req.Role) {
+		utils.ErrorResponse(c, 403, "You can only create users with 'user' role")
 		return
 	}
-
-	userAreaID, _ := c.Get("user_area_id")
-
-	// Validate role permissions
-	if userRole == models.RoleAdmin {
-		// Admin can only create users in their area and only with role 'user'
-		if req.Role != models.RoleUser {
-			utils.ErrorResponse(c, 403, "Admin can only create users with 'user' role")
-			return
-		}
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil || req.AreaID == nil || *req.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Admin can only create users in their area")
-			return
-		}
+	if !subject.Can(models.PermUsersManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "You can only create users in areas you manage")
+		return
 	}
 
 	// Check if email already exists
@@ -185,8 +155,6 @@ func CreateUser(c *gin.Context) {
 // @Router /users/{id} [put]
 func UpdateUser(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
-	userAreaID, _ := c.Get("user_area_id")
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -200,22 +168,20 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Admin can only update users in their area
-	if userRole == models.RoleAdmin {
-		areaID, ok := userAreaID.(*uint)
-		if !ok || areaID == nil {
-			utils.ErrorResponse(c, 403, "Admin must have an area assigned")
-			return
-		}
-		if user.AreaID == nil || *user.AreaID != *areaID {
-			utils.ErrorResponse(c, 403, "Cannot update users from other areas")
-			return
-		}
-		// Admin cannot change role
-		if req.Role != "" && req.Role != user.Role {
-			utils.ErrorResponse(c, 403, "Admin cannot change user roles")
-			return
-		}
+	// Area-scoped managers can only update users in their areas
+	subject := policy.FromContext(c)
+	if !subject.CanManageUser(&user) {
+		utils.ErrorResponse(c, 403, "Cannot update users from other areas")
+		return
+	}
+	if req.AreaID != nil && !subject.Can(models.PermUsersManage, req.AreaID) {
+		utils.ErrorResponse(c, 403, "Cannot move users to areas you don't manage")
+		return
+	}
+	// Only global user managers can change built-in roles
+	if req.Role != "" && req.Role != user.Role && !subject.IsGlobal(models.PermUsersManage) {
+		utils.ErrorResponse(c, 403, "You cannot change user roles")
+		return
 	}
 
 	// Update fields
@@ -235,7 +201,7 @@ func UpdateUser(c *gin.Context) {
 		user.AreaID = req.AreaID
 	}
 	if req.IsActive != nil {
-		user.IsActive = *req.IsActive
+		user.SetActive(*req.IsActive, models.DeactivatedByAdmin)
 	}
 
 	// Handle JSON fields separately
@@ -252,41 +218,232 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Save(&user).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		// Keep the primary membership in line with the new area or role
+		if req.AreaID != nil || req.Role != "" {
+			return models.SyncPrimaryArea(tx, &user)
+		}
+		return nil
+	})
+	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to update user")
 		return
 	}
 
-	// Reload to get Area relation
-	config.DB.Preload("Area").First(&user, user.ID)
+	// Reload to get Area relations
+	config.DB.Preload("Area").Preload("Areas.Area").First(&user, user.ID)
 
 	utils.SuccessResponse(c, 200, "User updated successfully", user)
 }
 
 // DeleteUser godoc
 // @Summary Delete user
-// @Description Soft delete a user (SuperAdmin only)
+// @Description Offboard (deactivate, revoke sessions, return open work to backlog) and soft delete a user (requires the global users.manage permission)
 // @Tags users
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} utils.Response
+// @Success 200 {object} utils.Response{data=models.OffboardingReport}
 // @Failure 401 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Router /users/{id} [delete]
 func DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
+	subject := policy.FromContext(c)
+	if !subject.IsGlobal(models.PermUsersManage) {
+		utils.ErrorResponse(c, 403, "Insufficient permissions")
+		return
+	}
+
 	var user models.User
 	if err := config.DB.First(&user, id).Error; err != nil {
 		utils.ErrorResponse(c, 404, "User not found")
 		return
 	}
 
-	if err := config.DB.Delete(&user).Error; err != nil {
+	var report *models.OffboardingReport
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if report, err = offboardUser(tx, subject, c.MustGet("user_id").(uint), &user, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
 		utils.ErrorResponse(c, 500, "Failed to delete user")
 		return
 	}
 
-	utils.SuccessResponse(c, 200, "User deleted successfully", nil)
+	utils.SuccessResponse(c, 200, "User deleted successfully", report)
+}
+
+// GetPendingUsers godoc
+// @Summary Get pending accounts
+// @Description Get accounts created through SSO that are waiting for approval (requires users.approve)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by approval status (pending, rejected). Defaults to pending"
+// @Success 200 {object} utils.Response{data=[]models.User}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /users/pending [get]
+func GetPendingUsers(c *gin.Context) {
+	status := models.ApprovalStatus(c.DefaultQuery("status", string(models.ApprovalStatusPending)))
+	if status != models.ApprovalStatusPending && status != models.ApprovalStatusRejected {
+		utils.ErrorResponse(c, 400, "Invalid status. Use pending or rejected")
+		return
+	}
+
+	var users []models.User
+	if err := config.DB.Where("approval_status = ?", status).Order("created_at ASC").Find(&users).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to retrieve pending users")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "Pending users retrieved successfully", users)
+}
+
+// ApproveUser godoc
+// @Summary Approve pending account
+// @Description Approve a pending account, assigning its area and role (requires users.approve)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param approval body ApproveUserRequest true "Area and role for the approved account"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/approve [post]
+func ApproveUser(c *gin.Context) {
+	id := c.Param("id")
+	reviewerID, _ := c.Get("user_id")
+
+	var req models.ApproveUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	if user.ApprovalStatus == models.ApprovalStatusApproved {
+		utils.ErrorResponse(c, 400, "User is already approved")
+		return
+	}
+
+	var area models.Area
+	if err := config.DB.Where("is_active = ?", true).First(&area, *req.AreaID).Error; err != nil {
+		utils.ErrorResponse(c, 404, "Area not found")
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	subject := policy.FromContext(c)
+	if !subject.Can(models.PermUsersApprove, &area.ID) {
+		utils.ErrorResponse(c, 403, "You can only approve accounts into areas you manage")
+		return
+	}
+	if !subject.CanAssignBaseRole(role) {
+		utils.ErrorResponse(c, 403, "You can only approve accounts with 'user' role")
+		return
+	}
+
+	now := time.Now()
+	reviewer := reviewerID.(uint)
+	user.Role = role
+	user.AreaID = &area.ID
+	user.SetActive(true, "")
+	user.ApprovalStatus = models.ApprovalStatusApproved
+	user.RejectionReason = ""
+	user.ReviewedBy = &reviewer
+	user.ReviewedAt = &now
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return models.SyncPrimaryArea(tx, &user)
+	})
+	if err != nil {
+		utils.ErrorResponse(c, 500, "Failed to approve user")
+		return
+	}
+
+	// Reload to get Area relation
+	config.DB.Preload("Area").First(&user, user.ID)
+
+	utils.SuccessResponse(c, 200, "User approved successfully", user)
+}
+
+// RejectUser godoc
+// @Summary Reject pending account
+// @Description Reject a pending account with a reason shown to the applicant on their next login (requires users.approve)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param rejection body RejectUserRequest true "Rejection reason"
+// @Success 200 {object} utils.Response{data=models.User}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /users/{id}/reject [post]
+func RejectUser(c *gin.Context) {
+	id := c.Param("id")
+	reviewerID, _ := c.Get("user_id")
+
+	var req models.RejectUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, 400, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		utils.ErrorResponse(c, 404, "User not found")
+		return
+	}
+
+	if !user.IsPendingApproval() {
+		utils.ErrorResponse(c, 400, "Only pending accounts can be rejected")
+		return
+	}
+
+	now := time.Now()
+	reviewer := reviewerID.(uint)
+	user.IsActive = false
+	user.ApprovalStatus = models.ApprovalStatusRejected
+	user.RejectionReason = req.Reason
+	user.ReviewedBy = &reviewer
+	user.ReviewedAt = &now
+
+	if err := config.DB.Save(&user).Error; err != nil {
+		utils.ErrorResponse(c, 500, "Failed to reject user")
+		return
+	}
+
+	utils.SuccessResponse(c, 200, "User rejected successfully", user)
+}
+
+// whereAreaMember restricts a users query to members of an area
+func whereAreaMember(query *gorm.DB, areaID uint) *gorm.DB {
+	return query.Where("(users.area_id = ? OR users.id IN (SELECT user_id FROM user_areas WHERE area_id = ?))", areaID, areaID)
 }
@@ -0,0 +1,195 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MicrosoftProviderName is the provider name of Microsoft accounts
+const MicrosoftProviderName = "microsoft"
+
+// MicrosoftUserInfo represents user information from Microsoft Graph API
+type MicrosoftUserInfo struct {
+	ID                string `json:"id"`
+	DisplayName       string `json:"displayName"`
+	GivenName         string `json:"givenName"`
+	Surname           string `json:"surname"`
+	UserPrincipalName string `json:"userPrincipalName"`
+	Mail              string `json:"mail"`
+}
+
+// MicrosoftProvider authenticates Microsoft access tokens through Microsoft Graph.
+// The access token is kept so the calendar integration can call Graph on the user's behalf.
+type MicrosoftProvider struct {
+	clientID string
+	tenantID string
+}
+
+// NewMicrosoftProvider creates the Microsoft provider for the accounts of one Azure AD tenant,
+// given by its ID or one of its verified domains. An empty tenant means the common endpoint.
+func NewMicrosoftProvider(clientID, tenantID string) *MicrosoftProvider {
+	tenantID = strings.TrimSpace(tenantID)
+	if tenantID == "" {
+		tenantID = "common"
+	}
+	return &MicrosoftProvider{clientID: clientID, tenantID: tenantID}
+}
+
+// IsSingleTenant checks the tenant names one organization rather than a multi-tenant endpoint
+// (common, organizations or consumers), which would accept accounts from any tenant
+func IsSingleTenant(tenantID string) bool {
+	switch strings.ToLower(strings.TrimSpace(tenantID)) {
+	case "", "common", "organizations", "consumers":
+		return false
+	}
+	return true
+}
+
+// Name implements Provider
+func (p *MicrosoftProvider) Name() string {
+	return MicrosoftProviderName
+}
+
+// Info implements Provider
+func (p *MicrosoftProvider) Info(ctx context.Context) ProviderInfo {
+	return ProviderInfo{
+		Name:                  MicrosoftProviderName,
+		DisplayName:           "Microsoft",
+		Type:                  "microsoft",
+		Issuer:                "https://login.microsoftonline.com/" + p.tenantID + "/v2.0",
+		AuthorizationEndpoint: "https://login.microsoftonline.com/" + p.tenantID + "/oauth2/v2.0/authorize",
+		ClientID:              p.clientID,
+		Scopes:                []string{"openid", "profile", "email", "User.Read", "Calendars.Read"},
+	}
+}
+
+// Authenticate validates the Microsoft access token and returns the account's identity
+func (p *MicrosoftProvider) Authenticate(ctx context.Context, creds Credentials) (*Identity, error) {
+	if creds.AccessToken == "" {
+		return nil, errors.New("access_token is required")
+	}
+
+	var userInfo MicrosoftUserInfo
+	if err := p.graphGet(ctx, creds.AccessToken, "/me", &userInfo); err != nil {
+		return nil, err
+	}
+
+	// Validate that we got essential information
+	if userInfo.Mail == "" && userInfo.UserPrincipalName == "" {
+		return nil, errors.New("no email found in Microsoft account")
+	}
+
+	// Use Mail if available, otherwise UserPrincipalName
+	email := userInfo.Mail
+	if email == "" {
+		email = userInfo.UserPrincipalName
+	}
+
+	name := userInfo.DisplayName
+	if name == "" {
+		name = strings.TrimSpace(userInfo.GivenName + " " + userInfo.Surname)
+	}
+
+	// Mail and UPN are not verified by themselves; only trust addresses in the tenant's verified domains.
+	// A multi-tenant endpoint accepts accounts from any tenant, so their addresses are never trusted
+	// and domain-based mapping rules do not match them.
+	email = strings.ToLower(email)
+	emailVerified := false
+	if IsSingleTenant(p.tenantID) {
+		// Any tenant's Graph token works on /me, so check it was issued by the configured tenant
+		domains, err := p.tenantDomains(ctx, creds.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		at := strings.LastIndex(email, "@")
+		emailVerified = at >= 0 && domains[email[at+1:]]
+	}
+
+	return &Identity{
+		Provider:      MicrosoftProviderName,
+		Subject:       userInfo.ID,
+		Email:         email,
+		EmailVerified: emailVerified,
+		Name:          name,
+		Groups:        p.groups(ctx, creds.AccessToken),
+		AccessToken:   creds.AccessToken,
+	}, nil
+}
+
+// tenantDomains checks the token belongs to the configured tenant and returns the tenant's verified domains, lowercased
+func (p *MicrosoftProvider) tenantDomains(ctx context.Context, accessToken string) (map[string]bool, error) {
+	var result struct {
+		Value []struct {
+			ID              string `json:"id"`
+			VerifiedDomains []struct {
+				Name string `json:"name"`
+			} `json:"verifiedDomains"`
+		} `json:"value"`
+	}
+	if err := p.graphGet(ctx, accessToken, "/organization?$select=id,verifiedDomains", &result); err != nil {
+		return nil, fmt.Errorf("failed to read the account's tenant: %w", err)
+	}
+	if len(result.Value) == 0 {
+		return nil, errors.New("the account does not belong to an organization")
+	}
+
+	org := result.Value[0]
+	domains := make(map[string]bool, len(org.VerifiedDomains))
+	for _, domain := range org.VerifiedDomains {
+		domains[strings.ToLower(domain.Name)] = true
+	}
+	if !strings.EqualFold(org.ID, p.tenantID) && !domains[strings.ToLower(p.tenantID)] {
+		return nil, errors.New("the account belongs to another tenant")
+	}
+	return domains, nil
+}
+
+// groups returns the object IDs of the user's groups. Display names are not reported: they are not
+// unique and anyone can reuse them. Reading groups needs the GroupMember.Read.All consent, so a
+// failure only means no groups are reported.
+func (p *MicrosoftProvider) groups(ctx context.Context, accessToken string) []string {
+	var result struct {
+		Value []struct {
+			ID string `json:"id"`
+		} `json:"value"`
+	}
+	if err := p.graphGet(ctx, accessToken, "/me/transitiveMemberOf/microsoft.graph.group?$select=id&$top=999", &result); err != nil {
+		return nil
+	}
+
+	groups := make([]string, 0, len(result.Value))
+	for _, group := range result.Value {
+		groups = append(groups, group.ID)
+	}
+	return groups
+}
+
+// graphGet calls a Microsoft Graph API endpoint and decodes the response
+func (p *MicrosoftProvider) graphGet(ctx context.Context, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://graph.microsoft.com/v1.0"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Microsoft Graph API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("microsoft API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
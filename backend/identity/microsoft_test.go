@@ -0,0 +1,33 @@
+package identity
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsSingleTenant(t *testing.T) {
+	tests := []struct {
+		tenantID string
+		want     bool
+	}{
+		{"", false},
+		{"  ", false},
+		{"common", false},
+		{"Organizations", false},
+		{"consumers", false},
+		{"72f988bf-86f1-41af-91ab-2d7cd011db47", true},
+		{"contoso.onmicrosoft.com", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsSingleTenant(tt.tenantID); got != tt.want {
+			t.Errorf("IsSingleTenant(%q) = %v, want %v", tt.tenantID, got, tt.want)
+		}
+	}
+}
+
+func TestNewMicrosoftProviderDefaultsToCommon(t *testing.T) {
+	if got := NewMicrosoftProvider("client", " ").Info(context.Background()).Issuer; got != "https://login.microsoftonline.com/common/v2.0" {
+		t.Errorf("Issuer = %q, want the common endpoint", got)
+	}
+}
@@ -0,0 +1,392 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Cache lifetimes for the discovery document and the signing keys of an issuer
+const (
+	discoveryTTL    = time.Hour
+	keysTTL         = time.Hour
+	keysMinInterval = time.Minute // Minimum time between key reloads caused by an unknown kid
+)
+
+// idTokenMethods are the ID token signing algorithms accepted from OIDC providers
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// OIDCConfig configures a generic OpenID Connect provider
+type OIDCConfig struct {
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string   // Only needed to exchange authorization codes with confidential clients
+	Scopes       []string // Default: openid email profile
+	EmailClaim   string   // Default: email
+	NameClaim    string   // Default: name
+	GroupsClaim  string   // Default: groups. Dotted paths reach nested claims, e.g. realm_access.roles
+}
+
+// discoveryDocument is the subset of /.well-known/openid-configuration the provider uses
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider authenticates users of any OpenID Connect issuer (Keycloak, Google Workspace, Okta, ...)
+// by verifying their ID token against the keys published through discovery
+type OIDCProvider struct {
+	config OIDCConfig
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewOIDCProvider creates an OIDC provider. Discovery happens on first use.
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.DisplayName == "" {
+		config.DisplayName = config.Name
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	if config.EmailClaim == "" {
+		config.EmailClaim = "email"
+	}
+	if config.NameClaim == "" {
+		config.NameClaim = "name"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	return &OIDCProvider{config: config}
+}
+
+// Name implements Provider
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+// Info implements Provider
+func (p *OIDCProvider) Info(ctx context.Context) ProviderInfo {
+	info := ProviderInfo{
+		Name:        p.config.Name,
+		DisplayName: p.config.DisplayName,
+		Type:        "oidc",
+		Issuer:      p.config.Issuer,
+		ClientID:    p.config.ClientID,
+		Scopes:      p.config.Scopes,
+	}
+	if doc, err := p.discover(ctx); err == nil {
+		info.AuthorizationEndpoint = doc.AuthorizationEndpoint
+	}
+	return info
+}
+
+// Authenticate verifies an ID token, exchanging the authorization code first when no ID token is sent
+func (p *OIDCProvider) Authenticate(ctx context.Context, creds Credentials) (*Identity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds.IDToken == "" {
+		if creds.Code == "" {
+			return nil, errors.New("id_token or code is required")
+		}
+		if creds.IDToken, creds.AccessToken, err = p.exchangeCode(ctx, doc, creds); err != nil {
+			return nil, err
+		}
+	}
+
+	claims, err := p.verifyIDToken(ctx, doc, creds.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if creds.Nonce != "" {
+		if nonce, _ := claims["nonce"].(string); nonce != creds.Nonce {
+			return nil, errors.New("nonce does not match")
+		}
+	}
+
+	// Claims missing from the ID token may be available from the userinfo endpoint
+	if creds.AccessToken != "" && doc.UserinfoEndpoint != "" && lookupClaim(claims, p.config.EmailClaim) == nil {
+		if userinfo, err := p.fetchUserinfo(ctx, doc, creds.AccessToken); err == nil {
+			if sub, _ := userinfo["sub"].(string); sub == claims["sub"] {
+				for key, value := range userinfo {
+					if _, exists := claims[key]; !exists {
+						claims[key] = value
+					}
+				}
+			}
+		}
+	}
+
+	return p.mapClaims(claims, creds.AccessToken)
+}
+
+// mapClaims builds the identity from the verified claims using the configured claim names
+func (p *OIDCProvider) mapClaims(claims jwt.MapClaims, accessToken string) (*Identity, error) {
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	email, _ := lookupClaim(claims, p.config.EmailClaim).(string)
+	if email == "" {
+		return nil, errors.New("no email found in ID token")
+	}
+	// Only trust the address when the provider does not say it is unverified
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, errors.New("email address is not verified")
+	}
+
+	name, _ := lookupClaim(claims, p.config.NameClaim).(string)
+	if name == "" {
+		given, _ := claims["given_name"].(string)
+		family, _ := claims["family_name"].(string)
+		name = strings.TrimSpace(given + " " + family)
+	}
+	if name == "" {
+		name = email
+	}
+
+	var groups []string
+	switch value := lookupClaim(claims, p.config.GroupsClaim).(type) {
+	case []interface{}:
+		for _, group := range value {
+			if s, ok := group.(string); ok && s != "" {
+				groups = append(groups, s)
+			}
+		}
+	case string:
+		groups = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	}
+
+	verified, _ := claims["email_verified"].(bool)
+
+	return &Identity{
+		Provider:      p.config.Name,
+		Subject:       subject,
+		Email:         strings.ToLower(email),
+		EmailVerified: verified,
+		Name:          name,
+		Groups:        groups,
+		AccessToken:   accessToken,
+	}, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience and expiry of an ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, doc *discoveryDocument, idToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, doc, kid)
+	},
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	// With several audiences the token must have been issued to us (authorized party)
+	if azp, ok := claims["azp"].(string); ok && azp != "" && azp != p.config.ClientID {
+		return nil, errors.New("invalid ID token: issued to another client")
+	}
+	return claims, nil
+}
+
+// discover loads and caches the issuer's discovery document
+func (p *OIDCProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		if p.discovery != nil {
+			return p.discovery, nil // Keep using the previous document while the issuer is unreachable
+		}
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", doc.Issuer, p.config.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document has no jwks_uri")
+	}
+
+	p.discovery = &doc
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// signingKey returns the issuer key for kid, reloading the key set when it is stale or the kid is unknown
+func (p *OIDCProvider) signingKey(ctx context.Context, doc *discoveryDocument, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.findKey(kid)
+	age := time.Since(p.keysFetchedAt)
+	if (!ok && age >= keysMinInterval) || age >= keysTTL {
+		var set struct {
+			Keys []JWK `json:"keys"`
+		}
+		if err := getJSON(ctx, doc.JWKSURI, "", &set); err != nil {
+			if !ok {
+				return nil, fmt.Errorf("failed to load issuer keys: %w", err)
+			}
+			return key, nil
+		}
+
+		keys := make(map[string]crypto.PublicKey)
+		for _, jwk := range set.Keys {
+			if jwk.Use != "" && jwk.Use != "sig" {
+				continue
+			}
+			if publicKey, err := jwk.PublicKey(); err == nil {
+				keys[jwk.Kid] = publicKey
+			}
+		}
+		p.keys = keys
+		p.keysFetchedAt = time.Now()
+		key, ok = p.findKey(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// findKey looks a key up by kid. Issuers with a single key may omit the kid.
+func (p *OIDCProvider) findKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// exchangeCode redeems an authorization code at the token endpoint
+func (p *OIDCProvider) exchangeCode(ctx context.Context, doc *discoveryDocument, creds Credentials) (idToken, accessToken string, err error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {creds.Code},
+		"redirect_uri": {creds.RedirectURI},
+		"client_id":    {p.config.ClientID},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	if creds.CodeVerifier != "" {
+		form.Set("code_verifier", creds.CodeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokens struct {
+		IDToken     string `json:"id_token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return "", "", errors.New("token endpoint returned no id_token")
+	}
+	return tokens.IDToken, tokens.AccessToken, nil
+}
+
+// fetchUserinfo reads the claims of the userinfo endpoint
+func (p *OIDCProvider) fetchUserinfo(ctx context.Context, doc *discoveryDocument, accessToken string) (map[string]interface{}, error) {
+	claims := map[string]interface{}{}
+	if err := getJSON(ctx, doc.UserinfoEndpoint, accessToken, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// lookupClaim returns a claim by name, following dots into nested objects
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	if value, ok := claims[path]; ok {
+		return value
+	}
+	var current interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = object[part]
+	}
+	return current
+}
+
+// getJSON fetches a JSON document, optionally with a bearer token
+func getJSON(ctx context.Context, rawURL, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
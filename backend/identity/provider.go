@@ -0,0 +1,155 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrProviderNotFound is returned when no provider is registered under a name
+var ErrProviderNotFound = errors.New("identity provider not found")
+
+// Identity is the user information an identity provider vouches for
+type Identity struct {
+	Provider      string   // Name of the provider that authenticated the user
+	Subject       string   // Stable user ID at the provider
+	Email         string   // Lowercased email address
+	EmailVerified bool     // The provider vouches that the user owns the email address
+	Name          string   // Display name
+	Groups        []string // Group names or IDs reported by the provider (object IDs for Microsoft)
+	// AccessToken is kept for provider APIs used after login (e.g. the Microsoft calendar)
+	AccessToken string
+}
+
+// Credentials are what the client obtained from the provider. Which fields are required
+// depends on the provider: Microsoft needs an access token, OIDC providers an ID token
+// or an authorization code to exchange.
+type Credentials struct {
+	IDToken      string
+	AccessToken  string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	Nonce        string
+}
+
+// ProviderInfo describes a provider to clients so they can start its login flow
+type ProviderInfo struct {
+	Name                  string   `json:"name"`
+	DisplayName           string   `json:"display_name"`
+	Type                  string   `json:"type"` // 'microsoft' or 'oidc'
+	Issuer                string   `json:"issuer,omitempty"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint,omitempty"`
+	ClientID              string   `json:"client_id,omitempty"`
+	Scopes                []string `json:"scopes,omitempty"`
+}
+
+// Provider authenticates users against an external identity provider
+type Provider interface {
+	// Name is the identifier stored in User.AuthProvider
+	Name() string
+	// Info describes the provider for the login screen
+	Info(ctx context.Context) ProviderInfo
+	// Authenticate verifies the credentials and returns the identity they belong to
+	Authenticate(ctx context.Context, creds Credentials) (*Identity, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	providers  = make(map[string]Provider)
+	order      []string
+)
+
+// httpClient is shared by the providers for discovery, key and API calls
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// validName matches provider names: they are used in URLs, env vars and the users.auth_provider column
+var validName = regexp.MustCompile(`^[a-z][a-z0-9-]{0,19}$`)
+
+// Register adds a provider, replacing any provider with the same name
+func Register(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := providers[p.Name()]; !exists {
+		order = append(order, p.Name())
+	}
+	providers[p.Name()] = p
+}
+
+// Get returns the provider registered under name
+func Get(name string) (Provider, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return p, nil
+}
+
+// All returns the registered providers in registration order
+func All() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	list := make([]Provider, 0, len(order))
+	for _, name := range order {
+		list = append(list, providers[name])
+	}
+	return list
+}
+
+// LoadFromEnv registers Microsoft (unless MICROSOFT_SSO_ENABLED=false) and every OIDC provider
+// listed in OIDC_PROVIDERS. Each OIDC provider reads OIDC_<NAME>_* variables, for example:
+//
+//	OIDC_PROVIDERS=keycloak,google
+//	OIDC_KEYCLOAK_ISSUER=https://sso.example.com/realms/main
+//	OIDC_KEYCLOAK_CLIENT_ID=timeflow
+//	OIDC_KEYCLOAK_GROUPS_CLAIM=realm_access.roles
+func LoadFromEnv() {
+	if !strings.EqualFold(os.Getenv("MICROSOFT_SSO_ENABLED"), "false") {
+		tenantID := os.Getenv("MICROSOFT_TENANT_ID")
+		if !IsSingleTenant(tenantID) {
+			log.Printf("WARNING: MICROSOFT_TENANT_ID %q is not a single tenant: Microsoft accounts from ANY tenant can sign in "+
+				"(pending SuperAdmin approval) and domain-based identity mapping rules are ignored for them. "+
+				"Set it to the ID or a verified domain of your tenant.", tenantID)
+		}
+		Register(NewMicrosoftProvider(os.Getenv("MICROSOFT_CLIENT_ID"), tenantID))
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !validName.MatchString(name) || name == "local" {
+			log.Printf("Warning: Skipping OIDC provider %q: invalid name", name)
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config := OIDCConfig{
+			Name:         name,
+			DisplayName:  os.Getenv(prefix + "DISPLAY_NAME"),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			EmailClaim:   os.Getenv(prefix + "EMAIL_CLAIM"),
+			NameClaim:    os.Getenv(prefix + "NAME_CLAIM"),
+			GroupsClaim:  os.Getenv(prefix + "GROUPS_CLAIM"),
+		}
+		if config.Issuer == "" || config.ClientID == "" {
+			log.Printf("Warning: Skipping OIDC provider %q: %sISSUER and %sCLIENT_ID are required", name, prefix, prefix)
+			continue
+		}
+
+		Register(NewOIDCProvider(config))
+		log.Printf("✓ OIDC provider registered: %s (%s)", name, config.Issuer)
+	}
+}
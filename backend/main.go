@@ -1,13 +1,16 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/config"
 	_ "github.com/jaliko05/time-flow/docs" // swagger docs
+	"github.com/jaliko05/time-flow/identity"
 	"github.com/jaliko05/time-flow/routes"
+	"github.com/jaliko05/time-flow/utils"
 	"github.com/joho/godotenv"
 )
 
@@ -30,15 +33,40 @@ import (
 // @name Authorization
 // @description Enter your JWT token in the format: Bearer {token}
 
+// @securityDefinitions.apikey ProvisioningToken
+// @in header
+// @name Authorization
+// @description SCIM provisioning token in the format: Bearer {token}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
+	// Register the identity providers (Microsoft and OIDC_PROVIDERS)
+	identity.LoadFromEnv()
+
 	// Initialize database
 	config.ConnectDatabase()
 
+	// Load the JWT signing keys
+	if err := utils.InitKeyring(config.DB); err != nil {
+		log.Fatalf("Failed to initialize JWT keys: %v", err)
+	}
+
+	// Admin CLI commands (e.g. `timeflow create-superadmin -email ...`)
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	// Enter first-run mode if there is no super admin yet
+	config.InitializeSetup()
+
+	// Rotate the JWT signing key when it reaches JWT_KEY_ROTATION_DAYS
+	utils.StartKeyRotation()
+
 	// Setup Gin router
 	gin.SetMode(os.Getenv("GIN_MODE"))
 	router := gin.Default()
@@ -57,3 +85,33 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// runCommand executes an admin CLI command instead of starting the server
+func runCommand(name string, args []string) {
+	switch name {
+	case "create-superadmin":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		email := fs.String("email", "", "Super admin email")
+		password := fs.String("password", "", "Super admin password (min 8 characters)")
+		fullName := fs.String("name", "Super Administrator", "Super admin full name")
+		fs.Parse(args)
+
+		if *email == "" || len(*password) < 8 {
+			log.Fatal("Usage: timeflow create-superadmin -email <email> -password <password> [-name <full name>]")
+		}
+
+		user, err := config.CreateSuperAdmin(*email, *password, *fullName)
+		if err != nil {
+			log.Fatalf("Failed to create super admin: %v", err)
+		}
+		log.Printf("Super admin created: %s (id %d)", user.Email, user.ID)
+	case "rotate-jwt-key":
+		key, err := utils.RotateSigningKey()
+		if err != nil {
+			log.Fatalf("Failed to rotate JWT signing key: %v", err)
+		}
+		log.Printf("JWT signing key rotated: kid %s (%s)", key.Kid, key.Algorithm)
+	default:
+		log.Fatalf("Unknown command %q. Available commands: create-superadmin, rotate-jwt-key", name)
+	}
+}
@@ -2,11 +2,20 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
 	"github.com/jaliko05/time-flow/utils"
 )
 
+// passwordChangeAllowedPaths are the only routes reachable while a password change is pending
+var passwordChangeAllowedPaths = map[string]bool{
+	"/api/v1/auth/me":              true,
+	"/api/v1/auth/change-password": true,
+}
+
 // AuthMiddleware validates JWT token and sets user info in context
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -33,12 +42,50 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Deactivated accounts and revoked sessions lose access immediately
+		if !accountAcceptsToken(claims) {
+			utils.ErrorResponse(c, 401, "Session revoked or account inactive")
+			c.Abort()
+			return
+		}
+
+		// Accounts flagged for a forced password change can only change their password
+		if claims.MustChangePassword && !passwordChangeAllowedPaths[c.FullPath()] {
+			utils.ErrorResponse(c, 403, "Password change required")
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
 		c.Set("user_area_id", claims.AreaID)
 
+		// Requests made by a SuperAdmin acting as this user are checked and audited
+		if claims.Impersonation != nil {
+			handleImpersonation(c, claims.Impersonation)
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// accountAcceptsToken checks that the token's account is still active and approved and its tokens were
+// not revoked after it was issued
+func accountAcceptsToken(claims *utils.JWTClaims) bool {
+	var account struct {
+		IsActive        bool
+		ApprovalStatus  models.ApprovalStatus
+		TokensRevokedAt *time.Time
+	}
+	if err := config.DB.Model(&models.User{}).Select("is_active, approval_status, tokens_revoked_at").
+		Where("id = ?", claims.UserID).Take(&account).Error; err != nil {
+		return false
+	}
+	if !account.IsActive || account.ApprovalStatus != models.ApprovalStatusApproved {
+		return false
+	}
+	return account.TokensRevokedAt == nil || (claims.IssuedAt != nil && claims.IssuedAt.Time.After(*account.TokensRevokedAt))
+}
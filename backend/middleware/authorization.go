@@ -3,9 +3,27 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/policy"
 	"github.com/jaliko05/time-flow/utils"
 )
 
+// RequirePermission middleware checks if the user holds at least one of the permissions
+// in some area. Area-specific checks are done by the handlers through the policy package.
+func RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := policy.FromContext(c)
+		for _, p := range permissions {
+			if subject.CanAnywhere(p) {
+				c.Next()
+				return
+			}
+		}
+
+		utils.ErrorResponse(c, 403, "Insufficient permissions")
+		c.Abort()
+	}
+}
+
 // RequireRole middleware checks if user has required role
 func RequireRole(roles ...models.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// impersonationWriteAllowedPaths can be called with a read-only impersonation token
+var impersonationWriteAllowedPaths = map[string]bool{
+	"/api/v1/auth/impersonation/stop": true,
+}
+
+// safeMethods don't modify data and are always allowed while impersonating
+var safeMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"OPTIONS": true,
+}
+
+// handleImpersonation runs a request made with an impersonation token: it checks the session is
+// still active, blocks writes for read-only sessions and records the request in the audit log.
+func handleImpersonation(c *gin.Context, imp *utils.ImpersonationClaims) {
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, imp.SessionID).Error; err != nil || !session.IsActive() {
+		utils.ErrorResponse(c, 401, "Impersonation session has ended")
+		c.Abort()
+		return
+	}
+
+	c.Set("impersonator_id", session.ImpersonatorID)
+	c.Set("impersonation_session_id", session.ID)
+
+	blocked := session.ReadOnly && !safeMethods[c.Request.Method] && !impersonationWriteAllowedPaths[c.FullPath()]
+	if blocked {
+		utils.ErrorResponse(c, 403, "Write operations are disabled while impersonating in read-only mode")
+		c.Abort()
+	} else {
+		c.Next()
+	}
+
+	entry := models.ImpersonationAuditLog{
+		SessionID:          session.ID,
+		ImpersonatorID:     session.ImpersonatorID,
+		ImpersonatedUserID: session.ImpersonatedUserID,
+		Method:             c.Request.Method,
+		Path:               c.Request.URL.Path,
+		Query:              c.Request.URL.RawQuery,
+		StatusCode:         c.Writer.Status(),
+		Blocked:            blocked,
+		ClientIP:           c.ClientIP(),
+	}
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Warning: Failed to record impersonated request for session %d: %v", session.ID, err)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// SCIMAuthMiddleware authenticates SCIM requests with a provisioning token
+func SCIMAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || parts[1] == "" {
+			utils.SCIMErrorResponse(c, 401, "", "Provisioning token required")
+			c.Abort()
+			return
+		}
+
+		var token models.ProvisioningToken
+		if err := config.DB.Where("token_hash = ? AND revoked_at IS NULL", utils.HashToken(parts[1])).First(&token).Error; err != nil {
+			utils.SCIMErrorResponse(c, 401, "", "Invalid provisioning token")
+			c.Abort()
+			return
+		}
+
+		config.DB.Model(&token).UpdateColumn("last_used_at", time.Now())
+
+		c.Set("provisioning_token_id", token.ID)
+		c.Set("scim_auth_provider", token.AuthProvider)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/utils"
+)
+
+// RequireSetupComplete blocks the API until the first super admin has been created
+func RequireSetupComplete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.IsSetupRequired() {
+			utils.ErrorResponse(c, 503, "Initial setup required. Create the first super admin using the setup token")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
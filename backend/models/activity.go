@@ -6,6 +6,7 @@ import (
 	"gorm.io/gorm"
 )
 
+// ActivityType is the code of an ActivityTypeDefinition. The constants are the built-in types.
 type ActivityType string
 
 const (
@@ -40,6 +41,12 @@ type Activity struct {
 	OtherArea       string         `json:"other_area"`
 	Observations    string         `gorm:"type:text" json:"observations"`
 	CalendarEventID *string        `gorm:"uniqueIndex" json:"calendar_event_id"` // Changed to unique for calendar sync
+	Billable        bool           `gorm:"default:false;index" json:"billable"`
+	CostRate        float64        `gorm:"default:0" json:"cost_rate"` // Rates in effect when the activity was logged, kept so later rate changes don't rewrite history
+	BillRate        float64        `gorm:"default:0" json:"bill_rate"`
+	CostAmount      float64        `gorm:"default:0" json:"cost_amount"`
+	RevenueAmount   float64        `gorm:"default:0" json:"revenue_amount"`
+	InvoiceID       *uint          `gorm:"index" json:"invoice_id"` // Set once invoiced; the activity is locked until the invoice is voided
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
@@ -51,6 +58,11 @@ type Activity struct {
 	Task    *Task    `gorm:"foreignKey:TaskID" json:"task,omitempty" swaggerignore:"true"`
 }
 
+// IsInvoiced checks if the activity belongs to an issued invoice and can no longer change
+func (a *Activity) IsInvoiced() bool {
+	return a.InvoiceID != nil
+}
+
 // BeforeCreate hook to set month field automatically
 func (a *Activity) BeforeCreate(tx *gorm.DB) error {
 	if a.Month == "" {
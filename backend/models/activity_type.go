@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ActivityTypeDefinition is an activity type that can be used when logging activities.
+// Global types (no area) are available everywhere; area types only to activities of that area.
+type ActivityTypeDefinition struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	Code            ActivityType   `gorm:"type:varchar(50);not null;uniqueIndex:idx_activity_type_code_area,where:deleted_at IS NULL" json:"code"` // Value stored in activities.activity_type
+	AreaID          *uint          `gorm:"index;uniqueIndex:idx_activity_type_code_area,where:deleted_at IS NULL" json:"area_id"`                  // Nil for global types
+	Names           datatypes.JSON `gorm:"not null" json:"names" swaggertype:"object"`                                                             // Display name per locale, e.g. {"es": "Pruebas", "en": "Testing"}
+	Name            string         `gorm:"-" json:"name"`                                                                                          // Display name in the requester's locale
+	IsActive        bool           `json:"is_active"`
+	BillableDefault bool           `gorm:"default:false" json:"billable_default"` // Activities of this type are billable unless stated otherwise
+	RequiresProject bool           `gorm:"default:false" json:"requires_project"` // Activities of this type must be linked to a project
+	SortOrder       int            `gorm:"default:0" json:"sort_order"`
+	CreatedBy       *uint          `json:"created_by,omitempty"` // Nil for the built-in types
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Area *Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// BuiltinActivityTypes are the types that used to be hardcoded, seeded as global definitions
+var BuiltinActivityTypes = []struct {
+	Code  ActivityType
+	Names map[string]string
+}{
+	{ActivityTypePlanDeTrabajo, map[string]string{"es": "Plan de Trabajo", "en": "Work Plan"}},
+	{ActivityTypeApoyoSolicitadoPorOtrasAreas, map[string]string{"es": "Apoyo Solicitado por Otras Áreas", "en": "Support Requested by Other Areas"}},
+	{ActivityTypeTeams, map[string]string{"es": "Teams", "en": "Teams"}},
+	{ActivityTypeInterno, map[string]string{"es": "Interno", "en": "Internal"}},
+	{ActivityTypeSesion, map[string]string{"es": "Sesión", "en": "Session"}},
+	{ActivityTypeInvestigacion, map[string]string{"es": "Investigación", "en": "Research"}},
+	{ActivityTypePrototipado, map[string]string{"es": "Prototipado", "en": "Prototyping"}},
+	{ActivityTypeDisenos, map[string]string{"es": "Diseños", "en": "Designs"}},
+	{ActivityTypePruebas, map[string]string{"es": "Pruebas", "en": "Testing"}},
+	{ActivityTypeDocumentacion, map[string]string{"es": "Documentación", "en": "Documentation"}},
+}
+
+// Localize fills Name with the display name for the locale, falling back to its language,
+// to Spanish and finally to the code
+func (d *ActivityTypeDefinition) Localize(locale string) {
+	var names map[string]string
+	_ = json.Unmarshal(d.Names, &names)
+
+	language := strings.SplitN(locale, "-", 2)[0]
+	for _, key := range []string{locale, language, DefaultLocale} {
+		if name := names[key]; name != "" {
+			d.Name = name
+			return
+		}
+	}
+	d.Name = string(d.Code)
+}
+
+// ScopeActivityTypesFor restricts a query to the active types available to the given areas
+func ScopeActivityTypesFor(db *gorm.DB, areaIDs []uint) *gorm.DB {
+	db = db.Where("is_active = ?", true)
+	if len(areaIDs) == 0 {
+		return db.Where("area_id IS NULL")
+	}
+	return db.Where("area_id IS NULL OR area_id IN ?", areaIDs)
+}
+
+// FindActivityType returns the active definition of a type available to an activity of the area.
+// An area type takes precedence over a global one with the same code.
+func FindActivityType(db *gorm.DB, code ActivityType, areaID *uint) (*ActivityTypeDefinition, error) {
+	var areaIDs []uint
+	if areaID != nil {
+		areaIDs = []uint{*areaID}
+	}
+	var definition ActivityTypeDefinition
+	err := ScopeActivityTypesFor(db, areaIDs).
+		Where("code = ?", code).
+		Order("area_id IS NULL ASC").
+		First(&definition).Error
+	if err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
@@ -19,13 +19,16 @@ type Area struct {
 	ID          uint           `gorm:"primarykey" json:"id"`
 	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
 	Description string         `json:"description"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	ParentID    *uint          `gorm:"index" json:"parent_id"` // Parent area (directorate → department → team)
+	IsActive    bool           `json:"is_active"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
 
 	// Relations
-	Users []User `gorm:"foreignKey:AreaID" json:"users,omitempty" swaggerignore:"true"`
+	Users    []User `gorm:"foreignKey:AreaID" json:"users,omitempty" swaggerignore:"true"`
+	Parent   *Area  `gorm:"foreignKey:ParentID" json:"parent,omitempty" swaggerignore:"true"`
+	Children []Area `gorm:"foreignKey:ParentID" json:"children,omitempty" swaggerignore:"true"`
 }
 
 // WorkSchedule stores the work schedule configuration
@@ -0,0 +1,68 @@
+package models
+
+import "gorm.io/gorm"
+
+// AreaTree is an in-memory view of the area hierarchy
+type AreaTree struct {
+	parents  map[uint]*uint
+	children map[uint][]uint
+}
+
+// LoadAreaTree reads the parent of every area
+func LoadAreaTree(db *gorm.DB) *AreaTree {
+	var rows []struct {
+		ID       uint
+		ParentID *uint
+	}
+	db.Model(&Area{}).Select("id, parent_id").Scan(&rows)
+
+	tree := &AreaTree{
+		parents:  make(map[uint]*uint, len(rows)),
+		children: make(map[uint][]uint),
+	}
+	for _, row := range rows {
+		tree.parents[row.ID] = row.ParentID
+		if row.ParentID != nil {
+			tree.children[*row.ParentID] = append(tree.children[*row.ParentID], row.ID)
+		}
+	}
+	return tree
+}
+
+// Descendants returns the area and every area below it
+func (t *AreaTree) Descendants(areaID uint) []uint {
+	ids := []uint{areaID}
+	seen := map[uint]bool{areaID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// Depth returns how many ancestors the area has
+func (t *AreaTree) Depth(areaID uint) int {
+	depth := 0
+	seen := map[uint]bool{areaID: true}
+	for parent := t.parents[areaID]; parent != nil && !seen[*parent]; parent = t.parents[*parent] {
+		seen[*parent] = true
+		depth++
+	}
+	return depth
+}
+
+// IsAncestor checks if ancestorID is areaID itself or one of its ancestors
+func (t *AreaTree) IsAncestor(ancestorID, areaID uint) bool {
+	seen := make(map[uint]bool)
+	for current := &areaID; current != nil && !seen[*current]; current = t.parents[*current] {
+		if *current == ancestorID {
+			return true
+		}
+		seen[*current] = true
+	}
+	return false
+}
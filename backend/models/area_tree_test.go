@@ -0,0 +1,106 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testAreaTree builds a tree from each area's parent, as LoadAreaTree does from the database
+func testAreaTree(parents map[uint]uint) *AreaTree {
+	tree := &AreaTree{parents: make(map[uint]*uint), children: make(map[uint][]uint)}
+	for id := uint(1); id <= 10; id++ {
+		parent, ok := parents[id]
+		if !ok {
+			continue
+		}
+		if parent == 0 {
+			tree.parents[id] = nil
+			continue
+		}
+		tree.parents[id] = &parent
+		tree.children[parent] = append(tree.children[parent], id)
+	}
+	return tree
+}
+
+// 1 ── 2 ── 4
+// │    └─── 5
+// └─── 3
+// 6 (separate root); 7 ⇄ 8 (a corrupt cycle)
+var testAreaParents = map[uint]uint{1: 0, 2: 1, 3: 1, 4: 2, 5: 2, 6: 0, 7: 8, 8: 7}
+
+func TestAreaTreeDescendants(t *testing.T) {
+	tree := testAreaTree(testAreaParents)
+
+	tests := []struct {
+		name   string
+		areaID uint
+		want   []uint
+	}{
+		{"root", 1, []uint{1, 2, 3, 4, 5}},
+		{"subtree", 2, []uint{2, 4, 5}},
+		{"leaf", 4, []uint{4}},
+		{"separate root", 6, []uint{6}},
+		{"unknown area", 42, []uint{42}},
+		{"cycle", 7, []uint{7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tree.Descendants(tt.areaID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Descendants(%d) = %v, want %v", tt.areaID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaTreeDepth(t *testing.T) {
+	tree := testAreaTree(testAreaParents)
+
+	tests := []struct {
+		areaID uint
+		want   int
+	}{
+		{1, 0},
+		{2, 1},
+		{3, 1},
+		{5, 2},
+		{6, 0},
+		{42, 0},
+		{7, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tree.Depth(tt.areaID); got != tt.want {
+			t.Errorf("Depth(%d) = %d, want %d", tt.areaID, got, tt.want)
+		}
+	}
+}
+
+func TestAreaTreeIsAncestor(t *testing.T) {
+	tree := testAreaTree(testAreaParents)
+
+	tests := []struct {
+		name               string
+		ancestorID, areaID uint
+		want               bool
+	}{
+		{"itself", 2, 2, true},
+		{"parent", 1, 2, true},
+		{"grandparent", 1, 5, true},
+		{"child", 5, 1, false},
+		{"sibling", 3, 4, false},
+		{"other root", 6, 4, false},
+		{"unknown area", 1, 42, false},
+		{"cycle ends", 6, 7, false},
+		{"within cycle", 8, 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tree.IsAncestor(tt.ancestorID, tt.areaID); got != tt.want {
+				t.Errorf("IsAncestor(%d, %d) = %v, want %v", tt.ancestorID, tt.areaID, got, tt.want)
+			}
+		})
+	}
+}
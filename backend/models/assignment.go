@@ -6,6 +6,35 @@ import (
 	"gorm.io/gorm"
 )
 
+// MemberRole is what a member can do within a project
+type MemberRole string
+
+const (
+	MemberRoleOwner       MemberRole = "owner"       // Gestiona el proyecto, sus miembros y sus tareas
+	MemberRoleManager     MemberRole = "manager"     // Crea y asigna tareas y cambia el estado del proyecto
+	MemberRoleContributor MemberRole = "contributor" // Trabaja en sus tareas y registra tiempo
+	MemberRoleViewer      MemberRole = "viewer"      // Solo consulta; no registra tiempo
+)
+
+// IsValid checks if the role is one of the defined member roles
+func (r MemberRole) IsValid() bool {
+	switch r {
+	case MemberRoleOwner, MemberRoleManager, MemberRoleContributor, MemberRoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanManageTasks checks if members with the role can create, edit and assign the project's tasks
+func (r MemberRole) CanManageTasks() bool {
+	return r == MemberRoleOwner || r == MemberRoleManager
+}
+
+// CanLogTime checks if members with the role can work on tasks and register activities
+func (r MemberRole) CanLogTime() bool {
+	return r != MemberRoleViewer
+}
+
 // ProjectAssignment represents the many-to-many relationship between projects and users
 // This allows multiple users to be assigned to a single project
 type ProjectAssignment struct {
@@ -14,8 +43,8 @@ type ProjectAssignment struct {
 	UserID       uint           `gorm:"not null;index:idx_project_user,unique;index:idx_user_active" json:"user_id"`       // Composite indexes
 	AssignedBy   uint           `gorm:"not null" json:"assigned_by"`
 	AssignedAt   time.Time      `gorm:"autoCreateTime" json:"assigned_at"`
-	CanModify    bool           `gorm:"default:true" json:"can_modify"`
-	IsActive     bool           `gorm:"default:true;index:idx_project_active;index:idx_user_active" json:"is_active"` // Part of composite indexes
+	Role         MemberRole     `gorm:"type:varchar(20);not null;default:'contributor'" json:"role"`
+	IsActive     bool           `gorm:"index:idx_project_active;index:idx_user_active" json:"is_active"` // Part of composite indexes
 	UnassignedAt *time.Time     `json:"unassigned_at,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -35,8 +64,7 @@ type TaskAssignment struct {
 	UserID       uint           `gorm:"not null;index:idx_task_user,unique;index:idx_user_task_active" json:"user_id"` // Composite indexes
 	AssignedBy   uint           `gorm:"not null" json:"assigned_by"`
 	AssignedAt   time.Time      `gorm:"autoCreateTime" json:"assigned_at"`
-	CanModify    bool           `gorm:"default:true" json:"can_modify"`
-	IsActive     bool           `gorm:"default:true;index:idx_task_active;index:idx_user_task_active" json:"is_active"` // Part of composite indexes
+	IsActive     bool           `gorm:"index:idx_task_active;index:idx_user_task_active" json:"is_active"` // Part of composite indexes
 	UnassignedAt *time.Time     `json:"unassigned_at,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -48,6 +76,16 @@ type TaskAssignment struct {
 	AssignedByUser User `gorm:"foreignKey:AssignedBy" json:"assigned_by_user,omitempty" swaggerignore:"true"`
 }
 
+// ProjectMemberRole returns the user's role on the project, if they have an active assignment
+func ProjectMemberRole(db *gorm.DB, projectID, userID uint) (MemberRole, bool) {
+	var assignment ProjectAssignment
+	err := db.Select("role").Where("project_id = ? AND user_id = ? AND is_active = ?", projectID, userID, true).First(&assignment).Error
+	if err != nil {
+		return "", false
+	}
+	return assignment.Role, true
+}
+
 // TableName specifies the table name for ProjectAssignment
 func (ProjectAssignment) TableName() string {
 	return "project_assignments"
@@ -57,3 +95,25 @@ func (ProjectAssignment) TableName() string {
 func (TaskAssignment) TableName() string {
 	return "task_assignments"
 }
+
+// AssignmentAction is a change recorded in the assignment history
+type AssignmentAction string
+
+const (
+	AssignmentActionAssigned    AssignmentAction = "assigned"
+	AssignmentActionUnassigned  AssignmentAction = "unassigned"
+	AssignmentActionRoleChanged AssignmentAction = "role_changed"
+)
+
+// AssignmentEvent records a change of who works on a project or task, so the history survives
+// assignments being deactivated and reactivated
+type AssignmentEvent struct {
+	ID        uint             `gorm:"primarykey" json:"id"`
+	ProjectID *uint            `gorm:"index" json:"project_id"`
+	TaskID    *uint            `gorm:"index" json:"task_id"`
+	UserID    uint             `gorm:"not null;index" json:"user_id"`
+	Action    AssignmentAction `gorm:"type:varchar(20);not null" json:"action"`
+	Role      MemberRole       `gorm:"type:varchar(20)" json:"role,omitempty"` // Project role after the change
+	ActorID   uint             `gorm:"not null" json:"actor_id"`
+	CreatedAt time.Time        `json:"created_at"`
+}
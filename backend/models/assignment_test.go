@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestMemberRolePermissions(t *testing.T) {
+	tests := []struct {
+		role        MemberRole
+		valid       bool
+		manageTasks bool
+		logTime     bool
+	}{
+		{MemberRoleOwner, true, true, true},
+		{MemberRoleManager, true, true, true},
+		{MemberRoleContributor, true, false, true},
+		{MemberRoleViewer, true, false, false},
+		{"admin", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.IsValid(); got != tt.valid {
+				t.Errorf("IsValid() = %v, want %v", got, tt.valid)
+			}
+			if got := tt.role.CanManageTasks(); got != tt.manageTasks {
+				t.Errorf("CanManageTasks() = %v, want %v", got, tt.manageTasks)
+			}
+			if got := tt.role.CanLogTime(); got != tt.logTime {
+				t.Errorf("CanLogTime() = %v, want %v", got, tt.logTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Percentages is a list of whole percentages stored as JSON
+type Percentages = datatypes.JSONSlice[int]
+
+// DefaultBudgetThresholds are the usage percentages that notify when a project sets none
+var DefaultBudgetThresholds = []int{50, 80, 100}
+
+// BudgetKind says what a project budget is measured in
+type BudgetKind string
+
+const (
+	BudgetKindHours  BudgetKind = "hours"  // Logged hours against BudgetHours (or EstimatedHours)
+	BudgetKindAmount BudgetKind = "amount" // Cost of logged activities against BudgetAmount
+)
+
+// BudgetUsage is how much of a project budget has been used
+type BudgetUsage struct {
+	Kind    BudgetKind `json:"kind"`
+	Budget  float64    `json:"budget"`
+	Used    float64    `json:"used"`
+	Percent float64    `json:"percent"`
+}
+
+// ProjectBudgetAlert records a threshold a project has crossed, so it is notified only once.
+// It is removed when usage drops back below the threshold, re-arming the notification.
+type ProjectBudgetAlert struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	ProjectID uint       `gorm:"not null;uniqueIndex:idx_project_budget_alert" json:"project_id"`
+	Kind      BudgetKind `gorm:"type:varchar(20);not null;uniqueIndex:idx_project_budget_alert" json:"kind"`
+	Threshold int        `gorm:"not null;uniqueIndex:idx_project_budget_alert" json:"threshold"`
+	Used      float64    `json:"used"`
+	Budget    float64    `json:"budget"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// HourBudget returns the hour budget, falling back to the estimate
+func (p *Project) HourBudget() float64 {
+	if p.BudgetHours != nil {
+		return *p.BudgetHours
+	}
+	return p.EstimatedHours
+}
+
+// Thresholds returns the project's alert thresholds in ascending order
+func (p *Project) Thresholds() []int {
+	thresholds := DefaultBudgetThresholds
+	if len(p.BudgetThresholds) > 0 {
+		thresholds = p.BudgetThresholds
+	}
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// BudgetUsage returns the usage of every budget the project has
+func (p *Project) BudgetUsage() []BudgetUsage {
+	usage := []BudgetUsage{}
+	if budget := p.HourBudget(); budget > 0 {
+		usage = append(usage, BudgetUsage{BudgetKindHours, budget, p.UsedHours, p.UsedHours / budget * 100})
+	}
+	if p.BudgetAmount != nil && *p.BudgetAmount > 0 {
+		usage = append(usage, BudgetUsage{BudgetKindAmount, *p.BudgetAmount, p.SpentAmount, p.SpentAmount / *p.BudgetAmount * 100})
+	}
+	return usage
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Client is the customer projects are delivered to and invoiced to
+type Client struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"not null;uniqueIndex:idx_client_area_name,where:deleted_at IS NULL" json:"name"`
+	TaxID       string         `gorm:"index" json:"tax_id"` // NIT, VAT number, ...
+	Email       string         `json:"email"`
+	Phone       string         `json:"phone"`
+	Address     string         `gorm:"type:text" json:"address"`
+	ContactName string         `json:"contact_name"`
+	AreaID      *uint          `gorm:"index;uniqueIndex:idx_client_area_name,where:deleted_at IS NULL" json:"area_id"` // Nil for clients shared by every area
+	IsActive    bool           `json:"is_active"`
+	CreatedBy   uint           `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Area *Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// AvailableIn checks if projects of the given area can belong to the client
+func (cl *Client) AvailableIn(areaID *uint) bool {
+	return cl.AreaID == nil || (areaID != nil && *cl.AreaID == *areaID)
+}
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Task progress under the 50/50 rule: a task earns half its estimate when work starts and the rest when it is completed
+const startedTaskProgress = 0.5
+
+// EarnedProgress returns how much of the planned work the tasks represent as done (0-1), weighting each
+// task by its estimate. Tasks without an estimate weigh as much as the average estimated task.
+func EarnedProgress(tasks []Task) float64 {
+	if len(tasks) == 0 {
+		return 0
+	}
+
+	var estimated float64
+	var withEstimate int
+	for _, t := range tasks {
+		if t.EstimatedHours > 0 {
+			estimated += t.EstimatedHours
+			withEstimate++
+		}
+	}
+	defaultWeight := 1.0
+	if withEstimate > 0 {
+		defaultWeight = estimated / float64(withEstimate)
+	}
+
+	var total, earned float64
+	for _, t := range tasks {
+		weight := t.EstimatedHours
+		if weight <= 0 {
+			weight = defaultWeight
+		}
+		total += weight
+		switch t.Status {
+		case TaskStatusCompleted:
+			earned += weight
+		case TaskStatusInProgress, TaskStatusPaused:
+			earned += weight * startedTaskProgress
+		}
+	}
+	return earned / total
+}
+
+// PlannedProgress returns the share of the schedule elapsed on the date (0-1), assuming work is planned
+// evenly between start and due dates
+func PlannedProgress(start, due, date time.Time) float64 {
+	span := due.Sub(start)
+	if span <= 0 {
+		if date.Before(due) {
+			return 0
+		}
+		return 1
+	}
+	elapsed := float64(date.Sub(start)) / float64(span)
+	if elapsed < 0 {
+		return 0
+	}
+	if elapsed > 1 {
+		return 1
+	}
+	return elapsed
+}
+
+// NewEarnedValue computes earned-value figures from the budget at completion, the earned progress (0-1),
+// the planned progress (nil without a schedule) and the actual cost, all in the same unit
+func NewEarnedValue(unit string, budget, progress float64, planned *float64, actual float64) EarnedValue {
+	ev := EarnedValue{
+		Unit:               unit,
+		BudgetAtCompletion: budget,
+		EarnedValue:        budget * progress,
+		ActualCost:         actual,
+	}
+	ev.CostVariance = ev.EarnedValue - ev.ActualCost
+	if ev.ActualCost > 0 && ev.EarnedValue > 0 {
+		cpi := ev.EarnedValue / ev.ActualCost
+		ev.CPI = &cpi
+		ev.EstimateAtCompletion = budget / cpi
+	} else {
+		// Nothing earned (or spent) yet: what was spent plus the whole remaining budget
+		ev.EstimateAtCompletion = ev.ActualCost + budget - ev.EarnedValue
+	}
+	ev.EstimateToComplete = ev.EstimateAtCompletion - ev.ActualCost
+	if ev.EstimateToComplete < 0 {
+		ev.EstimateToComplete = 0
+	}
+	ev.VarianceAtCompletion = budget - ev.EstimateAtCompletion
+
+	if planned != nil {
+		pv := budget * *planned
+		sv := ev.EarnedValue - pv
+		ev.PlannedValue = &pv
+		ev.ScheduleVariance = &sv
+		if pv > 0 {
+			spi := ev.EarnedValue / pv
+			ev.SPI = &spi
+		}
+	}
+	return ev
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEarnedProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []Task
+		want  float64
+	}{
+		{"no tasks", nil, 0},
+		{"none started", []Task{{Status: TaskStatusAssigned, EstimatedHours: 10}}, 0},
+		{"all completed", []Task{{Status: TaskStatusCompleted, EstimatedHours: 4}, {Status: TaskStatusCompleted, EstimatedHours: 6}}, 1},
+		{"started task earns half", []Task{{Status: TaskStatusInProgress, EstimatedHours: 10}}, 0.5},
+		{"paused task earns half", []Task{{Status: TaskStatusPaused, EstimatedHours: 10}}, 0.5},
+		{
+			"weighted by estimate",
+			[]Task{{Status: TaskStatusCompleted, EstimatedHours: 30}, {Status: TaskStatusAssigned, EstimatedHours: 10}},
+			0.75,
+		},
+		{
+			"unestimated task weighs the average",
+			[]Task{{Status: TaskStatusCompleted, EstimatedHours: 10}, {Status: TaskStatusCompleted, EstimatedHours: 30}, {Status: TaskStatusAssigned}},
+			40.0 / 60.0,
+		},
+		{
+			"no estimates weigh the same",
+			[]Task{{Status: TaskStatusCompleted}, {Status: TaskStatusAssigned}, {Status: TaskStatusInProgress}, {Status: TaskStatusAssigned}},
+			1.5 / 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EarnedProgress(tt.tasks); !almostEqual(got, tt.want) {
+				t.Errorf("EarnedProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlannedProgress(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		start, due, now time.Time
+		want            float64
+	}{
+		{"before start", start, due, start.AddDate(0, 0, -3), 0},
+		{"at start", start, due, start, 0},
+		{"halfway", start, due, start.AddDate(0, 0, 5), 0.5},
+		{"at due date", start, due, due, 1},
+		{"after due date", start, due, due.AddDate(0, 1, 0), 1},
+		{"empty schedule before due", due, due, start, 0},
+		{"empty schedule on due", due, due, due, 1},
+		{"due before start", due, start, due, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlannedProgress(tt.start, tt.due, tt.now); !almostEqual(got, tt.want) {
+				t.Errorf("PlannedProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEarnedValue(t *testing.T) {
+	half := 0.5
+	none := 0.0
+
+	tests := []struct {
+		name     string
+		budget   float64
+		progress float64
+		planned  *float64
+		actual   float64
+		want     EarnedValue
+	}{
+		{
+			name:   "nothing earned or spent",
+			budget: 100,
+			want:   EarnedValue{BudgetAtCompletion: 100, EstimateAtCompletion: 100, EstimateToComplete: 100},
+		},
+		{
+			name:   "spent without progress",
+			budget: 100, actual: 20,
+			want: EarnedValue{BudgetAtCompletion: 100, ActualCost: 20, CostVariance: -20,
+				EstimateAtCompletion: 120, EstimateToComplete: 100, VarianceAtCompletion: -20},
+		},
+		{
+			name:   "on budget",
+			budget: 100, progress: 0.4, actual: 40,
+			want: EarnedValue{BudgetAtCompletion: 100, EarnedValue: 40, ActualCost: 40,
+				EstimateAtCompletion: 100, EstimateToComplete: 60},
+		},
+		{
+			name:   "over budget",
+			budget: 100, progress: 0.5, actual: 100,
+			want: EarnedValue{BudgetAtCompletion: 100, EarnedValue: 50, ActualCost: 100, CostVariance: -50,
+				EstimateAtCompletion: 200, EstimateToComplete: 100, VarianceAtCompletion: -100},
+		},
+		{
+			name:   "estimate to complete never negative",
+			budget: 100, progress: 1, actual: 150,
+			want: EarnedValue{BudgetAtCompletion: 100, EarnedValue: 100, ActualCost: 150, CostVariance: -50,
+				EstimateAtCompletion: 150, VarianceAtCompletion: -50},
+		},
+		{
+			name:   "no budget",
+			budget: 0, progress: 0.5, actual: 10,
+			want: EarnedValue{ActualCost: 10, CostVariance: -10, EstimateAtCompletion: 10, VarianceAtCompletion: -10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewEarnedValue("hours", tt.budget, tt.progress, tt.planned, tt.actual)
+			if got.Unit != "hours" {
+				t.Errorf("Unit = %q, want hours", got.Unit)
+			}
+			checks := []struct {
+				field     string
+				got, want float64
+			}{
+				{"BudgetAtCompletion", got.BudgetAtCompletion, tt.want.BudgetAtCompletion},
+				{"EarnedValue", got.EarnedValue, tt.want.EarnedValue},
+				{"ActualCost", got.ActualCost, tt.want.ActualCost},
+				{"CostVariance", got.CostVariance, tt.want.CostVariance},
+				{"EstimateAtCompletion", got.EstimateAtCompletion, tt.want.EstimateAtCompletion},
+				{"EstimateToComplete", got.EstimateToComplete, tt.want.EstimateToComplete},
+				{"VarianceAtCompletion", got.VarianceAtCompletion, tt.want.VarianceAtCompletion},
+			}
+			for _, check := range checks {
+				if !almostEqual(check.got, check.want) {
+					t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+				}
+			}
+			if got.PlannedValue != nil || got.ScheduleVariance != nil || got.SPI != nil {
+				t.Errorf("schedule figures set without a planned progress")
+			}
+		})
+	}
+
+	t.Run("cost performance index", func(t *testing.T) {
+		got := NewEarnedValue("hours", 100, 0.5, nil, 25)
+		if got.CPI == nil || !almostEqual(*got.CPI, 2) {
+			t.Fatalf("CPI = %v, want 2", got.CPI)
+		}
+		if noSpend := NewEarnedValue("hours", 100, 0.5, nil, 0); noSpend.CPI != nil {
+			t.Errorf("CPI = %v without actual cost, want nil", *noSpend.CPI)
+		}
+	})
+
+	t.Run("schedule figures", func(t *testing.T) {
+		got := NewEarnedValue("hours", 100, 0.25, &half, 10)
+		if got.PlannedValue == nil || !almostEqual(*got.PlannedValue, 50) {
+			t.Fatalf("PlannedValue = %v, want 50", got.PlannedValue)
+		}
+		if got.ScheduleVariance == nil || !almostEqual(*got.ScheduleVariance, -25) {
+			t.Errorf("ScheduleVariance = %v, want -25", got.ScheduleVariance)
+		}
+		if got.SPI == nil || !almostEqual(*got.SPI, 0.5) {
+			t.Errorf("SPI = %v, want 0.5", got.SPI)
+		}
+
+		notStarted := NewEarnedValue("hours", 100, 0, &none, 0)
+		if notStarted.SPI != nil {
+			t.Errorf("SPI = %v with no planned value, want nil", *notStarted.SPI)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MappingMatchType is what an identity mapping rule compares
+type MappingMatchType string
+
+const (
+	MappingMatchGroup  MappingMatchType = "group"  // A group reported by the identity provider (object ID for Microsoft)
+	MappingMatchDomain MappingMatchType = "domain" // The domain of the user's email, when the provider verified it
+)
+
+// Membership sources: memberships created by mapping rules are re-synced on every SSO login
+const (
+	MembershipSourceManual = "manual"
+	MembershipSourceSSO    = "sso"
+)
+
+// IdentityMappingRule places users signing in through an identity provider in an area with a role
+type IdentityMappingRule struct {
+	ID         uint             `gorm:"primarykey" json:"id"`
+	Name       string           `gorm:"not null" json:"name"`
+	Provider   string           `gorm:"type:varchar(20);index" json:"provider"` // Empty matches every provider
+	MatchType  MappingMatchType `gorm:"type:varchar(20);not null" json:"match_type"`
+	MatchValue string           `gorm:"not null" json:"match_value"` // Group name/ID or email domain, compared case-insensitively
+	AreaID     uint             `gorm:"not null;index" json:"area_id"`
+	Role       Role             `gorm:"type:varchar(20);not null;default:'user'" json:"role"` // 'admin' (group rules only) or 'user' within the area
+	Priority   int              `gorm:"default:0" json:"priority"`                            // Highest priority rule picks the primary area
+	IsActive   bool             `json:"is_active"`
+	CreatedBy  uint             `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt   `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Area *Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// Matches checks if the rule applies to a user of the provider with the given email and groups.
+// Domain rules only apply to email addresses the provider verified.
+func (r *IdentityMappingRule) Matches(provider, email string, emailVerified bool, groups []string) bool {
+	if !r.IsActive || (r.Provider != "" && r.Provider != provider) {
+		return false
+	}
+
+	switch r.MatchType {
+	case MappingMatchDomain:
+		if !emailVerified {
+			return false
+		}
+		at := strings.LastIndex(email, "@")
+		return at >= 0 && strings.EqualFold(email[at+1:], strings.TrimPrefix(r.MatchValue, "@"))
+	case MappingMatchGroup:
+		for _, group := range groups {
+			if strings.EqualFold(group, r.MatchValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package models
+
+import "testing"
+
+func TestIdentityMappingRuleMatches(t *testing.T) {
+	const groupID = "3f2504e0-4f89-11d3-9a0c-0305e82c3301"
+	groups := []string{"0a1b2c3d-0000-0000-0000-000000000000", groupID}
+
+	tests := []struct {
+		name          string
+		rule          IdentityMappingRule
+		provider      string
+		email         string
+		emailVerified bool
+		groups        []string
+		want          bool
+	}{
+		{
+			name:     "group",
+			rule:     IdentityMappingRule{MatchType: MappingMatchGroup, MatchValue: groupID, IsActive: true},
+			provider: "microsoft", groups: groups, want: true,
+		},
+		{
+			name:     "group ignores case",
+			rule:     IdentityMappingRule{MatchType: MappingMatchGroup, MatchValue: "3F2504E0-4F89-11D3-9A0C-0305E82C3301", IsActive: true},
+			provider: "microsoft", groups: groups, want: true,
+		},
+		{
+			name:     "group not held",
+			rule:     IdentityMappingRule{MatchType: MappingMatchGroup, MatchValue: "Engineering", IsActive: true},
+			provider: "microsoft", groups: groups, want: false,
+		},
+		{
+			name:     "no groups",
+			rule:     IdentityMappingRule{MatchType: MappingMatchGroup, MatchValue: groupID, IsActive: true},
+			provider: "microsoft", want: false,
+		},
+		{
+			name:     "verified domain",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "example.com", IsActive: true},
+			provider: "microsoft", email: "ana@Example.com", emailVerified: true, want: true,
+		},
+		{
+			name:     "domain saved with @",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "@example.com", IsActive: true},
+			provider: "keycloak", email: "ana@example.com", emailVerified: true, want: true,
+		},
+		{
+			name:     "unverified domain",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "example.com", IsActive: true},
+			provider: "microsoft", email: "ana@example.com", want: false,
+		},
+		{
+			name:     "subdomain",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "example.com", IsActive: true},
+			provider: "microsoft", email: "ana@mail.example.com", emailVerified: true, want: false,
+		},
+		{
+			name:     "domain in the local part",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "example.com", IsActive: true},
+			provider: "microsoft", email: "example.com@evil.test", emailVerified: true, want: false,
+		},
+		{
+			name:     "email without domain",
+			rule:     IdentityMappingRule{MatchType: MappingMatchDomain, MatchValue: "example.com", IsActive: true},
+			provider: "microsoft", email: "ana", emailVerified: true, want: false,
+		},
+		{
+			name:     "same provider",
+			rule:     IdentityMappingRule{Provider: "microsoft", MatchType: MappingMatchGroup, MatchValue: groupID, IsActive: true},
+			provider: "microsoft", groups: groups, want: true,
+		},
+		{
+			name:     "other provider",
+			rule:     IdentityMappingRule{Provider: "keycloak", MatchType: MappingMatchGroup, MatchValue: groupID, IsActive: true},
+			provider: "microsoft", groups: groups, want: false,
+		},
+		{
+			name:     "inactive rule",
+			rule:     IdentityMappingRule{MatchType: MappingMatchGroup, MatchValue: groupID},
+			provider: "microsoft", groups: groups, want: false,
+		},
+		{
+			name:     "unknown match type",
+			rule:     IdentityMappingRule{MatchType: "email", MatchValue: "ana@example.com", IsActive: true},
+			provider: "microsoft", email: "ana@example.com", emailVerified: true, want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.provider, tt.email, tt.emailVerified, tt.groups); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ImpersonationSession records a SuperAdmin acting as another user for support purposes
+type ImpersonationSession struct {
+	ID                 uint       `gorm:"primarykey" json:"id"`
+	ImpersonatorID     uint       `gorm:"not null;index" json:"impersonator_id"`
+	ImpersonatedUserID uint       `gorm:"not null;index" json:"impersonated_user_id"`
+	Reason             string     `gorm:"type:text;not null" json:"reason"`
+	ReadOnly           bool       `json:"read_only"` // Blocks write requests made with the token
+	ExpiresAt          time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt            *time.Time `json:"ended_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+
+	// Relations
+	Impersonator     User `gorm:"foreignKey:ImpersonatorID" json:"impersonator,omitempty" swaggerignore:"true"`
+	ImpersonatedUser User `gorm:"foreignKey:ImpersonatedUserID" json:"impersonated_user,omitempty" swaggerignore:"true"`
+}
+
+// IsActive checks if the session has not been ended and has not expired
+func (s *ImpersonationSession) IsActive() bool {
+	return s.EndedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// ImpersonationAuditLog records a single request made with an impersonation token
+type ImpersonationAuditLog struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	SessionID          uint      `gorm:"not null;index" json:"session_id"`
+	ImpersonatorID     uint      `gorm:"not null;index" json:"impersonator_id"`
+	ImpersonatedUserID uint      `gorm:"not null;index" json:"impersonated_user_id"`
+	Method             string    `gorm:"type:varchar(10);not null" json:"method"`
+	Path               string    `gorm:"not null" json:"path"`
+	Query              string    `gorm:"type:text" json:"query,omitempty"`
+	StatusCode         int       `json:"status_code"`
+	Blocked            bool      `gorm:"default:false" json:"blocked"` // Write request rejected by a read-only session
+	ClientIP           string    `gorm:"type:varchar(45)" json:"client_ip"`
+	CreatedAt          time.Time `json:"created_at"`
+}
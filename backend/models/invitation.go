@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InvitationStatus represents the lifecycle state of an invitation
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+	InvitationStatusExpired  InvitationStatus = "expired"
+)
+
+// Invitation represents an admin-issued, single-use invitation to register
+type Invitation struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	Email          string         `gorm:"not null;index" json:"email"`
+	TokenHash      string         `gorm:"uniqueIndex;not null" json:"-"` // SHA-256 of the token, the token itself is never stored
+	Role           Role           `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+	AreaID         *uint          `gorm:"index" json:"area_id"`
+	InvitedBy      uint           `gorm:"not null;index" json:"invited_by"`
+	ExpiresAt      time.Time      `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time     `json:"accepted_at,omitempty"`
+	AcceptedUserID *uint          `json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time     `json:"revoked_at,omitempty"`
+	LastSentAt     *time.Time     `json:"last_sent_at,omitempty"`
+	SendCount      int            `gorm:"default:0" json:"send_count"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Computed
+	Status InvitationStatus `gorm:"-" json:"status"`
+
+	// Relations
+	Area          *Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+	InvitedByUser User  `gorm:"foreignKey:InvitedBy" json:"invited_by_user,omitempty" swaggerignore:"true"`
+}
+
+// AfterFind hook to compute the invitation status
+func (i *Invitation) AfterFind(tx *gorm.DB) error {
+	i.Status = i.CurrentStatus()
+	return nil
+}
+
+// CurrentStatus returns the status of the invitation at this moment
+func (i *Invitation) CurrentStatus() InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		return InvitationStatusRevoked
+	case time.Now().After(i.ExpiresAt):
+		return InvitationStatusExpired
+	default:
+		return InvitationStatusPending
+	}
+}
+
+// IsUsable checks if the invitation can still be used to register
+func (i *Invitation) IsUsable() bool {
+	return i.CurrentStatus() == InvitationStatusPending
+}
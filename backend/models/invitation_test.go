@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvitationCurrentStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		invitation Invitation
+		want       InvitationStatus
+	}{
+		{"pending", Invitation{ExpiresAt: future}, InvitationStatusPending},
+		{"expired", Invitation{ExpiresAt: past}, InvitationStatusExpired},
+		{"accepted", Invitation{ExpiresAt: future, AcceptedAt: &past}, InvitationStatusAccepted},
+		{"revoked", Invitation{ExpiresAt: future, RevokedAt: &past}, InvitationStatusRevoked},
+		{"accepted before expiring", Invitation{ExpiresAt: past, AcceptedAt: &past}, InvitationStatusAccepted},
+		{"revoked before expiring", Invitation{ExpiresAt: past, RevokedAt: &past}, InvitationStatusRevoked},
+		{"accepted wins over revoked", Invitation{ExpiresAt: future, AcceptedAt: &past, RevokedAt: &past}, InvitationStatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.invitation.CurrentStatus(); got != tt.want {
+				t.Errorf("CurrentStatus() = %q, want %q", got, tt.want)
+			}
+			if usable := tt.invitation.IsUsable(); usable != (tt.want == InvitationStatusPending) {
+				t.Errorf("IsUsable() = %v for a %s invitation", usable, tt.want)
+			}
+		})
+	}
+}
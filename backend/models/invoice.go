@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvoiceStatus represents the status of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusIssued InvoiceStatus = "issued"
+	InvoiceStatusVoid   InvoiceStatus = "void" // Its activities are released and can be invoiced again
+)
+
+// Invoice bills a client for the uninvoiced billable activities of a period.
+// Invoices are never deleted; voiding one releases its activities.
+type Invoice struct {
+	ID         uint          `gorm:"primarykey" json:"id"`
+	Number     string        `gorm:"uniqueIndex:idx_invoice_number,where:number <> ''" json:"number"` // Assigned from the ID right after creation
+	ClientID   uint          `gorm:"not null;index" json:"client_id"`
+	AreaID     *uint         `gorm:"index" json:"area_id"` // Area whose activities are invoiced; nil for every area
+	PeriodFrom time.Time     `gorm:"type:date;not null" json:"period_from"`
+	PeriodTo   time.Time     `gorm:"type:date;not null" json:"period_to"`
+	Status     InvoiceStatus `gorm:"type:varchar(20);not null;default:'issued';index" json:"status"`
+	Currency   string        `gorm:"type:varchar(10);not null" json:"currency"`
+	Hours      float64       `gorm:"default:0" json:"hours"`
+	Total      float64       `gorm:"default:0" json:"total"`
+	Notes      string        `gorm:"type:text" json:"notes"`
+	IssuedAt   time.Time     `json:"issued_at"`
+	VoidedAt   *time.Time    `json:"voided_at,omitempty"`
+	VoidedBy   *uint         `json:"voided_by,omitempty"`
+	CreatedBy  uint          `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+
+	// Relations
+	Client Client        `gorm:"foreignKey:ClientID" json:"client,omitempty" swaggerignore:"true"`
+	Lines  []InvoiceLine `gorm:"foreignKey:InvoiceID" json:"lines,omitempty"`
+}
+
+// InvoiceLine groups the invoiced activities of a project task (or of the project when they have no task)
+type InvoiceLine struct {
+	ID            uint    `gorm:"primarykey" json:"id"`
+	InvoiceID     uint    `gorm:"not null;index" json:"invoice_id"`
+	ProjectID     uint    `gorm:"not null" json:"project_id"`
+	TaskID        *uint   `json:"task_id"`
+	ProjectName   string  `json:"project_name"`
+	TaskName      string  `json:"task_name"`
+	Description   string  `json:"description"`
+	ActivityCount int     `json:"activity_count"`
+	Hours         float64 `json:"hours"`
+	Rate          float64 `json:"rate"` // Average bill rate of the line (amount / hours)
+	Amount        float64 `json:"amount"`
+}
+
+// SetLines sets the line items, completing their description and average rate, and the invoice totals
+func (i *Invoice) SetLines(lines []InvoiceLine) {
+	i.Lines = lines
+	i.Hours, i.Total = 0, 0
+	for j := range i.Lines {
+		line := &i.Lines[j]
+		line.Description = line.ProjectName
+		if line.TaskName != "" {
+			line.Description += " - " + line.TaskName
+		}
+		if line.Hours > 0 {
+			line.Rate = line.Amount / line.Hours
+		}
+		i.Hours += line.Hours
+		i.Total += line.Amount
+	}
+}
+
+// AssignNumber sets the invoice number from its ID and issue year, e.g. INV-2025-00042
+func (i *Invoice) AssignNumber() {
+	i.Number = fmt.Sprintf("INV-%d-%05d", i.IssuedAt.Year(), i.ID)
+}
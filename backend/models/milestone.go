@@ -0,0 +1,70 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MilestoneStatus is computed from the milestone's tasks and due date
+type MilestoneStatus string
+
+const (
+	MilestoneStatusPending    MilestoneStatus = "pending"     // Ninguna tarea iniciada
+	MilestoneStatusInProgress MilestoneStatus = "in_progress" // Alguna tarea iniciada o completada
+	MilestoneStatusCompleted  MilestoneStatus = "completed"   // Todas las tareas completadas
+	MilestoneStatusOverdue    MilestoneStatus = "overdue"     // Vencido sin completar
+)
+
+// Milestone is a checkpoint of a project, reached when all its linked tasks are completed
+type Milestone struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	ProjectID   uint           `gorm:"not null;index" json:"project_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	DueDate     time.Time      `gorm:"type:date;not null;index" json:"due_date"`
+	CreatedBy   uint           `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Computed from the linked tasks (see Evaluate)
+	Status         MilestoneStatus `gorm:"-" json:"status"`
+	Progress       float64         `gorm:"-" json:"progress"` // Percentage, counting started tasks by half
+	TasksTotal     int             `gorm:"-" json:"tasks_total"`
+	TasksCompleted int             `gorm:"-" json:"tasks_completed"`
+
+	// Relations
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty" swaggerignore:"true"`
+	Tasks   []Task   `gorm:"foreignKey:MilestoneID" json:"tasks,omitempty" swaggerignore:"true"`
+}
+
+// Evaluate computes the milestone's progress and status from its tasks as of the given calendar day (UTC midnight)
+func (m *Milestone) Evaluate(tasks []Task, today time.Time) {
+	m.TasksTotal = len(tasks)
+	m.TasksCompleted = 0
+	started := false
+	for _, t := range tasks {
+		switch t.Status {
+		case TaskStatusCompleted:
+			m.TasksCompleted++
+			started = true
+		case TaskStatusInProgress, TaskStatusPaused:
+			started = true
+		}
+	}
+	m.Progress = math.Round(EarnedProgress(tasks)*1000) / 10
+
+	due := time.Date(m.DueDate.Year(), m.DueDate.Month(), m.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case m.TasksTotal > 0 && m.TasksCompleted == m.TasksTotal:
+		m.Status = MilestoneStatusCompleted
+	case today.After(due):
+		m.Status = MilestoneStatusOverdue
+	case started:
+		m.Status = MilestoneStatusInProgress
+	default:
+		m.Status = MilestoneStatusPending
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMilestoneEvaluate(t *testing.T) {
+	due := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	onDue := due
+	before := due.AddDate(0, 0, -1)
+	after := due.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name          string
+		tasks         []Task
+		today         time.Time
+		wantStatus    MilestoneStatus
+		wantProgress  float64
+		wantCompleted int
+	}{
+		{"no tasks", nil, before, MilestoneStatusPending, 0, 0},
+		{"no tasks past due", nil, after, MilestoneStatusOverdue, 0, 0},
+		{"nothing started", []Task{{Status: TaskStatusAssigned}, {Status: TaskStatusAssigned}}, before, MilestoneStatusPending, 0, 0},
+		{"task in progress", []Task{{Status: TaskStatusInProgress}, {Status: TaskStatusAssigned}}, before, MilestoneStatusInProgress, 25, 0},
+		{"paused task counts as started", []Task{{Status: TaskStatusPaused}}, before, MilestoneStatusInProgress, 50, 0},
+		{"one of three completed", []Task{{Status: TaskStatusCompleted}, {Status: TaskStatusAssigned}, {Status: TaskStatusAssigned}}, before, MilestoneStatusInProgress, 33.3, 1},
+		{"all completed", []Task{{Status: TaskStatusCompleted}, {Status: TaskStatusCompleted}}, before, MilestoneStatusCompleted, 100, 2},
+		{"completed late is still completed", []Task{{Status: TaskStatusCompleted}}, after, MilestoneStatusCompleted, 100, 1},
+		{"due today is not overdue", []Task{{Status: TaskStatusInProgress}}, onDue, MilestoneStatusInProgress, 50, 0},
+		{"past due and unfinished", []Task{{Status: TaskStatusCompleted}, {Status: TaskStatusInProgress}}, after, MilestoneStatusOverdue, 75, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The due date is stored as a date; a time of day or zone must not move it
+			m := Milestone{DueDate: time.Date(2026, 6, 15, 18, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))}
+			m.Evaluate(tt.tasks, tt.today)
+
+			if m.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", m.Status, tt.wantStatus)
+			}
+			if !almostEqual(m.Progress, tt.wantProgress) {
+				t.Errorf("Progress = %v, want %v", m.Progress, tt.wantProgress)
+			}
+			if m.TasksTotal != len(tt.tasks) || m.TasksCompleted != tt.wantCompleted {
+				t.Errorf("tasks = %d/%d completed, want %d/%d", m.TasksCompleted, m.TasksTotal, tt.wantCompleted, len(tt.tasks))
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// NotificationType identifies what a notification is about
+type NotificationType string
+
+const (
+	NotificationTypeBudgetThreshold NotificationType = "budget_threshold"
+)
+
+// Notification is an in-app message for a user
+type Notification struct {
+	ID        uint             `gorm:"primarykey" json:"id"`
+	UserID    uint             `gorm:"not null;index" json:"user_id"`
+	Type      NotificationType `gorm:"type:varchar(50);not null;index" json:"type"`
+	Title     string           `gorm:"not null" json:"title"`
+	Message   string           `gorm:"type:text" json:"message"`
+	ProjectID *uint            `gorm:"index" json:"project_id,omitempty"`
+	ReadAt    *time.Time       `gorm:"index" json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Permission is a named capability that can be granted to a role
+type Permission string
+
+const (
+	PermUsersView           Permission = "users.view"
+	PermUsersManage         Permission = "users.manage"
+	PermUsersApprove        Permission = "users.approve"
+	PermInvitationsManage   Permission = "invitations.manage"
+	PermAreasManage         Permission = "areas.manage"
+	PermRolesManage         Permission = "roles.manage"
+	PermRolesAssign         Permission = "roles.assign"
+	PermProjectsView        Permission = "projects.view"
+	PermProjectsManage      Permission = "projects.manage"
+	PermTasksView           Permission = "tasks.view"
+	PermTasksManage         Permission = "tasks.manage"
+	PermActivitiesView      Permission = "activities.view"
+	PermActivitiesLog       Permission = "activities.log"
+	PermStatsView           Permission = "stats.view"
+	PermTimesheetsApprove   Permission = "timesheets.approve"
+	PermSkillsManage        Permission = "skills.manage"
+	PermActivityTypesManage Permission = "activity_types.manage"
+	PermRatesManage         Permission = "rates.manage"
+	PermClientsManage       Permission = "clients.manage"
+	PermInvoicesManage      Permission = "invoices.manage"
+)
+
+// PermissionInfo describes a permission in the catalog
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// PermissionCatalog lists every permission that can be granted to a role
+var PermissionCatalog = []PermissionInfo{
+	{PermUsersView, "View users of the area"},
+	{PermUsersManage, "Create, update and deactivate users of the area"},
+	{PermUsersApprove, "Approve or reject pending accounts (global only)"},
+	{PermInvitationsManage, "Invite people to the area"},
+	{PermAreasManage, "Create, update and delete areas (global only)"},
+	{PermRolesManage, "Define custom roles (global only)"},
+	{PermRolesAssign, "Assign roles to users of the area"},
+	{PermProjectsView, "View all projects of the area"},
+	{PermProjectsManage, "Create, update and delete projects of the area"},
+	{PermTasksView, "View all tasks of the area"},
+	{PermTasksManage, "Create, update and delete tasks of the area"},
+	{PermActivitiesView, "View activities logged by users of the area"},
+	{PermActivitiesLog, "Log own activities"},
+	{PermStatsView, "View statistics of the area"},
+	{PermTimesheetsApprove, "Approve timesheets of the area"},
+	{PermSkillsManage, "Maintain the skills catalog of the area"},
+	{PermActivityTypesManage, "Maintain the activity types of the area"},
+	{PermRatesManage, "Maintain cost and bill rates of users and projects of the area"},
+	{PermClientsManage, "Maintain the clients of the area"},
+	{PermInvoicesManage, "Invoice billable activities of the area"},
+}
+
+// IsGlobalOnly checks if the permission can only be granted for every area. Areas, role definitions
+// and the approval queue are not tied to one area, so an area-scoped grant would act everywhere.
+func (p Permission) IsGlobalOnly() bool {
+	switch p {
+	case PermAreasManage, PermRolesManage, PermUsersApprove:
+		return true
+	}
+	return false
+}
+
+// IsValidPermission checks if a permission exists in the catalog
+func IsValidPermission(p Permission) bool {
+	for _, info := range PermissionCatalog {
+		if info.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleDefinition is a custom role made of named permissions
+type RoleDefinition struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"not null;uniqueIndex:idx_role_definition_name,where:deleted_at IS NULL" json:"name"`
+	Description string         `json:"description"`
+	CreatedBy   uint           `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions,omitempty"`
+}
+
+// RolePermission links a permission to a role definition
+type RolePermission struct {
+	ID         uint       `gorm:"primarykey" json:"-"`
+	RoleID     uint       `gorm:"not null;index:idx_role_permission,unique" json:"-"`
+	Permission Permission `gorm:"type:varchar(50);not null;index:idx_role_permission,unique" json:"permission"`
+}
+
+// RoleAssignment grants a role definition to a user, either globally (AreaID nil) or for one area
+type RoleAssignment struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	UserID    uint           `gorm:"not null;index" json:"user_id"`
+	RoleID    uint           `gorm:"not null;index" json:"role_id"`
+	AreaID    *uint          `gorm:"index" json:"area_id"` // nil means the role applies to every area
+	GrantedBy uint           `gorm:"not null" json:"granted_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	User User           `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
+	Role RoleDefinition `gorm:"foreignKey:RoleID" json:"role,omitempty" swaggerignore:"true"`
+	Area *Area          `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// PermissionNames returns the permission names of the role
+func (r *RoleDefinition) PermissionNames() []Permission {
+	names := make([]Permission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Permission)
+	}
+	return names
+}
@@ -42,6 +42,11 @@ type Project struct {
 	Description       string          `gorm:"type:text" json:"description"`
 	CreatedBy         uint            `gorm:"not null;index" json:"created_by"`                                       // Indexed for queries by creator
 	AreaID            *uint           `gorm:"index" json:"area_id"`                                                   // Indexed for area filtering
+	ClientID          *uint           `gorm:"index" json:"client_id"`                                                 // Client the project is delivered and invoiced to
+	BudgetHours       *float64        `json:"budget_hours"`                                                           // Hour budget; nil uses EstimatedHours
+	BudgetAmount      *float64        `json:"budget_amount"`                                                          // Money budget, compared with the cost of logged activities
+	BudgetThresholds  Percentages     `json:"budget_thresholds" swaggertype:"array,integer"`                          // Usage percentages that notify; empty uses 50, 80 and 100
+	SpentAmount       float64         `gorm:"default:0" json:"spent_amount"`                                          // Cost of logged activities
 	ProjectType       ProjectType     `gorm:"type:varchar(20);not null;default:'personal';index" json:"project_type"` // Indexed for filtering
 	Status            ProjectStatus   `gorm:"type:varchar(20);not null;default:'unassigned';index" json:"status"`     // Indexed for status filtering
 	Priority          ProjectPriority `gorm:"type:varchar(20);not null;default:'medium'" json:"priority"`
@@ -52,7 +57,8 @@ type Project struct {
 	StartDate         *time.Time      `json:"start_date"`
 	DueDate           *time.Time      `json:"due_date"`
 	CompletedAt       *time.Time      `json:"completed_at"`
-	IsActive          bool            `gorm:"default:true;index" json:"is_active"` // Indexed for active/inactive filtering
+	IsActive          bool            `gorm:"index" json:"is_active"` // Indexed for active/inactive filtering
+	Billable          *bool           `json:"billable"`               // Default billable flag of its activities; nil follows the activity type
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt  `gorm:"index" json:"-" swaggerignore:"true"`
@@ -60,11 +66,15 @@ type Project struct {
 	// Relations
 	Creator            User                `gorm:"foreignKey:CreatedBy" json:"creator,omitempty" swaggerignore:"true"`
 	Area               *Area               `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+	Client             *Client             `gorm:"foreignKey:ClientID" json:"client,omitempty" swaggerignore:"true"`
 	Tasks              []Task              `gorm:"foreignKey:ProjectID" json:"tasks,omitempty" swaggerignore:"true"`
 	Activities         []Activity          `gorm:"foreignKey:ProjectID" json:"activities,omitempty" swaggerignore:"true"`
 	Comments           []Comment           `gorm:"foreignKey:ProjectID" json:"comments,omitempty" swaggerignore:"true"`
 	AssignedUsers      []User              `gorm:"many2many:project_assignments;joinForeignKey:ProjectID;joinReferences:UserID" json:"assigned_users,omitempty" swaggerignore:"true"`
 	ProjectAssignments []ProjectAssignment `gorm:"foreignKey:ProjectID" json:"project_assignments,omitempty" swaggerignore:"true"`
+	RequiredSkills     []ProjectSkill      `gorm:"foreignKey:ProjectID" json:"required_skills,omitempty" swaggerignore:"true"`
+	Milestones         []Milestone         `gorm:"foreignKey:ProjectID" json:"milestones,omitempty" swaggerignore:"true"`
+	SharedAreas        []ProjectArea       `gorm:"foreignKey:ProjectID" json:"shared_areas,omitempty" swaggerignore:"true"` // Participating areas besides the host area
 }
 
 // BeforeSave hook to update project metrics
@@ -103,20 +113,24 @@ func (p *Project) CanRegisterActivity() bool {
 	return p.Status == ProjectStatusInProgress || p.Status == ProjectStatusCompleted
 }
 
-// UpdateUsedHours updates the used hours from tasks and activities
+// UpdateUsedHours updates the used hours and spent amount from activities
 func (p *Project) UpdateUsedHours(db *gorm.DB) error {
-	var total float64
+	var totals struct {
+		Hours  float64
+		Amount float64
+	}
 
-	// Sum hours from all activities related to this project
+	// Sum hours and cost from all activities related to this project
 	err := db.Model(&Activity{}).
 		Where("project_id = ?", p.ID).
-		Select("COALESCE(SUM(execution_time), 0)").
-		Scan(&total).Error
+		Select("COALESCE(SUM(execution_time), 0) AS hours, COALESCE(SUM(cost_amount), 0) AS amount").
+		Scan(&totals).Error
 
 	if err != nil {
 		return err
 	}
 
-	p.UsedHours = total
+	p.UsedHours = totals.Hours
+	p.SpentAmount = totals.Amount
 	return db.Save(p).Error
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProjectArea shares an area project with another area. The project's AreaID is its host area;
+// shared areas participate: their members can be assigned and their admins can assign them.
+type ProjectArea struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ProjectID uint      `gorm:"not null;index:idx_project_area,unique" json:"project_id"`
+	AreaID    uint      `gorm:"not null;index:idx_project_area,unique;index" json:"area_id"`
+	AddedBy   uint      `gorm:"not null" json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	Area Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// SharedAreaIDs returns the IDs of the areas participating in the project, besides its host area
+func SharedAreaIDs(db *gorm.DB, projectID uint) []uint {
+	var areaIDs []uint
+	db.Model(&ProjectArea{}).Where("project_id = ?", projectID).Order("id ASC").Pluck("area_id", &areaIDs)
+	return areaIDs
+}
+
+// ProjectAreaIDs returns the host area of the project followed by the areas it is shared with
+func ProjectAreaIDs(db *gorm.DB, project *Project) []uint {
+	if project.AreaID == nil {
+		return nil
+	}
+	return append([]uint{*project.AreaID}, SharedAreaIDs(db, project.ID)...)
+}
+
+// ScopeProjectsInAreas restricts a projects query to the projects hosted in or shared with the areas
+func ScopeProjectsInAreas(db *gorm.DB, areaIDs []uint) *gorm.DB {
+	return db.Where("(projects.area_id IN ? OR projects.id IN (SELECT project_id FROM project_areas WHERE area_id IN ?))",
+		NonEmptyIDs(areaIDs), NonEmptyIDs(areaIDs))
+}
+
+// BelongsToAnyArea checks if the user is a member of at least one of the areas
+func (u *User) BelongsToAnyArea(db *gorm.DB, areaIDs []uint) bool {
+	for _, areaID := range areaIDs {
+		if u.BelongsToArea(db, areaID) {
+			return true
+		}
+	}
+	return false
+}
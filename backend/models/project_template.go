@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ProjectTemplate is a reusable project structure: standard tasks with their estimates, dependencies
+// and the role of the person who usually takes them
+type ProjectTemplate struct {
+	ID           uint            `gorm:"primarykey" json:"id"`
+	Name         string          `gorm:"not null;uniqueIndex:idx_template_area_name,where:deleted_at IS NULL" json:"name"`
+	Description  string          `gorm:"type:text" json:"description"`
+	AreaID       *uint           `gorm:"index;uniqueIndex:idx_template_area_name,where:deleted_at IS NULL" json:"area_id"` // Nil for templates available to every area
+	Priority     ProjectPriority `gorm:"type:varchar(20);not null;default:'medium'" json:"priority"`
+	DurationDays int             `gorm:"default:0" json:"duration_days"` // Project due date relative to its start; 0 leaves it empty
+	Billable     *bool           `json:"billable"`
+	CreatedBy    uint            `gorm:"not null" json:"created_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Tasks []ProjectTemplateTask `gorm:"foreignKey:TemplateID" json:"tasks,omitempty"`
+}
+
+// ProjectTemplateTask is a standard task of a template. Dates are offsets in days from the project start.
+type ProjectTemplateTask struct {
+	ID             uint                        `gorm:"primarykey" json:"id"`
+	TemplateID     uint                        `gorm:"not null;index" json:"template_id"`
+	Key            string                      `gorm:"not null" json:"key"` // Identifies the task within the template for dependencies
+	Name           string                      `gorm:"not null" json:"name"`
+	Description    string                      `gorm:"type:text" json:"description"`
+	Priority       TaskPriority                `gorm:"type:varchar(20);not null;default:'medium'" json:"priority"`
+	EstimatedHours float64                     `gorm:"default:0" json:"estimated_hours"`
+	Order          int                         `gorm:"default:0" json:"order"`
+	DueOffsetDays  *int                        `json:"due_offset_days"`                       // Due date in days from the project start
+	AssigneeRole   string                      `json:"assignee_role"`                         // Role of the default assignee, e.g. "designer"
+	DependsOn      datatypes.JSONSlice[string] `json:"depends_on" swaggertype:"array,string"` // Keys of the tasks it depends on
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RateScope says who an hourly rate applies to
+type RateScope string
+
+const (
+	RateScopeRole    RateScope = "role"    // Everyone with a built-in role
+	RateScopeUser    RateScope = "user"    // One user, overrides the role rate
+	RateScopeProject RateScope = "project" // Work on a project (optionally by one user), overrides user and role rates
+)
+
+// HourlyRate is an effective-dated cost and/or bill rate. A nil rate leaves that rate
+// to the next less specific scope (project+user > project > user > role).
+type HourlyRate struct {
+	ID            uint           `gorm:"primarykey" json:"id"`
+	Scope         RateScope      `gorm:"type:varchar(20);not null;index" json:"scope"`
+	Role          *Role          `gorm:"type:varchar(20);index" json:"role,omitempty"` // Role scope
+	UserID        *uint          `gorm:"index" json:"user_id,omitempty"`               // User scope, or project scope for one user
+	ProjectID     *uint          `gorm:"index" json:"project_id,omitempty"`            // Project scope
+	CostRate      *float64       `json:"cost_rate"`                                    // What an hour costs the company
+	BillRate      *float64       `json:"bill_rate"`                                    // What an hour is billed to the client
+	EffectiveFrom time.Time      `gorm:"type:date;not null;index" json:"effective_from"`
+	EffectiveTo   *time.Time     `gorm:"type:date" json:"effective_to,omitempty"` // Last day the rate applies; nil while current
+	CreatedBy     uint           `gorm:"not null" json:"created_by"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	User    *User    `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
+	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty" swaggerignore:"true"`
+}
+
+// ResolvedRates are the rates that apply to an hour of work
+type ResolvedRates struct {
+	CostRate float64
+	BillRate float64
+}
+
+// ResolveRates finds the cost and bill rates in effect on the date for a user working on an optional project
+func ResolveRates(db *gorm.DB, user *User, projectID *uint, date time.Time) (ResolvedRates, error) {
+	// Most specific first
+	scopes := make([]func(*gorm.DB) *gorm.DB, 0, 4)
+	if projectID != nil {
+		scopes = append(scopes,
+			func(q *gorm.DB) *gorm.DB {
+				return q.Where("scope = ? AND project_id = ? AND user_id = ?", RateScopeProject, *projectID, user.ID)
+			},
+			func(q *gorm.DB) *gorm.DB {
+				return q.Where("scope = ? AND project_id = ? AND user_id IS NULL", RateScopeProject, *projectID)
+			})
+	}
+	scopes = append(scopes,
+		func(q *gorm.DB) *gorm.DB { return q.Where("scope = ? AND user_id = ?", RateScopeUser, user.ID) },
+		func(q *gorm.DB) *gorm.DB { return q.Where("scope = ? AND role = ?", RateScopeRole, user.Role) })
+
+	var merger rateMerger
+	for _, scope := range scopes {
+		var rate HourlyRate
+		err := scope(db.Model(&HourlyRate{})).
+			Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", date, date).
+			Order("effective_from DESC").
+			First(&rate).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return merger.resolved, err
+		}
+		if merger.add(&rate) {
+			break
+		}
+	}
+	return merger.resolved, nil
+}
+
+// rateMerger takes each rate from the most specific scope that sets it, fed from most to least specific
+type rateMerger struct {
+	resolved             ResolvedRates
+	costFound, billFound bool
+}
+
+// add merges the rate in effect for the next scope and reports whether both rates are now known
+func (m *rateMerger) add(rate *HourlyRate) bool {
+	if !m.costFound && rate.CostRate != nil {
+		m.resolved.CostRate, m.costFound = *rate.CostRate, true
+	}
+	if !m.billFound && rate.BillRate != nil {
+		m.resolved.BillRate, m.billFound = *rate.BillRate, true
+	}
+	return m.costFound && m.billFound
+}
+
+// ApplyRates snapshots the rates on the activity and computes its cost and revenue
+func (a *Activity) ApplyRates(rates ResolvedRates) {
+	a.CostRate = rates.CostRate
+	a.BillRate = rates.BillRate
+	a.RecalculateAmounts()
+}
+
+// RecalculateAmounts computes cost and revenue from the snapshotted rates; only billable hours produce revenue
+func (a *Activity) RecalculateAmounts() {
+	a.CostAmount = a.ExecutionTime * a.CostRate
+	a.RevenueAmount = 0
+	if a.Billable {
+		a.RevenueAmount = a.ExecutionTime * a.BillRate
+	}
+}
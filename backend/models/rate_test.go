@@ -0,0 +1,101 @@
+package models
+
+import "testing"
+
+func TestRateMerger(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		rates    []HourlyRate // Most specific scope first
+		want     ResolvedRates
+		wantDone []bool
+	}{
+		{
+			name: "no rates",
+			want: ResolvedRates{},
+		},
+		{
+			name:     "single scope sets both",
+			rates:    []HourlyRate{{CostRate: f(40), BillRate: f(90)}},
+			want:     ResolvedRates{CostRate: 40, BillRate: 90},
+			wantDone: []bool{true},
+		},
+		{
+			name:     "most specific scope wins",
+			rates:    []HourlyRate{{CostRate: f(50), BillRate: f(120)}, {CostRate: f(40), BillRate: f(90)}},
+			want:     ResolvedRates{CostRate: 50, BillRate: 120},
+			wantDone: []bool{true, true},
+		},
+		{
+			name:     "nil rate falls through to less specific scope",
+			rates:    []HourlyRate{{BillRate: f(150)}, {CostRate: f(40), BillRate: f(90)}},
+			want:     ResolvedRates{CostRate: 40, BillRate: 150},
+			wantDone: []bool{false, true},
+		},
+		{
+			name:     "each rate from a different scope",
+			rates:    []HourlyRate{{CostRate: f(60)}, {}, {BillRate: f(100)}},
+			want:     ResolvedRates{CostRate: 60, BillRate: 100},
+			wantDone: []bool{false, false, true},
+		},
+		{
+			name:     "zero is a rate",
+			rates:    []HourlyRate{{BillRate: f(0)}, {CostRate: f(40), BillRate: f(90)}},
+			want:     ResolvedRates{CostRate: 40, BillRate: 0},
+			wantDone: []bool{false, true},
+		},
+		{
+			name:     "missing rate stays zero",
+			rates:    []HourlyRate{{CostRate: f(40)}},
+			want:     ResolvedRates{CostRate: 40},
+			wantDone: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var merger rateMerger
+			for i := range tt.rates {
+				if done := merger.add(&tt.rates[i]); done != tt.wantDone[i] {
+					t.Errorf("add(rates[%d]) = %v, want %v", i, done, tt.wantDone[i])
+				}
+			}
+			if merger.resolved != tt.want {
+				t.Errorf("resolved = %+v, want %+v", merger.resolved, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivityApplyRates(t *testing.T) {
+	tests := []struct {
+		name        string
+		hours       float64
+		billable    bool
+		rates       ResolvedRates
+		wantCost    float64
+		wantRevenue float64
+	}{
+		{"billable", 2.5, true, ResolvedRates{CostRate: 40, BillRate: 100}, 100, 250},
+		{"not billable", 2.5, false, ResolvedRates{CostRate: 40, BillRate: 100}, 100, 0},
+		{"no rates", 3, true, ResolvedRates{}, 0, 0},
+		{"no hours", 0, true, ResolvedRates{CostRate: 40, BillRate: 100}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := Activity{ExecutionTime: tt.hours, Billable: tt.billable, RevenueAmount: 999}
+			activity.ApplyRates(tt.rates)
+			if activity.CostRate != tt.rates.CostRate || activity.BillRate != tt.rates.BillRate {
+				t.Errorf("rates = %v/%v, want %v/%v", activity.CostRate, activity.BillRate, tt.rates.CostRate, tt.rates.BillRate)
+			}
+			if !almostEqual(activity.CostAmount, tt.wantCost) {
+				t.Errorf("CostAmount = %v, want %v", activity.CostAmount, tt.wantCost)
+			}
+			if !almostEqual(activity.RevenueAmount, tt.wantRevenue) {
+				t.Errorf("RevenueAmount = %v, want %v", activity.RevenueAmount, tt.wantRevenue)
+			}
+		})
+	}
+}
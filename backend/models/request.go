@@ -10,16 +10,46 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	FullName string `json:"full_name" binding:"required"`
-	AreaID   *uint  `json:"area_id"`
+	Email           string `json:"email" binding:"required,email"`
+	Password        string `json:"password" binding:"required,min=6"`
+	FullName        string `json:"full_name" binding:"required"`
+	InvitationToken string `json:"invitation_token"` // Required unless open registration is enabled; sets the role and area
 }
 
 type MicrosoftLoginRequest struct {
 	AccessToken string `json:"access_token" binding:"required"`
 }
 
+// SSOLoginRequest carries what the client obtained from an identity provider.
+// OIDC providers need id_token, or code (plus redirect_uri and code_verifier for PKCE) to exchange.
+type SSOLoginRequest struct {
+	IDToken      string `json:"id_token"`
+	AccessToken  string `json:"access_token"`
+	Code         string `json:"code"`
+	RedirectURI  string `json:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"` // Checked against the ID token when sent
+}
+
+type StartImpersonationRequest struct {
+	UserID          uint   `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"required,min=5"`            // Motivo (p. ej. número de ticket de soporte)
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1"` // Por defecto y como máximo IMPERSONATION_MAX_MINUTES
+	AllowWrites     bool   `json:"allow_writes"`                               // Por defecto la sesión es de solo lectura
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type SetupRequest struct {
+	SetupToken string `json:"setup_token" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=8"`
+	FullName   string `json:"full_name" binding:"required"`
+}
+
 type CreateSuperAdminRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
@@ -51,6 +81,30 @@ type UpdateUserRequest struct {
 	IsActive     *bool       `json:"is_active"`
 }
 
+type ApproveUserRequest struct {
+	Role   Role  `json:"role" binding:"omitempty,oneof=user admin"`
+	AreaID *uint `json:"area_id" binding:"required"`
+}
+
+type RejectUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ============================================
+// Invitation Requests
+// ============================================
+
+type CreateInvitationRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           Role   `json:"role" binding:"omitempty,oneof=user admin"`
+	AreaID         *uint  `json:"area_id" binding:"required"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,gt=0"` // Defaults to INVITATION_EXPIRATION_HOURS
+}
+
+type ResendInvitationRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"omitempty,gt=0"`
+}
+
 // ============================================
 // Area Requests
 // ============================================
@@ -58,14 +112,130 @@ type UpdateUserRequest struct {
 type CreateAreaRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"`
 }
 
 type UpdateAreaRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	ParentID    *uint  `json:"parent_id"` // 0 moves the area to the top level
 	IsActive    *bool  `json:"is_active"`
 }
 
+type OffboardUserRequest struct {
+	ReassignToID *uint `json:"reassign_to_id"` // Usuario que recibe las tareas y proyectos abiertos; vacío los devuelve al backlog
+}
+
+type UpdateUserSettingsRequest struct {
+	Timezone            *string       `json:"timezone"`                                   // Zona horaria IANA, ej. America/Bogota
+	Locale              *string       `json:"locale"`                                     // Idioma, ej. es o es-CO
+	WeekStart           *int          `json:"week_start" binding:"omitempty,min=0,max=6"` // Primer día de la semana (0 = domingo, 1 = lunes)
+	DefaultActivityType *ActivityType `json:"default_activity_type"`                      // Tipo usado cuando una actividad no indica tipo; "" lo borra
+}
+
+type AddUserAreaRequest struct {
+	AreaID    uint `json:"area_id" binding:"required"`
+	Role      Role `json:"role" binding:"omitempty,oneof=user admin"` // Rol dentro del área (por defecto 'user')
+	IsPrimary bool `json:"is_primary"`
+}
+
+type UpdateUserAreaRequest struct {
+	Role      Role  `json:"role" binding:"omitempty,oneof=user admin"`
+	IsPrimary *bool `json:"is_primary"`
+}
+
+// ============================================
+// Role Requests
+// ============================================
+
+type CreateRoleRequest struct {
+	Name        string       `json:"name" binding:"required,min=2,max=100"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" binding:"required,min=1"`
+}
+
+type UpdateRoleRequest struct {
+	Name        string       `json:"name" binding:"omitempty,min=2,max=100"`
+	Description *string      `json:"description"`
+	Permissions []Permission `json:"permissions" binding:"omitempty,min=1"`
+}
+
+type AssignRoleRequest struct {
+	RoleID uint  `json:"role_id" binding:"required"`
+	AreaID *uint `json:"area_id"` // nil grants the role in every area
+}
+
+// ============================================
+// Identity Mapping Requests
+// ============================================
+
+type CreateIdentityMappingRequest struct {
+	Name       string           `json:"name" binding:"required,max=100"`
+	Provider   string           `json:"provider"` // Vacío aplica a todos los proveedores
+	MatchType  MappingMatchType `json:"match_type" binding:"required,oneof=group domain"`
+	MatchValue string           `json:"match_value" binding:"required"` // Grupo (nombre o ID) o dominio del email
+	AreaID     uint             `json:"area_id" binding:"required"`
+	Role       Role             `json:"role" binding:"omitempty,oneof=user admin"` // Rol dentro del área (por defecto 'user'); 'admin' solo en reglas de grupo
+	Priority   int              `json:"priority"`
+	IsActive   *bool            `json:"is_active"`
+}
+
+type UpdateIdentityMappingRequest struct {
+	Name       string           `json:"name" binding:"omitempty,max=100"`
+	Provider   *string          `json:"provider"`
+	MatchType  MappingMatchType `json:"match_type" binding:"omitempty,oneof=group domain"`
+	MatchValue string           `json:"match_value"`
+	AreaID     *uint            `json:"area_id"`
+	Role       Role             `json:"role" binding:"omitempty,oneof=user admin"`
+	Priority   *int             `json:"priority"`
+	IsActive   *bool            `json:"is_active"`
+}
+
+// ============================================
+// Provisioning Requests
+// ============================================
+
+type CreateProvisioningTokenRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	AuthProvider string `json:"auth_provider"` // Proveedor con el que inician sesión los usuarios aprovisionados (por defecto microsoft)
+}
+
+// ============================================
+// Skill Requests
+// ============================================
+
+type CreateSkillRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	AreaID      *uint  `json:"area_id"` // Vacío para una habilidad disponible en todas las áreas
+}
+
+type UpdateSkillRequest struct {
+	Name        string  `json:"name"`
+	Category    *string `json:"category"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type SkillLevelInput struct {
+	SkillID uint `json:"skill_id" binding:"required"`
+	Level   int  `json:"level" binding:"required,min=1,max=5"` // 1 (básico) a 5 (experto)
+}
+
+type SetUserSkillsRequest struct {
+	Skills []SkillLevelInput `json:"skills" binding:"dive"` // Reemplaza todas las habilidades del usuario
+}
+
+type RequiredSkillInput struct {
+	SkillID  uint `json:"skill_id" binding:"required"`
+	MinLevel int  `json:"min_level" binding:"omitempty,min=1,max=5"` // Nivel mínimo, por defecto 1
+}
+
+type SetRequiredSkillsRequest struct {
+	Skills []RequiredSkillInput `json:"skills" binding:"dive"` // Reemplaza las habilidades requeridas
+}
+
 // ============================================
 // Project Requests
 // ============================================
@@ -74,12 +244,19 @@ type CreateProjectRequest struct {
 	Name            string          `json:"name" binding:"required"`
 	Description     string          `json:"description"`
 	ProjectType     ProjectType     `json:"project_type" binding:"required,oneof=personal area"` // personal o area
+	AreaID          *uint           `json:"area_id"`                                             // Área del proyecto (por defecto el área principal del creador)
 	AssignedUserID  *uint           `json:"assigned_user_id"`                                    // Deprecated: single user (for backward compatibility)
 	AssignedUserIDs []uint          `json:"assigned_user_ids"`                                   // Multiple users to assign
+	SharedAreaIDs   []uint          `json:"shared_area_ids"`                                     // Áreas participantes además del área anfitriona (solo proyectos de área)
 	Priority        ProjectPriority `json:"priority" binding:"omitempty,oneof=low medium high critical"`
 	EstimatedHours  float64         `json:"estimated_hours" binding:"omitempty,gte=0"` // Horas estimadas (opcional)
 	StartDate       *string         `json:"start_date"`                                // Fecha de inicio en formato YYYY-MM-DD (opcional)
 	DueDate         *string         `json:"due_date"`                                  // Fecha de vencimiento en formato YYYY-MM-DD (opcional)
+	Billable        *bool           `json:"billable"`                                  // Facturable por defecto para sus actividades; vacío sigue el tipo de actividad
+	ClientID        *uint           `json:"client_id"`                                 // Cliente al que se entrega y factura (opcional)
+	BudgetHours     *float64        `json:"budget_hours" binding:"omitempty,gte=0"`    // Presupuesto en horas; vacío usa las horas estimadas
+	BudgetAmount    *float64        `json:"budget_amount" binding:"omitempty,gte=0"`   // Presupuesto en dinero (coste de las actividades)
+	Thresholds      []int           `json:"budget_thresholds" binding:"omitempty,max=10,dive,min=1,max=1000"`
 }
 
 type UpdateProjectRequest struct {
@@ -92,12 +269,30 @@ type UpdateProjectRequest struct {
 	StartDate       *string          `json:"start_date"`
 	DueDate         *string          `json:"due_date"`
 	IsActive        *bool            `json:"is_active"`
+	Billable        *bool            `json:"billable"`
+	ClientID        *uint            `json:"client_id"`                               // 0 desvincula el cliente
+	BudgetHours     *float64         `json:"budget_hours" binding:"omitempty,gte=0"`  // 0 vuelve a usar las horas estimadas
+	BudgetAmount    *float64         `json:"budget_amount" binding:"omitempty,gte=0"` // 0 elimina el presupuesto en dinero
+	Thresholds      []int            `json:"budget_thresholds" binding:"omitempty,max=10,dive,min=1,max=1000"`
 }
 
 type UpdateProjectStatusRequest struct {
 	Status ProjectStatus `json:"status" binding:"required,oneof=unassigned assigned in_progress paused completed"`
 }
 
+type UpdateProjectMemberRequest struct {
+	Role MemberRole `json:"role" binding:"required,oneof=owner manager contributor viewer"` // Rol del miembro en el proyecto
+}
+
+type ShareProjectRequest struct {
+	AreaID uint `json:"area_id" binding:"required"` // Área participante
+}
+
+type AddProjectMembersRequest struct {
+	UserIDs []uint     `json:"user_ids" binding:"required,min=1"`                               // Usuarios a asignar
+	Role    MemberRole `json:"role" binding:"omitempty,oneof=owner manager contributor viewer"` // Por defecto contributor; los miembros actuales conservan su rol
+}
+
 // ============================================
 // Task Requests
 // ============================================
@@ -111,6 +306,7 @@ type CreateTaskRequest struct {
 	EstimatedHours float64      `json:"estimated_hours" binding:"required,gt=0"`
 	DueDate        *string      `json:"due_date"` // YYYY-MM-DD format
 	Order          int          `json:"order"`
+	MilestoneID    *uint        `json:"milestone_id"`
 }
 
 type UpdateTaskRequest struct {
@@ -122,6 +318,7 @@ type UpdateTaskRequest struct {
 	DueDate        *string      `json:"due_date"` // YYYY-MM-DD format
 	Order          *int         `json:"order"`
 	IsActive       *bool        `json:"is_active"`
+	MilestoneID    *uint        `json:"milestone_id"` // 0 la desvincula de su hito
 }
 
 type UpdateTaskStatusRequest struct {
@@ -135,6 +332,85 @@ type BulkUpdateTaskOrderRequest struct {
 	} `json:"tasks" binding:"required,dive"`
 }
 
+type SetTaskDependenciesRequest struct {
+	DependsOnTaskIDs []uint `json:"depends_on_task_ids"` // Reemplaza las dependencias; tareas del mismo proyecto
+}
+
+type AddTaskAssigneesRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1"` // Usuarios a asignar; se mantienen los asignados actuales
+}
+
+// ============================================
+// Milestone Requests
+// ============================================
+
+type CreateMilestoneRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	DueDate     string `json:"due_date" binding:"required"` // YYYY-MM-DD
+	TaskIDs     []uint `json:"task_ids"`                    // Tareas del proyecto vinculadas al hito
+}
+
+type UpdateMilestoneRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	DueDate     string  `json:"due_date"` // YYYY-MM-DD
+	TaskIDs     []uint  `json:"task_ids"` // Si se envía, reemplaza las tareas vinculadas
+}
+
+// ============================================
+// Project Template Requests
+// ============================================
+
+type TemplateTaskInput struct {
+	Key            string       `json:"key"` // Identificador dentro de la plantilla; por defecto su posición (1, 2, ...)
+	Name           string       `json:"name" binding:"required"`
+	Description    string       `json:"description"`
+	Priority       TaskPriority `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	EstimatedHours float64      `json:"estimated_hours" binding:"gte=0"`
+	DueOffsetDays  *int         `json:"due_offset_days" binding:"omitempty,gte=0"` // Días desde el inicio del proyecto
+	AssigneeRole   string       `json:"assignee_role"`                             // Rol de quien suele hacerla, ej. "designer"
+	DependsOn      []string     `json:"depends_on"`                                // Claves de las tareas de las que depende
+}
+
+type CreateProjectTemplateRequest struct {
+	Name         string              `json:"name" binding:"required"`
+	Description  string              `json:"description"`
+	AreaID       *uint               `json:"area_id"` // Vacío para una plantilla disponible en todas las áreas
+	Priority     ProjectPriority     `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	DurationDays int                 `json:"duration_days" binding:"gte=0"` // Fecha límite del proyecto en días desde su inicio
+	Billable     *bool               `json:"billable"`
+	Tasks        []TemplateTaskInput `json:"tasks" binding:"dive"`
+}
+
+type UpdateProjectTemplateRequest struct {
+	Name         string              `json:"name"`
+	Description  *string             `json:"description"`
+	Priority     ProjectPriority     `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	DurationDays *int                `json:"duration_days" binding:"omitempty,gte=0"`
+	Billable     *bool               `json:"billable"`
+	Tasks        []TemplateTaskInput `json:"tasks" binding:"omitempty,dive"` // Si se envía, reemplaza las tareas
+}
+
+type SaveProjectAsTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	AreaID      *uint  `json:"area_id"` // Por defecto el área del proyecto
+}
+
+type InstantiateTemplateRequest struct {
+	Name      string            `json:"name" binding:"required"`
+	AreaID    *uint             `json:"area_id"`                       // Por defecto el área de la plantilla o la del usuario
+	StartDate string            `json:"start_date" binding:"required"` // YYYY-MM-DD; las fechas de las tareas se calculan desde aquí
+	Assignees map[string][]uint `json:"assignees"`                     // Rol de la plantilla -> usuarios asignados a sus tareas
+}
+
+type CloneProjectRequest struct {
+	Name               string `json:"name" binding:"required"`
+	StartDate          string `json:"start_date" binding:"required"` // YYYY-MM-DD; las fechas se desplazan desde el inicio del original
+	IncludeAssignments bool   `json:"include_assignments"`           // Copia los miembros y asignados de las tareas
+}
+
 // ============================================
 // Activity Requests
 // ============================================
@@ -142,20 +418,23 @@ type BulkUpdateTaskOrderRequest struct {
 type CreateActivityRequest struct {
 	ProjectID       *uint        `json:"project_id"`
 	TaskID          *uint        `json:"task_id"`
+	AreaID          *uint        `json:"area_id"` // Área a la que se imputa (por defecto la del proyecto o el área principal)
 	ProjectName     string       `json:"project_name"`
 	TaskName        string       `json:"task_name"`
 	ActivityName    string       `json:"activity_name" binding:"required"`
-	ActivityType    ActivityType `json:"activity_type" binding:"required"`
+	ActivityType    ActivityType `json:"activity_type"` // Código de un tipo disponible en el área; por defecto el de los ajustes del usuario
 	ExecutionTime   float64      `json:"execution_time" binding:"required,gt=0"`
 	Date            string       `json:"date" binding:"required"` // YYYY-MM-DD format
 	OtherArea       string       `json:"other_area"`
 	Observations    string       `json:"observations"`
 	CalendarEventID *string      `json:"calendar_event_id"` // ID del evento de calendario
+	Billable        *bool        `json:"billable"`          // Por defecto el del proyecto o el del tipo de actividad
 }
 
 type UpdateActivityRequest struct {
 	ProjectID     *uint        `json:"project_id"`
 	TaskID        *uint        `json:"task_id"`
+	AreaID        *uint        `json:"area_id"` // Área a la que se imputa (debe ser una de las áreas del usuario)
 	ProjectName   string       `json:"project_name"`
 	TaskName      string       `json:"task_name"`
 	ActivityName  string       `json:"activity_name"`
@@ -164,6 +443,78 @@ type UpdateActivityRequest struct {
 	Date          string       `json:"date"` // YYYY-MM-DD format
 	OtherArea     string       `json:"other_area"`
 	Observations  string       `json:"observations"`
+	Billable      *bool        `json:"billable"`
+}
+
+type CreateActivityTypeRequest struct {
+	Code            ActivityType      `json:"code" binding:"required"`  // Identificador: minúsculas, números y guiones bajos
+	AreaID          *uint             `json:"area_id"`                  // Vacío para un tipo global
+	Names           map[string]string `json:"names" binding:"required"` // Nombre por idioma, ej. {"es": "Soporte", "en": "Support"}
+	BillableDefault bool              `json:"billable_default"`
+	RequiresProject bool              `json:"requires_project"`
+	SortOrder       int               `json:"sort_order"`
+}
+
+type UpdateActivityTypeRequest struct {
+	Names           map[string]string `json:"names"` // Reemplaza los nombres por idioma
+	IsActive        *bool             `json:"is_active"`
+	BillableDefault *bool             `json:"billable_default"`
+	RequiresProject *bool             `json:"requires_project"`
+	SortOrder       *int              `json:"sort_order"`
+}
+
+// ============================================
+// Rate Requests
+// ============================================
+
+type CreateRateRequest struct {
+	Scope         RateScope `json:"scope" binding:"required,oneof=role user project"`
+	Role          *Role     `json:"role"`       // Obligatorio para scope role
+	UserID        *uint     `json:"user_id"`    // Obligatorio para scope user; opcional en project para un solo usuario
+	ProjectID     *uint     `json:"project_id"` // Obligatorio para scope project
+	CostRate      *float64  `json:"cost_rate" binding:"omitempty,gte=0"`
+	BillRate      *float64  `json:"bill_rate" binding:"omitempty,gte=0"`
+	EffectiveFrom string    `json:"effective_from" binding:"required"` // YYYY-MM-DD
+	EffectiveTo   *string   `json:"effective_to"`                      // YYYY-MM-DD, último día (opcional)
+}
+
+type UpdateRateRequest struct {
+	CostRate      *float64 `json:"cost_rate" binding:"omitempty,gte=0"`
+	BillRate      *float64 `json:"bill_rate" binding:"omitempty,gte=0"`
+	EffectiveFrom *string  `json:"effective_from"` // YYYY-MM-DD
+	EffectiveTo   *string  `json:"effective_to"`   // YYYY-MM-DD; "" deja la tarifa vigente sin fecha de fin
+}
+
+// ============================================
+// Client and Invoice Requests
+// ============================================
+
+type CreateClientRequest struct {
+	Name        string `json:"name" binding:"required,max=200"`
+	TaxID       string `json:"tax_id"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Phone       string `json:"phone"`
+	Address     string `json:"address"`
+	ContactName string `json:"contact_name"`
+	AreaID      *uint  `json:"area_id"` // Vacío para un cliente compartido por todas las áreas
+}
+
+type UpdateClientRequest struct {
+	Name        string  `json:"name" binding:"omitempty,max=200"`
+	TaxID       *string `json:"tax_id"`
+	Email       *string `json:"email" binding:"omitempty,email"`
+	Phone       *string `json:"phone"`
+	Address     *string `json:"address"`
+	ContactName *string `json:"contact_name"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type CreateInvoiceRequest struct {
+	ClientID uint   `json:"client_id" binding:"required"`
+	AreaID   *uint  `json:"area_id"`                      // Solo actividades de esta área; vacío para todas (requiere invoices.manage global)
+	DateFrom string `json:"date_from" binding:"required"` // YYYY-MM-DD
+	DateTo   string `json:"date_to" binding:"required"`   // YYYY-MM-DD
+	Notes    string `json:"notes"`
 }
 
 // ============================================
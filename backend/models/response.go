@@ -18,9 +18,135 @@ type UserResponse struct {
 	Role         Role        `json:"role"`
 	AreaID       *uint       `json:"area_id"`
 	Area         *Area       `json:"area,omitempty"`
+	Areas        []UserArea  `json:"areas,omitempty"` // Every area the user belongs to, with the role held in each
 	WorkSchedule interface{} `json:"work_schedule,omitempty"`
 	LunchBreak   interface{} `json:"lunch_break,omitempty"`
 	IsActive     bool        `json:"is_active"`
+	// Timezone, locale and preferences (only on /auth/me)
+	Settings *UserSettings `json:"settings,omitempty"`
+	// Password change required before using the rest of the API
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// Approval status for accounts created through SSO
+	ApprovalStatus  ApprovalStatus `json:"approval_status,omitempty"`
+	RejectionReason string         `json:"rejection_reason,omitempty"`
+	// Set when a SuperAdmin is acting as this user; the frontend shows a banner
+	IsImpersonated bool               `json:"is_impersonated,omitempty"`
+	Impersonation  *ImpersonationInfo `json:"impersonation,omitempty"`
+}
+
+type ImpersonationInfo struct {
+	SessionID        uint      `json:"session_id"`
+	ImpersonatorID   uint      `json:"impersonator_id"`
+	ImpersonatorName string    `json:"impersonator_name"`
+	ReadOnly         bool      `json:"read_only"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type ImpersonationResponse struct {
+	Token   string               `json:"token"`
+	Session ImpersonationSession `json:"session"`
+	User    UserResponse         `json:"user"`
+}
+
+type InvitationResponse struct {
+	Invitation    Invitation `json:"invitation"`
+	InvitationURL string     `json:"invitation_url"` // Contains the single-use token, only returned on create/resend
+	EmailSent     bool       `json:"email_sent"`     // False when the email could not be sent; share the link another way
+}
+
+// Offboarding actions applied to open work
+const (
+	OffboardingReassigned        = "reassigned"          // Given to the recipient
+	OffboardingReturnedToBacklog = "returned_to_backlog" // Nobody else was assigned, back to backlog/unassigned
+	OffboardingUnassigned        = "unassigned"          // Other assignees keep working on it
+)
+
+// OffboardingItem is an open task or project of a user being offboarded
+type OffboardingItem struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	ProjectID *uint  `json:"project_id,omitempty"` // Tasks only
+	Action    string `json:"action,omitempty"`     // 'reassigned', 'returned_to_backlog' or 'unassigned'
+	Reason    string `json:"reason,omitempty"`     // Why the item could not be reassigned
+}
+
+type OffboardingReport struct {
+	UserID                 uint              `json:"user_id"`
+	ReassignedTo           *uint             `json:"reassigned_to,omitempty"`
+	Tasks                  []OffboardingItem `json:"tasks"`
+	Projects               []OffboardingItem `json:"projects"`
+	TasksReassigned        int               `json:"tasks_reassigned"`
+	TasksReturnedToBacklog int               `json:"tasks_returned_to_backlog"`
+	TasksUnassigned        int               `json:"tasks_unassigned"`
+	ProjectsReassigned     int               `json:"projects_reassigned"`
+	ProjectsUnassigned     int               `json:"projects_unassigned"`
+	Deactivated            bool              `json:"deactivated"`
+	TokensRevoked          bool              `json:"tokens_revoked"`
+	OffboardedAt           *time.Time        `json:"offboarded_at,omitempty"` // Empty in previews
+}
+
+// Actions of a CSV import row
+const (
+	UserImportCreate = "create"
+	UserImportUpdate = "update"
+)
+
+// UserImportRow is the validation result of one CSV line
+type UserImportRow struct {
+	Line   int      `json:"line"` // Line number in the file, the header is line 1
+	Email  string   `json:"email"`
+	Action string   `json:"action,omitempty"` // 'create' or 'update'
+	UserID *uint    `json:"user_id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type UserImportReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Created int             `json:"created"`
+	Updated int             `json:"updated"`
+	Failed  int             `json:"failed"`
+	Rows    []UserImportRow `json:"rows"`
+}
+
+type ProvisioningTokenResponse struct {
+	ProvisioningToken ProvisioningToken `json:"provisioning_token"`
+	Token             string            `json:"token"` // Only returned on create, configure it as the SCIM bearer token
+	SCIMBaseURL       string            `json:"scim_base_url"`
+}
+
+// ============================================
+// Skill Responses
+// ============================================
+
+// SkillGap compares a required skill with the candidate's level (0 when they don't have it)
+type SkillGap struct {
+	SkillID  uint   `json:"skill_id"`
+	Name     string `json:"name"`
+	MinLevel int    `json:"min_level"`
+	Level    int    `json:"level"`
+}
+
+// CandidateSuggestion ranks an area member for a task or project
+type CandidateSuggestion struct {
+	UserID          uint       `json:"user_id"`
+	FullName        string     `json:"full_name"`
+	Email           string     `json:"email"`
+	Score           float64    `json:"score"`       // 0-100, 70% skill match and 30% free capacity
+	SkillMatch      float64    `json:"skill_match"` // 0-100
+	MatchedSkills   []SkillGap `json:"matched_skills"`
+	MissingSkills   []SkillGap `json:"missing_skills"` // Required skills below the minimum level
+	ScheduleHours   float64    `json:"schedule_hours"` // Working hours until the horizon
+	OpenHours       float64    `json:"open_hours"`     // Remaining estimated hours of open tasks
+	FreeHours       float64    `json:"free_hours"`     // Schedule hours minus open hours
+	AlreadyAssigned bool       `json:"already_assigned"`
+}
+
+type CandidatesResponse struct {
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"` // Due date, or the requested number of weeks ahead
+	Candidates []CandidateSuggestion `json:"candidates"`
 }
 
 // ============================================
@@ -36,10 +162,23 @@ type AreaSummary struct {
 	TotalActivities   int64            `json:"total_activities"`
 	TotalHours        float64          `json:"total_hours"`
 	AverageCompletion float64          `json:"average_completion"`
+	ParentID          *uint            `json:"parent_id"`
+	Depth             int              `json:"depth"`            // 0 for top-level areas
+	Rollup            *AreaTotals      `json:"rollup,omitempty"` // Totals including every descendant area
 	UserStats         []UserSummary    `json:"user_stats,omitempty"`
 	ProjectStats      []ProjectSummary `json:"project_stats,omitempty"`
 }
 
+type AreaTotals struct {
+	AreaIDs           []uint  `json:"area_ids"` // The area and all its descendants
+	TotalUsers        int64   `json:"total_users"`
+	TotalProjects     int64   `json:"total_projects"`
+	ActiveProjects    int64   `json:"active_projects"`
+	TotalActivities   int64   `json:"total_activities"`
+	TotalHours        float64 `json:"total_hours"`
+	AverageCompletion float64 `json:"average_completion"`
+}
+
 type UserSummary struct {
 	UserID            uint    `json:"user_id"`
 	UserName          string  `json:"user_name"`
@@ -60,6 +199,18 @@ type ProjectSummary struct {
 	RemainingHours    float64  `json:"remaining_hours"`
 	CompletionPercent float64  `json:"completion_percent"`
 	IsActive          bool     `json:"is_active"`
+
+	// Host and shared areas, and the hours each area contributed
+	AreaID        *uint       `json:"area_id"`
+	SharedAreaIDs []uint      `json:"shared_area_ids,omitempty"`
+	HoursByArea   []AreaHours `json:"hours_by_area"`
+}
+
+// AreaHours is the time logged on a project towards one area
+type AreaHours struct {
+	AreaID   *uint   `json:"area_id"` // Nil for activities without area
+	AreaName string  `json:"area_name"`
+	Hours    float64 `json:"hours"`
 }
 
 type ActivityStats struct {
@@ -71,6 +222,100 @@ type ActivityStats struct {
 	ByArea          map[string]float64 `json:"by_area"`
 }
 
+// BudgetReportItem is a project with its budget usage
+type BudgetReportItem struct {
+	ProjectID   uint          `json:"project_id"`
+	ProjectName string        `json:"project_name"`
+	AreaID      *uint         `json:"area_id"`
+	AreaIDs     []uint        `json:"area_ids"` // Host area followed by the areas the project is shared with
+	Currency    string        `json:"currency"` // Of the amount budget
+	MaxPercent  float64       `json:"max_percent"`
+	Budgets     []BudgetUsage `json:"budgets"`
+}
+
+// EarnedValue compares planned, earned and actual work in hours or money
+type EarnedValue struct {
+	Unit                 string   `json:"unit"` // hours or the billing currency
+	BudgetAtCompletion   float64  `json:"budget_at_completion"`
+	PlannedValue         *float64 `json:"planned_value"` // Nil without start and due dates
+	EarnedValue          float64  `json:"earned_value"`
+	ActualCost           float64  `json:"actual_cost"`
+	ScheduleVariance     *float64 `json:"schedule_variance"` // Earned minus planned; negative is behind schedule
+	CostVariance         float64  `json:"cost_variance"`     // Earned minus actual; negative is over budget
+	SPI                  *float64 `json:"spi"`
+	CPI                  *float64 `json:"cpi"`
+	EstimateAtCompletion float64  `json:"estimate_at_completion"`
+	EstimateToComplete   float64  `json:"estimate_to_complete"`
+	VarianceAtCompletion float64  `json:"variance_at_completion"`
+}
+
+// BurnPoint is the hours logged on a project in a week
+type BurnPoint struct {
+	WeekStart       time.Time `json:"week_start"`
+	Hours           float64   `json:"hours"`
+	CumulativeHours float64   `json:"cumulative_hours"`
+}
+
+type ProjectForecast struct {
+	ProjectID           uint         `json:"project_id"`
+	AsOf                time.Time    `json:"as_of"`
+	PercentComplete     float64      `json:"percent_complete"` // Earned progress from task states (50/50 rule)
+	PercentSpent        float64      `json:"percent_spent"`    // Logged hours against the hour budget
+	TasksTotal          int          `json:"tasks_total"`
+	TasksCompleted      int          `json:"tasks_completed"`
+	TasksInProgress     int          `json:"tasks_in_progress"`
+	BurnRate            float64      `json:"burn_rate"`       // Hours per week
+	BurnRateBasis       string       `json:"burn_rate_basis"` // 'recent' (last weeks), 'overall' or 'none'
+	DueDate             *time.Time   `json:"due_date"`
+	ProjectedCompletion *time.Time   `json:"projected_completion"` // Nil when nothing is being logged
+	DaysLate            *int         `json:"days_late"`            // Projected completion minus due date; negative is ahead
+	Hours               EarnedValue  `json:"hours"`
+	Cost                *EarnedValue `json:"cost,omitempty"` // Only with a money budget
+	History             []BurnPoint  `json:"history"`
+}
+
+// AssignmentHistoryEntry is who worked on a project or task and when
+type AssignmentHistoryEntry struct {
+	UserID         uint               `json:"user_id"`
+	FullName       string             `json:"full_name"`
+	Email          string             `json:"email"`
+	Role           MemberRole         `json:"role,omitempty"` // Current or last project role
+	IsActive       bool               `json:"is_active"`
+	Periods        []AssignmentPeriod `json:"periods"`
+	AllocatedHours float64            `json:"allocated_hours"` // Estimates of the assigned tasks, split among their assignees
+	LoggedHours    float64            `json:"logged_hours"`
+}
+
+type AssignmentPeriod struct {
+	From       time.Time  `json:"from"`
+	To         *time.Time `json:"to"` // Nil while still assigned
+	AssignedBy uint       `json:"assigned_by"`
+}
+
+// MilestoneAgenda lists the milestones that need attention across the projects a user can see
+type MilestoneAgenda struct {
+	Overdue  []Milestone `json:"overdue"`
+	Upcoming []Milestone `json:"upcoming"` // Due within the requested days, oldest first
+}
+
+// BillingReport aggregates the cost and revenue snapshotted on activities
+type BillingReport struct {
+	GroupBy  string             `json:"group_by"` // project, area or month
+	Currency string             `json:"currency"`
+	Rows     []BillingReportRow `json:"rows"`
+	Totals   BillingReportRow   `json:"totals"`
+}
+
+type BillingReportRow struct {
+	Key           string  `json:"key"` // Project or area ID, or YYYY-MM
+	Label         string  `json:"label"`
+	Hours         float64 `json:"hours"`
+	BillableHours float64 `json:"billable_hours"`
+	Cost          float64 `json:"cost"`
+	Revenue       float64 `json:"revenue"`
+	Margin        float64 `json:"margin"` // Revenue minus cost
+}
+
 // ============================================
 // Calendar Responses
 // ============================================
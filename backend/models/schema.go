@@ -0,0 +1,58 @@
+package models
+
+// All returns every model stored in the database, in migration order.
+//
+// Boolean and numeric fields carry no `default:` tag other than their zero value: on Create GORM writes
+// the tag's value in place of a false or 0 field, so a record created inactive or with a zero setting would
+// be stored with the default instead. Set the value explicitly when creating the record.
+func All() []interface{} {
+	return []interface{}{
+		&Area{},
+		&User{},
+		&UserArea{},
+		&UserSettings{},
+		&UserIdentity{},
+		&IdentityMappingRule{},
+		&ProvisioningToken{},
+		&ScimGroup{},
+		&Project{},
+		&Task{},
+		&TaskDependency{},
+		&Milestone{},
+		&ProjectTemplate{},
+		&ProjectTemplateTask{},
+		&Activity{},
+		&ActivityTypeDefinition{},
+		&HourlyRate{},
+		&Client{},
+		&Invoice{},
+		&InvoiceLine{},
+		&ProjectBudgetAlert{},
+		&Notification{},
+		&Comment{},
+		&ProjectAssignment{},
+		&ProjectArea{},
+		&TaskAssignment{},
+		&AssignmentEvent{},
+		&Invitation{},
+		&RoleDefinition{},
+		&RolePermission{},
+		&RoleAssignment{},
+		&ImpersonationSession{},
+		&ImpersonationAuditLog{},
+		&SigningKey{},
+		&Skill{},
+		&UserSkill{},
+		&ProjectSkill{},
+		&TaskSkill{},
+	}
+}
+
+// NonEmptyIDs returns the IDs for an IN condition, or the unused ID 0 when there are none:
+// an empty IN list is invalid SQL, and 0 matches nothing as well
+func NonEmptyIDs(ids []uint) []uint {
+	if len(ids) == 0 {
+		return []uint{0}
+	}
+	return ids
+}
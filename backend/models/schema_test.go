@@ -0,0 +1,48 @@
+package models
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// GORM writes a field's default in place of its zero value on Create, so a non-zero default on a
+// boolean or numeric field makes false or 0 impossible to store
+func TestNoNonZeroDefaultsOnZeroableFields(t *testing.T) {
+	cache := &sync.Map{}
+	for _, model := range All() {
+		s, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		for _, field := range s.Fields {
+			if field.DBName == "" || field.PrimaryKey || !field.HasDefaultValue || field.DefaultValueInterface == nil {
+				continue
+			}
+			switch field.FieldType.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+				if !reflect.ValueOf(field.DefaultValueInterface).IsZero() {
+					t.Errorf("%s.%s has default %v", s.Name, field.Name, field.DefaultValueInterface)
+				}
+			}
+		}
+	}
+}
+
+func TestAllModelsParse(t *testing.T) {
+	seen := make(map[string]bool)
+	cache := &sync.Map{}
+	for _, model := range All() {
+		s, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		if seen[s.Table] {
+			t.Errorf("table %s listed twice", s.Table)
+		}
+		seen[s.Table] = true
+	}
+}
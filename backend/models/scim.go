@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MembershipSourceSCIM marks area memberships managed through SCIM group provisioning
+const MembershipSourceSCIM = "scim"
+
+// ProvisioningToken authenticates an identity system calling the SCIM endpoints
+type ProvisioningToken struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	Name         string     `gorm:"not null" json:"name"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`                  // SHA-256 of the token, the token itself is never stored
+	AuthProvider string     `gorm:"type:varchar(20);not null" json:"auth_provider"` // Identity provider provisioned users sign in with
+	CreatedBy    uint       `gorm:"not null" json:"created_by"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ScimGroup is a group provisioned through SCIM, mapped to an area. Members of the
+// group are members of the area.
+type ScimGroup struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	ExternalID  string         `gorm:"index" json:"external_id"`
+	DisplayName string         `gorm:"not null" json:"display_name"`
+	AreaID      uint           `gorm:"not null;uniqueIndex:idx_scim_group_area,where:deleted_at IS NULL" json:"area_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Area Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// RefreshPrimaryArea makes sure the user's primary area is one of their memberships: when the primary
+// membership is gone, the oldest remaining membership becomes primary, or the user is left without area.
+func RefreshPrimaryArea(tx *gorm.DB, userID uint) error {
+	var memberships []UserArea
+	if err := tx.Where("user_id = ?", userID).Order("is_primary DESC, created_at ASC").Find(&memberships).Error; err != nil {
+		return err
+	}
+	if len(memberships) == 0 {
+		return tx.Model(&User{}).Where("id = ?", userID).Update("area_id", nil).Error
+	}
+	if memberships[0].IsPrimary {
+		return nil
+	}
+	return memberships[0].MakePrimary(tx)
+}
+
+// ============================================
+// SCIM Resources (RFC 7643)
+// ============================================
+
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMMultiValue is an entry of a multi-valued attribute such as emails, groups or members
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type SCIMUser struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	UserName    string           `json:"userName"`
+	Name        *SCIMName        `json:"name,omitempty"`
+	DisplayName string           `json:"displayName,omitempty"`
+	Emails      []SCIMMultiValue `json:"emails,omitempty"`
+	Active      *bool            `json:"active,omitempty"`
+	Groups      []SCIMMultiValue `json:"groups,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []SCIMMultiValue `json:"members,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// SigningKey is an asymmetric key pair used to sign JWTs, identified by its kid
+type SigningKey struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Kid         string     `gorm:"uniqueIndex;not null" json:"kid"`
+	Algorithm   string     `gorm:"type:varchar(10);not null" json:"algorithm"` // RS256 or EdDSA
+	PrivateKey  string     `gorm:"type:text;not null" json:"-"`                // PKCS#8 PEM, encrypted when JWT_KEY_ENCRYPTION_KEY is set
+	PublicKey   string     `gorm:"type:text;not null" json:"public_key"`       // PKIX PEM
+	ActivatedAt time.Time  `gorm:"not null" json:"activated_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"` // No longer signs new tokens
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // No longer verifies tokens, removed from the JWKS
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CanSign checks if the key is the kind of key that signs new tokens
+func (k *SigningKey) CanSign() bool {
+	return k.RetiredAt == nil
+}
+
+// CanVerify checks if tokens signed with the key are still accepted
+func (k *SigningKey) CanVerify(now time.Time) bool {
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Proficiency levels range from 1 (basic) to 5 (expert)
+const (
+	MinSkillLevel = 1
+	MaxSkillLevel = 5
+)
+
+// Skill is an entry of the skills catalog, shared by every area or owned by one
+type Skill struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	Name        string         `gorm:"not null;uniqueIndex:idx_skill_area_name,where:deleted_at IS NULL" json:"name"`
+	Category    string         `gorm:"index" json:"category"`
+	Description string         `json:"description"`
+	AreaID      *uint          `gorm:"index;uniqueIndex:idx_skill_area_name,where:deleted_at IS NULL" json:"area_id"` // Nil for skills available to every area
+	IsActive    bool           `json:"is_active"`
+	CreatedBy   uint           `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+
+	// Relations
+	Area *Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// UserSkill is the proficiency of a user in a skill
+type UserSkill struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_skill" json:"user_id"`
+	SkillID   uint      `gorm:"not null;uniqueIndex:idx_user_skill;index" json:"skill_id"`
+	Level     int       `gorm:"not null" json:"level"` // 1 (basic) to 5 (expert)
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Skill Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty" swaggerignore:"true"`
+}
+
+// ProjectSkill is a skill required to work on a project
+type ProjectSkill struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ProjectID uint      `gorm:"not null;uniqueIndex:idx_project_skill" json:"project_id"`
+	SkillID   uint      `gorm:"not null;uniqueIndex:idx_project_skill" json:"skill_id"`
+	MinLevel  int       `gorm:"not null" json:"min_level"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	Skill Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty" swaggerignore:"true"`
+}
+
+// TaskSkill is a skill required to work on a task
+type TaskSkill struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	TaskID    uint      `gorm:"not null;uniqueIndex:idx_task_skill" json:"task_id"`
+	SkillID   uint      `gorm:"not null;uniqueIndex:idx_task_skill" json:"skill_id"`
+	MinLevel  int       `gorm:"not null" json:"min_level"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	Skill Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty" swaggerignore:"true"`
+}
+
+// AvailableIn checks if the skill can be used in the given area
+func (s *Skill) AvailableIn(areaID *uint) bool {
+	return s.AreaID == nil || (areaID != nil && *s.AreaID == *areaID)
+}
@@ -31,6 +31,7 @@ const (
 type Task struct {
 	ID                uint           `gorm:"primarykey" json:"id"`
 	ProjectID         uint           `gorm:"not null;index:idx_project_status" json:"project_id"` // Composite index with status
+	MilestoneID       *uint          `gorm:"index" json:"milestone_id"`
 	Name              string         `gorm:"not null" json:"name"`
 	Description       string         `gorm:"type:text" json:"description"`
 	Status            TaskStatus     `gorm:"type:varchar(20);not null;default:'backlog';index:idx_project_status" json:"status"` // Composite index
@@ -44,7 +45,7 @@ type Task struct {
 	EndDate           *time.Time     `json:"end_date"`
 	DueDate           *time.Time     `json:"due_date"`
 	Order             int            `gorm:"default:0" json:"order"`
-	IsActive          bool           `gorm:"default:true" json:"is_active"`
+	IsActive          bool           `json:"is_active"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
@@ -56,6 +57,8 @@ type Task struct {
 	Comments        []Comment        `gorm:"foreignKey:TaskID" json:"comments,omitempty" swaggerignore:"true"`
 	AssignedUsers   []User           `gorm:"many2many:task_assignments;joinForeignKey:TaskID;joinReferences:UserID" json:"assigned_users,omitempty" swaggerignore:"true"`
 	TaskAssignments []TaskAssignment `gorm:"foreignKey:TaskID" json:"task_assignments,omitempty" swaggerignore:"true"`
+	RequiredSkills  []TaskSkill      `gorm:"foreignKey:TaskID" json:"required_skills,omitempty" swaggerignore:"true"`
+	Dependencies    []TaskDependency `gorm:"foreignKey:TaskID" json:"dependencies,omitempty" swaggerignore:"true"`
 }
 
 // BeforeSave hook to update task metrics
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// TaskDependency says a task cannot start before another task of the same project is done
+type TaskDependency struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	TaskID          uint      `gorm:"not null;uniqueIndex:idx_task_dependency" json:"task_id"`
+	DependsOnTaskID uint      `gorm:"not null;uniqueIndex:idx_task_dependency;index" json:"depends_on_task_id"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	// Relations
+	DependsOn Task `gorm:"foreignKey:DependsOnTaskID" json:"depends_on,omitempty" swaggerignore:"true"`
+}
+
+// HasDependencyCycle checks if the dependency graph (task -> tasks it depends on) contains a cycle
+func HasDependencyCycle(graph map[uint][]uint) bool {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[uint]int, len(graph))
+	var visit func(uint) bool
+	visit = func(id uint) bool {
+		switch state[id] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[id] = visiting
+		for _, next := range graph[id] {
+			if visit(next) {
+				return true
+			}
+		}
+		state[id] = done
+		return false
+	}
+	for id := range graph {
+		if visit(id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestHasDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph map[uint][]uint
+		want  bool
+	}{
+		{"empty", nil, false},
+		{"single task", map[uint][]uint{1: nil}, false},
+		{"chain", map[uint][]uint{1: {2}, 2: {3}, 3: nil}, false},
+		{"diamond", map[uint][]uint{1: {2, 3}, 2: {4}, 3: {4}}, false},
+		{"separate chains", map[uint][]uint{1: {2}, 3: {4}, 4: {2}}, false},
+		{"self dependency", map[uint][]uint{1: {1}}, true},
+		{"two tasks", map[uint][]uint{1: {2}, 2: {1}}, true},
+		{"long cycle", map[uint][]uint{1: {2}, 2: {3}, 3: {4}, 4: {1}}, true},
+		{"cycle off the main chain", map[uint][]uint{1: {2}, 2: {3}, 3: nil, 5: {6}, 6: {7}, 7: {5}}, true},
+		{"cycle through a task without own entry", map[uint][]uint{1: {2}, 2: {3}, 3: {2}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasDependencyCycle(tt.graph); got != tt.want {
+				t.Errorf("HasDependencyCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
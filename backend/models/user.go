@@ -8,6 +8,22 @@ import (
 	"gorm.io/gorm"
 )
 
+// ApprovalStatus represents the approval state of a self-registered account
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+// Reasons an account was deactivated on purpose
+const (
+	DeactivatedByAdmin       = "admin"
+	DeactivatedByOffboarding = "offboarding"
+	DeactivatedBySCIM        = "scim"
+)
+
 // User represents a user in the system
 type User struct {
 	ID           uint           `gorm:"primarykey" json:"id"`
@@ -18,19 +34,35 @@ type User struct {
 	AreaID       *uint          `gorm:"index" json:"area_id"` // Index added for performance on area filtering
 	WorkSchedule datatypes.JSON `json:"work_schedule" swaggertype:"object"`
 	LunchBreak   datatypes.JSON `json:"lunch_break" swaggertype:"object"`
-	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	IsActive     bool           `json:"is_active"`
+	// Set when the account still uses a known default password
+	MustChangePassword bool `gorm:"default:false" json:"must_change_password"`
+	// Tokens issued before this moment are rejected (set when the account is offboarded)
+	TokensRevokedAt *time.Time `json:"-"`
+	// Set when an admin, offboarding or SCIM disables the account on purpose; cleared on reactivation
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	DeactivationReason string     `gorm:"type:varchar(20)" json:"deactivation_reason,omitempty"`
 	// Microsoft OAuth fields
-	MicrosoftID          *string        `gorm:"index" json:"microsoft_id,omitempty"`                   // Microsoft user ID
-	MicrosoftAccessToken *string        `gorm:"type:text" json:"-"`                                    // Microsoft access token (encrypted, not exposed in JSON)
-	AuthProvider         string         `gorm:"type:varchar(20);default:'local'" json:"auth_provider"` // 'local' or 'microsoft'
-	CreatedAt            time.Time      `json:"created_at"`
-	UpdatedAt            time.Time      `json:"updated_at"`
-	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
+	MicrosoftID          *string `gorm:"index" json:"microsoft_id,omitempty"`                   // Microsoft user ID
+	MicrosoftAccessToken *string `gorm:"type:text" json:"-"`                                    // Microsoft access token (encrypted, not exposed in JSON)
+	AuthProvider         string  `gorm:"type:varchar(20);default:'local'" json:"auth_provider"` // 'local' or the name of an identity provider
+	// ExternalID is the user's ID in the identity system that provisions it through SCIM
+	ExternalID *string `gorm:"index" json:"external_id,omitempty"`
+	// Approval fields (accounts created through SSO wait for SuperAdmin approval)
+	ApprovalStatus  ApprovalStatus `gorm:"type:varchar(20);not null;default:'approved';index" json:"approval_status"`
+	RejectionReason string         `gorm:"type:text" json:"rejection_reason,omitempty"`
+	ReviewedBy      *uint          `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
 
 	// Relations
-	Area       *Area      `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
-	Projects   []Project  `gorm:"foreignKey:CreatedBy" json:"projects,omitempty" swaggerignore:"true"`
-	Activities []Activity `gorm:"foreignKey:UserID" json:"activities,omitempty" swaggerignore:"true"`
+	Area       *Area          `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+	Areas      []UserArea     `gorm:"foreignKey:UserID" json:"areas,omitempty" swaggerignore:"true"`
+	Identities []UserIdentity `gorm:"foreignKey:UserID" json:"identities,omitempty" swaggerignore:"true"`
+	Projects   []Project      `gorm:"foreignKey:CreatedBy" json:"projects,omitempty" swaggerignore:"true"`
+	Activities []Activity     `gorm:"foreignKey:UserID" json:"activities,omitempty" swaggerignore:"true"`
 }
 
 // BeforeCreate hook to hash password before creating user
@@ -57,6 +89,24 @@ func (u *User) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// AfterCreate hook to register the primary area as a membership
+func (u *User) AfterCreate(tx *gorm.DB) error {
+	if u.AreaID != nil {
+		return SyncPrimaryArea(tx.Session(&gorm.Session{NewDB: true}), u)
+	}
+	return nil
+}
+
+// SetPassword hashes and sets a new password on the user
+func (u *User) SetPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.Password = string(hashedPassword)
+	return nil
+}
+
 // CheckPassword compares a password with the user's hashed password
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
@@ -74,6 +124,36 @@ func (u *User) HasAccessToArea(areaID uint) bool {
 	return false
 }
 
+// IsPendingApproval checks if the account is still waiting for SuperAdmin approval
+func (u *User) IsPendingApproval() bool {
+	return u.ApprovalStatus == ApprovalStatusPending
+}
+
+// SetActive activates or deactivates the account, recording when and why it was deactivated
+func (u *User) SetActive(active bool, reason string) {
+	if active == u.IsActive && (active || u.DeactivatedAt != nil) {
+		return
+	}
+	u.IsActive = active
+	if active {
+		u.DeactivatedAt = nil
+		u.DeactivationReason = ""
+		return
+	}
+	now := time.Now()
+	u.DeactivatedAt = &now
+	u.DeactivationReason = reason
+}
+
+// ActivationUpdates returns the columns SetActive changes, for Updates calls
+func (u *User) ActivationUpdates() map[string]interface{} {
+	return map[string]interface{}{
+		"is_active":           u.IsActive,
+		"deactivated_at":      u.DeactivatedAt,
+		"deactivation_reason": u.DeactivationReason,
+	}
+}
+
 // CanManageUsers checks if user can manage other users
 func (u *User) CanManageUsers() bool {
 	return u.Role == RoleSuperAdmin || u.Role == RoleAdmin
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserArea is a user's membership in an area, with the role the user holds in that area.
+// User.AreaID mirrors the membership flagged as primary.
+type UserArea struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_user_area,unique" json:"user_id"`
+	AreaID    uint      `gorm:"not null;index:idx_user_area,unique;index" json:"area_id"`
+	Role      Role      `gorm:"type:varchar(20);not null;default:'user'" json:"role"` // 'admin' or 'user' within this area
+	IsPrimary bool      `gorm:"default:false" json:"is_primary"`
+	Source    string    `gorm:"type:varchar(20);not null;default:'manual'" json:"source"` // 'manual', 'sso' (identity mapping rules) or 'scim' (SCIM groups)
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
+	Area Area `gorm:"foreignKey:AreaID" json:"area,omitempty" swaggerignore:"true"`
+}
+
+// MembershipRoleFor returns the per-area role that matches a built-in role
+func MembershipRoleFor(role Role) Role {
+	if role == RoleAdmin {
+		return RoleAdmin
+	}
+	return RoleUser
+}
+
+// SyncPrimaryArea makes user.AreaID the user's primary membership, creating it if needed
+// with the role matching the user's built-in role. Other memberships stop being primary.
+func SyncPrimaryArea(tx *gorm.DB, user *User) error {
+	if err := tx.Model(&UserArea{}).Where("user_id = ?", user.ID).Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	if user.AreaID == nil {
+		return nil
+	}
+
+	var membership UserArea
+	err := tx.Where("user_id = ? AND area_id = ?", user.ID, *user.AreaID).First(&membership).Error
+	if err == gorm.ErrRecordNotFound {
+		membership = UserArea{UserID: user.ID, AreaID: *user.AreaID}
+	} else if err != nil {
+		return err
+	}
+	membership.Role = MembershipRoleFor(user.Role)
+	membership.IsPrimary = true
+	return tx.Save(&membership).Error
+}
+
+// MakePrimary flags the membership as the user's primary area and mirrors it on User.AreaID
+func (m *UserArea) MakePrimary(tx *gorm.DB) error {
+	if err := tx.Model(&UserArea{}).Where("user_id = ? AND id <> ?", m.UserID, m.ID).Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(m).Update("is_primary", true).Error; err != nil {
+		return err
+	}
+	return tx.Model(&User{}).Where("id = ?", m.UserID).Update("area_id", m.AreaID).Error
+}
+
+// UserAreaIDs returns the IDs of every area the user belongs to
+func UserAreaIDs(db *gorm.DB, userID uint) []uint {
+	var areaIDs []uint
+	db.Model(&UserArea{}).Where("user_id = ?", userID).Pluck("area_id", &areaIDs)
+	return areaIDs
+}
+
+// BelongsToArea checks if the user is a member of the area, either as primary area or through a membership
+func (u *User) BelongsToArea(db *gorm.DB, areaID uint) bool {
+	if u.AreaID != nil && *u.AreaID == areaID {
+		return true
+	}
+	return IsAreaMember(db, u.ID, areaID)
+}
+
+// IsAreaMember checks if the user belongs to the area
+func IsAreaMember(db *gorm.DB, userID, areaID uint) bool {
+	var count int64
+	db.Model(&UserArea{}).Where("user_id = ? AND area_id = ?", userID, areaID).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// AuthProviderLocal is the auth provider of accounts that sign in with a password
+const AuthProviderLocal = "local"
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Provider    string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject     string     `gorm:"not null;uniqueIndex:idx_identity_provider_subject" json:"subject"` // User ID at the provider
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"-" swaggerignore:"true"`
+}
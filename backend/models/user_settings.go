@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Defaults applied to users that never saved their settings
+const (
+	DefaultTimezone  = "UTC"
+	DefaultLocale    = "es"
+	DefaultWeekStart = 1 // Monday
+)
+
+// UserSettings holds the personal preferences of a user
+type UserSettings struct {
+	ID                  uint         `gorm:"primarykey" json:"id"`
+	UserID              uint         `gorm:"not null;uniqueIndex" json:"user_id"`
+	Timezone            string       `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"` // IANA name, e.g. America/Bogota
+	Locale              string       `gorm:"type:varchar(10);not null;default:'es'" json:"locale"`
+	WeekStart           int          `gorm:"not null" json:"week_start"` // 0 = Sunday ... 6 = Saturday
+	DefaultActivityType ActivityType `gorm:"type:varchar(50)" json:"default_activity_type,omitempty"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+
+	location *time.Location
+}
+
+// Location returns the user's timezone, falling back to UTC when it cannot be loaded
+func (s *UserSettings) Location() *time.Location {
+	if s.location == nil {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		s.location = loc
+	}
+	return s.location
+}
+
+// Now returns the current time in the user's timezone
+func (s *UserSettings) Now() time.Time {
+	return time.Now().In(s.Location())
+}
+
+// Today returns the user's current calendar day, as stored in date columns (midnight UTC)
+func (s *UserSettings) Today() time.Time {
+	now := s.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WeekStartOf returns the first day of the week containing the given calendar day
+func (s *UserSettings) WeekStartOf(day time.Time) time.Time {
+	offset := (int(day.Weekday()) - s.WeekStart + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// StartOfDay returns the instant a calendar day begins in the user's timezone
+func (s *UserSettings) StartOfDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.Location())
+}
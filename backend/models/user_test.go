@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserSetActive(t *testing.T) {
+	user := User{IsActive: true}
+
+	user.SetActive(false, DeactivatedByOffboarding)
+	if user.IsActive || user.DeactivatedAt == nil || user.DeactivationReason != DeactivatedByOffboarding {
+		t.Fatalf("after offboarding = active %v at %v reason %q, want a recorded offboarding", user.IsActive, user.DeactivatedAt, user.DeactivationReason)
+	}
+	deactivatedAt := *user.DeactivatedAt
+
+	// Deactivating again keeps the original moment and reason
+	user.SetActive(false, DeactivatedByAdmin)
+	if !user.DeactivatedAt.Equal(deactivatedAt) || user.DeactivationReason != DeactivatedByOffboarding {
+		t.Errorf("second deactivation changed it to %v %q", user.DeactivatedAt, user.DeactivationReason)
+	}
+
+	updates := user.ActivationUpdates()
+	if at, _ := updates["deactivated_at"].(*time.Time); updates["is_active"] != false || at == nil || updates["deactivation_reason"] != DeactivatedByOffboarding {
+		t.Errorf("ActivationUpdates() = %v", updates)
+	}
+
+	user.SetActive(true, "")
+	if !user.IsActive || user.DeactivatedAt != nil || user.DeactivationReason != "" {
+		t.Errorf("after reactivation = active %v at %v reason %q, want cleared", user.IsActive, user.DeactivatedAt, user.DeactivationReason)
+	}
+
+	// An account that was never active, such as one pending approval, is recorded when deactivated on purpose
+	pending := User{}
+	pending.SetActive(false, DeactivatedBySCIM)
+	if pending.DeactivatedAt == nil || pending.DeactivationReason != DeactivatedBySCIM {
+		t.Errorf("deactivating an inactive account = %v %q, want recorded", pending.DeactivatedAt, pending.DeactivationReason)
+	}
+}
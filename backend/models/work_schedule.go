@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DefaultDailyHours are the working hours assumed on weekdays for users without a work schedule
+const DefaultDailyHours = 8.0
+
+// WorkDay is one day of a user's work schedule, as stored by the settings page
+type WorkDay struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"` // HH:MM
+	End     string `json:"end"`   // HH:MM
+}
+
+// DailyHours returns the hours the user works on each weekday, after the lunch break
+func (u *User) DailyHours() map[time.Weekday]float64 {
+	hours := make(map[time.Weekday]float64, 7)
+
+	var schedule map[string]WorkDay
+	if len(u.WorkSchedule) == 0 || json.Unmarshal(u.WorkSchedule, &schedule) != nil || len(schedule) == 0 {
+		for day := time.Monday; day <= time.Friday; day++ {
+			hours[day] = DefaultDailyHours
+		}
+		return hours
+	}
+
+	var lunch WorkDay
+	if len(u.LunchBreak) > 0 {
+		_ = json.Unmarshal(u.LunchBreak, &lunch)
+	}
+	lunchHours := 0.0
+	if lunch.Enabled {
+		lunchHours = clockHours(lunch.Start, lunch.End)
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		workDay, ok := schedule[strings.ToLower(day.String())]
+		if !ok || !workDay.Enabled {
+			continue
+		}
+		// Same defaults the settings page shows for days without times
+		if workDay.Start == "" {
+			workDay.Start = "09:00"
+		}
+		if workDay.End == "" {
+			workDay.End = "18:00"
+		}
+		if worked := clockHours(workDay.Start, workDay.End) - lunchHours; worked > 0 {
+			hours[day] = worked
+		}
+	}
+	return hours
+}
+
+// ScheduledHours returns the hours the user works in the calendar days from..to (both included)
+func (u *User) ScheduledHours(from, to time.Time) float64 {
+	daily := u.DailyHours()
+	total := 0.0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		total += daily[day.Weekday()]
+	}
+	return total
+}
+
+// clockHours returns the hours between two HH:MM times
+func clockHours(start, end string) float64 {
+	s, err1 := time.Parse("15:04", start)
+	e, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil || !e.After(s) {
+		return 0
+	}
+	return e.Sub(s).Hours()
+}
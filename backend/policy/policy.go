@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+)
+
+// contextKey is where the subject is cached for the duration of a request
+const contextKey = "policy_subject"
+
+// adminPermissions are granted to the built-in admin role within the admin's area
+var adminPermissions = []models.Permission{
+	models.PermUsersView,
+	models.PermUsersManage,
+	models.PermInvitationsManage,
+	models.PermRolesAssign,
+	models.PermProjectsView,
+	models.PermProjectsManage,
+	models.PermTasksView,
+	models.PermTasksManage,
+	models.PermActivitiesView,
+	models.PermStatsView,
+	models.PermTimesheetsApprove,
+	models.PermSkillsManage,
+	models.PermActivityTypesManage,
+	models.PermRatesManage,
+	models.PermClientsManage,
+	models.PermInvoicesManage,
+}
+
+// userPermissions are granted to the built-in user role
+var userPermissions = []models.Permission{
+	models.PermActivitiesLog,
+}
+
+// Subject is the authenticated user that authorization decisions are made for
+type Subject struct {
+	UserID uint
+	Role   models.Role
+	AreaID *uint
+
+	loaded  bool
+	global  map[models.Permission]bool
+	areas   map[models.Permission]map[uint]bool
+	members map[uint]models.Role // area ID -> role within that area
+
+	projectRoles map[uint]models.MemberRole // project ID -> member role, empty when not a member
+	sharedAreas  map[uint][]uint            // project ID -> areas the project is shared with
+}
+
+// FromContext returns the subject for the authenticated request, loading its grants once per request
+func FromContext(c *gin.Context) *Subject {
+	if cached, ok := c.Get(contextKey); ok {
+		return cached.(*Subject)
+	}
+
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+	userAreaID, _ := c.Get("user_area_id")
+
+	s := &Subject{}
+	if id, ok := userID.(uint); ok {
+		s.UserID = id
+	}
+	if role, ok := userRole.(models.Role); ok {
+		s.Role = role
+	}
+	if areaID, ok := userAreaID.(*uint); ok {
+		s.AreaID = areaID
+	}
+
+	c.Set(contextKey, s)
+	return s
+}
+
+// ForUser returns the subject for a user other than the caller, e.g. to find who a notification concerns
+func ForUser(user *models.User) *Subject {
+	return &Subject{UserID: user.ID, Role: user.Role, AreaID: user.AreaID}
+}
+
+// load resolves the permissions granted by the built-in role and by custom role assignments
+func (s *Subject) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.global = make(map[models.Permission]bool)
+	s.areas = make(map[models.Permission]map[uint]bool)
+	s.members = make(map[uint]models.Role)
+
+	var memberships []models.UserArea
+	config.DB.Where("user_id = ?", s.UserID).Find(&memberships)
+	for _, m := range memberships {
+		s.members[m.AreaID] = m.Role
+	}
+	// Accounts not yet migrated to memberships still count their primary area
+	if len(memberships) == 0 && s.AreaID != nil {
+		s.members[*s.AreaID] = models.MembershipRoleFor(s.Role)
+	}
+
+	var grants []roleGrant
+	if s.Role != models.RoleSuperAdmin {
+		config.DB.Table("role_assignments").
+			Select("role_assignments.area_id, role_permissions.permission").
+			Joins("JOIN role_definitions ON role_definitions.id = role_assignments.role_id AND role_definitions.deleted_at IS NULL").
+			Joins("JOIN role_permissions ON role_permissions.role_id = role_assignments.role_id").
+			Where("role_assignments.user_id = ? AND role_assignments.deleted_at IS NULL", s.UserID).
+			Scan(&grants)
+	}
+	s.grant(grants)
+
+	if len(s.areas) > 0 {
+		s.expandToDescendants(models.LoadAreaTree(config.DB))
+	}
+}
+
+// roleGrant is a permission given by a custom role assignment, in one area or in every area (nil AreaID)
+type roleGrant struct {
+	AreaID     *uint
+	Permission models.Permission
+}
+
+// grant applies the built-in role, following the role held in each member area, and the custom role grants.
+// Global-only permissions granted within an area are ignored.
+func (s *Subject) grant(grants []roleGrant) {
+	switch s.Role {
+	case models.RoleSuperAdmin:
+		for _, info := range models.PermissionCatalog {
+			s.global[info.Name] = true
+		}
+		return
+	case models.RoleUser:
+		for _, p := range userPermissions {
+			s.global[p] = true
+		}
+	}
+
+	// Admin permissions follow the role held in each area
+	for areaID, role := range s.members {
+		if role == models.RoleAdmin {
+			for _, p := range adminPermissions {
+				s.grantArea(p, areaID)
+			}
+		}
+	}
+
+	for _, g := range grants {
+		if g.AreaID == nil {
+			s.global[g.Permission] = true
+		} else if !g.Permission.IsGlobalOnly() {
+			s.grantArea(g.Permission, *g.AreaID)
+		}
+	}
+}
+
+// expandToDescendants extends every area grant to the areas below it in the hierarchy
+func (s *Subject) expandToDescendants(tree *models.AreaTree) {
+	for p, areaIDs := range s.areas {
+		granted := make([]uint, 0, len(areaIDs))
+		for areaID := range areaIDs {
+			granted = append(granted, areaID)
+		}
+		for _, areaID := range granted {
+			for _, descendantID := range tree.Descendants(areaID) {
+				s.grantArea(p, descendantID)
+			}
+		}
+	}
+}
+
+func (s *Subject) grantArea(p models.Permission, areaID uint) {
+	if s.areas[p] == nil {
+		s.areas[p] = make(map[uint]bool)
+	}
+	s.areas[p][areaID] = true
+}
+
+// IsMemberOf checks if the subject belongs to the area
+func (s *Subject) IsMemberOf(areaID uint) bool {
+	s.load()
+	_, ok := s.members[areaID]
+	return ok
+}
+
+// Can checks if the subject holds a permission for the given area.
+// A nil area (e.g. personal projects) only matches global grants.
+func (s *Subject) Can(p models.Permission, areaID *uint) bool {
+	s.load()
+	if s.global[p] {
+		return true
+	}
+	return areaID != nil && s.areas[p][*areaID]
+}
+
+// CanAll checks if the subject holds every permission for the given area
+func (s *Subject) CanAll(perms []models.Permission, areaID *uint) bool {
+	for _, p := range perms {
+		if !s.Can(p, areaID) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGlobal checks if the subject holds a permission for every area
+func (s *Subject) IsGlobal(p models.Permission) bool {
+	s.load()
+	return s.global[p]
+}
+
+// CanAnywhere checks if the subject holds a permission globally or in at least one area
+func (s *Subject) CanAnywhere(p models.Permission) bool {
+	s.load()
+	return s.global[p] || len(s.areas[p]) > 0
+}
+
+// AreaScope returns the areas where the subject holds a permission. When global is true
+// the permission applies everywhere and areaIDs is nil.
+func (s *Subject) AreaScope(p models.Permission) (global bool, areaIDs []uint) {
+	s.load()
+	if s.global[p] {
+		return true, nil
+	}
+	for areaID := range s.areas[p] {
+		areaIDs = append(areaIDs, areaID)
+	}
+	return false, areaIDs
+}
@@ -0,0 +1,275 @@
+package policy
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/jaliko05/time-flow/models"
+)
+
+// testSubject resolves the subject's grants from its memberships and custom role grants, without a database
+func testSubject(role models.Role, members map[uint]models.Role, grants ...roleGrant) *Subject {
+	s := &Subject{
+		UserID:  7,
+		Role:    role,
+		loaded:  true,
+		global:  make(map[models.Permission]bool),
+		areas:   make(map[models.Permission]map[uint]bool),
+		members: members,
+	}
+	if s.members == nil {
+		s.members = make(map[uint]models.Role)
+	}
+	s.grant(grants)
+	return s
+}
+
+func area(id uint) *uint {
+	return &id
+}
+
+func TestSubjectCan(t *testing.T) {
+	superAdmin := testSubject(models.RoleSuperAdmin, nil)
+	user := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleUser})
+	areaAdmin := testSubject(models.RoleAdmin, map[uint]models.Role{1: models.RoleAdmin, 2: models.RoleUser})
+	projectLead := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleUser},
+		roleGrant{AreaID: area(3), Permission: models.PermProjectsManage},
+		roleGrant{Permission: models.PermStatsView})
+
+	tests := []struct {
+		name    string
+		subject *Subject
+		perm    models.Permission
+		areaID  *uint
+		want    bool
+	}{
+		{"super admin anywhere", superAdmin, models.PermAreasManage, area(9), true},
+		{"super admin without area", superAdmin, models.PermRolesManage, nil, true},
+		{"user logs activities", user, models.PermActivitiesLog, area(1), true},
+		{"user logs activities without area", user, models.PermActivitiesLog, nil, true},
+		{"user cannot manage projects", user, models.PermProjectsManage, area(1), false},
+		{"admin manages own area", areaAdmin, models.PermProjectsManage, area(1), true},
+		{"admin in area where plain member", areaAdmin, models.PermProjectsManage, area(2), false},
+		{"admin outside own areas", areaAdmin, models.PermProjectsManage, area(3), false},
+		{"admin without area", areaAdmin, models.PermProjectsManage, nil, false},
+		{"admin cannot manage areas", areaAdmin, models.PermAreasManage, area(1), false},
+		{"admin does not log activities", areaAdmin, models.PermActivitiesLog, area(1), false},
+		{"custom role in its area", projectLead, models.PermProjectsManage, area(3), true},
+		{"custom role outside its area", projectLead, models.PermProjectsManage, area(1), false},
+		{"global custom role", projectLead, models.PermStatsView, area(5), true},
+		{"global custom role without area", projectLead, models.PermStatsView, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.subject.Can(tt.perm, tt.areaID); got != tt.want {
+				t.Errorf("Can(%s) = %v, want %v", tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubjectGlobalOnlyPermissions(t *testing.T) {
+	for _, p := range []models.Permission{models.PermAreasManage, models.PermRolesManage, models.PermUsersApprove} {
+		t.Run(string(p), func(t *testing.T) {
+			if !p.IsGlobalOnly() {
+				t.Fatalf("%s is not global only", p)
+			}
+
+			scoped := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleUser}, roleGrant{AreaID: area(1), Permission: p})
+			if scoped.Can(p, area(1)) || scoped.CanAnywhere(p) || scoped.IsGlobal(p) {
+				t.Errorf("%s granted within an area acts as a grant", p)
+			}
+
+			global := testSubject(models.RoleUser, nil, roleGrant{Permission: p})
+			if !global.CanAnywhere(p) || !global.IsGlobal(p) || !global.Can(p, area(4)) {
+				t.Errorf("%s granted globally does not apply", p)
+			}
+		})
+	}
+
+	for _, p := range []models.Permission{models.PermRolesAssign, models.PermProjectsManage, models.PermUsersManage} {
+		if p.IsGlobalOnly() {
+			t.Errorf("%s should be assignable within an area", p)
+		}
+	}
+}
+
+func TestSubjectScope(t *testing.T) {
+	areaAdmin := testSubject(models.RoleAdmin, map[uint]models.Role{1: models.RoleAdmin, 4: models.RoleAdmin, 2: models.RoleUser},
+		roleGrant{AreaID: area(6), Permission: models.PermStatsView})
+
+	global, areaIDs := areaAdmin.AreaScope(models.PermStatsView)
+	sort.Slice(areaIDs, func(i, j int) bool { return areaIDs[i] < areaIDs[j] })
+	if global || len(areaIDs) != 3 || areaIDs[0] != 1 || areaIDs[1] != 4 || areaIDs[2] != 6 {
+		t.Errorf("AreaScope(stats.view) = %v, %v, want false, [1 4 6]", global, areaIDs)
+	}
+	if !areaAdmin.CanAnywhere(models.PermStatsView) || areaAdmin.IsGlobal(models.PermStatsView) {
+		t.Error("area grants should count anywhere but not globally")
+	}
+	if areaAdmin.CanAnywhere(models.PermRolesManage) {
+		t.Error("area admin should not manage roles")
+	}
+
+	superAdmin := testSubject(models.RoleSuperAdmin, nil)
+	if global, areaIDs := superAdmin.AreaScope(models.PermStatsView); !global || areaIDs != nil {
+		t.Errorf("AreaScope() for super admin = %v, %v, want true, nil", global, areaIDs)
+	}
+
+	perms := []models.Permission{models.PermProjectsManage, models.PermTasksManage}
+	if !areaAdmin.CanAll(perms, area(4)) {
+		t.Error("CanAll() in admin area = false")
+	}
+	if areaAdmin.CanAll(append(perms, models.PermRolesManage), area(4)) {
+		t.Error("CanAll() with a missing permission = true")
+	}
+}
+
+func TestSubjectMembershipAndBaseRoles(t *testing.T) {
+	member := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleUser, 2: models.RoleAdmin})
+	if !member.IsMemberOf(1) || !member.IsMemberOf(2) || member.IsMemberOf(3) {
+		t.Error("IsMemberOf() does not follow the memberships")
+	}
+	if !member.CanLogActivities() {
+		t.Error("user cannot log activities")
+	}
+	if !member.Can(models.PermUsersManage, area(2)) {
+		t.Error("admin membership does not grant admin permissions in the area")
+	}
+
+	if !member.CanAssignBaseRole(models.RoleUser) || member.CanAssignBaseRole(models.RoleAdmin) {
+		t.Error("area admins can only give the user role")
+	}
+	globalManager := testSubject(models.RoleUser, nil, roleGrant{Permission: models.PermUsersManage})
+	if !globalManager.CanAssignBaseRole(models.RoleAdmin) || !globalManager.CanAssignBaseRole(models.RoleSuperAdmin) {
+		t.Error("global users.manage holders can give every role")
+	}
+}
+
+func TestSubjectProjectMemberRoles(t *testing.T) {
+	project := &models.Project{ID: 100, AreaID: area(1), ProjectType: models.ProjectTypeArea}
+
+	tests := []struct {
+		name         string
+		role         models.MemberRole // Empty when not a member
+		manage       bool
+		manageTasks  bool
+		updateStatus bool
+	}{
+		{"owner", models.MemberRoleOwner, true, true, true},
+		{"manager", models.MemberRoleManager, false, true, true},
+		{"contributor", models.MemberRoleContributor, false, false, true},
+		{"viewer", models.MemberRoleViewer, false, false, false},
+		{"not a member", "", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleUser})
+			s.projectRoles = map[uint]models.MemberRole{project.ID: tt.role}
+
+			if got := s.CanManageProject(project); got != tt.manage {
+				t.Errorf("CanManageProject() = %v, want %v", got, tt.manage)
+			}
+			if got := s.CanManageTasksIn(project); got != tt.manageTasks {
+				t.Errorf("CanManageTasksIn() = %v, want %v", got, tt.manageTasks)
+			}
+			if got := s.CanManageTask(&models.Task{ProjectID: project.ID, Project: *project}); got != tt.manageTasks {
+				t.Errorf("CanManageTask() = %v, want %v", got, tt.manageTasks)
+			}
+			if got := s.CanUpdateProjectStatus(project); got != tt.updateStatus {
+				t.Errorf("CanUpdateProjectStatus() = %v, want %v", got, tt.updateStatus)
+			}
+		})
+	}
+
+	t.Run("area managers do not need a member role", func(t *testing.T) {
+		s := testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleAdmin})
+		s.projectRoles = map[uint]models.MemberRole{project.ID: models.MemberRoleViewer}
+		if !s.CanManageProject(project) || !s.CanManageTasksIn(project) || !s.CanUpdateProjectStatus(project) {
+			t.Error("an area admin who is a viewer member lost the area's project and task permissions")
+		}
+	})
+
+	t.Run("personal project creator", func(t *testing.T) {
+		personal := &models.Project{ID: 101, ProjectType: models.ProjectTypePersonal, CreatedBy: 7}
+		s := testSubject(models.RoleUser, nil)
+		s.projectRoles = map[uint]models.MemberRole{personal.ID: ""}
+		if !s.CanManageTasksIn(personal) || !s.CanUpdateProjectStatus(personal) {
+			t.Error("the creator cannot manage their personal project")
+		}
+		personal.CreatedBy = 8
+		if s.CanManageTasksIn(personal) || s.CanUpdateProjectStatus(personal) {
+			t.Error("another user's personal project can be managed")
+		}
+	})
+}
+
+func TestSubjectSharedProjects(t *testing.T) {
+	// Hosted in area 1 and shared with areas 2 and 3
+	project := &models.Project{ID: 200, AreaID: area(1), ProjectType: models.ProjectTypeArea}
+	shared := map[uint][]uint{project.ID: {2, 3}}
+
+	tests := []struct {
+		name     string
+		subject  *Subject
+		member   models.MemberRole
+		view     bool
+		manage   bool
+		all      bool
+		assignTo []uint
+	}{
+		{
+			name:    "host area admin",
+			subject: testSubject(models.RoleUser, map[uint]models.Role{1: models.RoleAdmin}),
+			view:    true, manage: true, assignTo: []uint{1, 2, 3},
+		},
+		{
+			name:    "shared area admin",
+			subject: testSubject(models.RoleUser, map[uint]models.Role{3: models.RoleAdmin}),
+			view:    true, assignTo: []uint{3},
+		},
+		{
+			name:    "project owner",
+			subject: testSubject(models.RoleUser, map[uint]models.Role{2: models.RoleUser}),
+			member:  models.MemberRoleOwner,
+			view:    true, manage: true, assignTo: []uint{1, 2, 3},
+		},
+		{
+			name:    "project contributor",
+			subject: testSubject(models.RoleUser, map[uint]models.Role{2: models.RoleUser}),
+			member:  models.MemberRoleContributor,
+			view:    true,
+		},
+		{
+			name:    "admin of an unrelated area",
+			subject: testSubject(models.RoleUser, map[uint]models.Role{4: models.RoleAdmin}),
+		},
+		{
+			name:    "global project manager",
+			subject: testSubject(models.RoleUser, nil, roleGrant{Permission: models.PermProjectsManage}),
+			manage:  true, all: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.subject
+			s.projectRoles = map[uint]models.MemberRole{project.ID: tt.member}
+			s.sharedAreas = shared
+
+			if got := s.CanViewProject(project); got != tt.view {
+				t.Errorf("CanViewProject() = %v, want %v", got, tt.view)
+			}
+			if got := s.CanManageProject(project); got != tt.manage {
+				t.Errorf("CanManageProject() = %v, want %v", got, tt.manage)
+			}
+			all, areaIDs := s.AssignableAreas(project)
+			sort.Slice(areaIDs, func(i, j int) bool { return areaIDs[i] < areaIDs[j] })
+			if all != tt.all || !slices.Equal(areaIDs, tt.assignTo) {
+				t.Errorf("AssignableAreas() = %v, %v, want %v, %v", all, areaIDs, tt.all, tt.assignTo)
+			}
+		})
+	}
+}
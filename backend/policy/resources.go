@@ -0,0 +1,300 @@
+package policy
+
+import (
+	"github.com/jaliko05/time-flow/config"
+	"github.com/jaliko05/time-flow/models"
+	"gorm.io/gorm"
+)
+
+// Subqueries selecting the projects and tasks a user is actively assigned to
+const (
+	assignedProjectsSQL = "SELECT project_id FROM project_assignments WHERE user_id = ? AND is_active = ? AND deleted_at IS NULL"
+	assignedTasksSQL    = "SELECT task_id FROM task_assignments WHERE user_id = ? AND is_active = ? AND deleted_at IS NULL"
+	areaMembersSQL      = "SELECT user_id FROM user_areas WHERE area_id IN ?"
+	sharedProjectsSQL   = "SELECT project_id FROM project_areas WHERE area_id IN ?"
+)
+
+// permissionHoldersSQL selects the users with a custom role assignment that includes a permission
+const permissionHoldersSQL = "SELECT role_assignments.user_id FROM role_assignments " +
+	"JOIN role_definitions ON role_definitions.id = role_assignments.role_id AND role_definitions.deleted_at IS NULL " +
+	"JOIN role_permissions ON role_permissions.role_id = role_assignments.role_id " +
+	"WHERE role_permissions.permission = ? AND role_assignments.deleted_at IS NULL"
+
+// IsAssignedToProject checks if the subject has an active assignment on the project
+func (s *Subject) IsAssignedToProject(projectID uint) bool {
+	_, ok := s.ProjectRole(projectID)
+	return ok
+}
+
+// ProjectRole returns the subject's member role on the project, if they have an active assignment.
+// Like the grants, it is looked up once per request.
+func (s *Subject) ProjectRole(projectID uint) (models.MemberRole, bool) {
+	if role, ok := s.projectRoles[projectID]; ok {
+		return role, role != ""
+	}
+	role, _ := models.ProjectMemberRole(config.DB, projectID, s.UserID)
+	if s.projectRoles == nil {
+		s.projectRoles = make(map[uint]models.MemberRole)
+	}
+	s.projectRoles[projectID] = role
+	return role, role != ""
+}
+
+// IsAssignedToTask checks if the subject has an active assignment on the task
+func (s *Subject) IsAssignedToTask(taskID uint) bool {
+	var count int64
+	config.DB.Model(&models.TaskAssignment{}).
+		Where("task_id = ? AND user_id = ? AND is_active = ?", taskID, s.UserID, true).
+		Count(&count)
+	return count > 0
+}
+
+// ownsPersonalProject checks if the project is a personal project created by the subject
+func (s *Subject) ownsPersonalProject(project *models.Project) bool {
+	return project.ProjectType == models.ProjectTypePersonal && project.CreatedBy == s.UserID
+}
+
+// sharedAreaIDs returns the areas the project is shared with, looked up once per request
+func (s *Subject) sharedAreaIDs(projectID uint) []uint {
+	if areaIDs, ok := s.sharedAreas[projectID]; ok {
+		return areaIDs
+	}
+	if s.sharedAreas == nil {
+		s.sharedAreas = make(map[uint][]uint)
+	}
+	s.sharedAreas[projectID] = models.SharedAreaIDs(config.DB, projectID)
+	return s.sharedAreas[projectID]
+}
+
+// canInSharedArea checks if the subject holds the permission in one of the areas the project is shared with
+func (s *Subject) canInSharedArea(p models.Permission, projectID uint) bool {
+	for _, areaID := range s.sharedAreaIDs(projectID) {
+		if s.Can(p, &areaID) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanViewProject checks if the subject can see a project
+func (s *Subject) CanViewProject(project *models.Project) bool {
+	return s.Can(models.PermProjectsView, project.AreaID) ||
+		s.ownsPersonalProject(project) ||
+		s.IsAssignedToProject(project.ID) ||
+		s.canInSharedArea(models.PermProjectsView, project.ID)
+}
+
+// CanManageProject checks if the subject can update, assign or delete a project: project managers of its area and its owners
+func (s *Subject) CanManageProject(project *models.Project) bool {
+	if s.Can(models.PermProjectsManage, project.AreaID) {
+		return true
+	}
+	role, ok := s.ProjectRole(project.ID)
+	return ok && role == models.MemberRoleOwner
+}
+
+// AssignableAreas returns the areas whose members the subject can assign to the project. Global project managers
+// can assign anyone; those who manage the project, members of its host and shared areas; project managers
+// of a shared area, members of the shared areas they manage. all is also true for personal projects the
+// subject manages, which are not limited to an area.
+func (s *Subject) AssignableAreas(project *models.Project) (all bool, areaIDs []uint) {
+	if s.IsGlobal(models.PermProjectsManage) {
+		return true, nil
+	}
+	if project.ProjectType != models.ProjectTypeArea {
+		return s.CanManageProject(project), nil
+	}
+	if s.CanManageProject(project) {
+		if project.AreaID == nil {
+			return false, nil
+		}
+		return false, append([]uint{*project.AreaID}, s.sharedAreaIDs(project.ID)...)
+	}
+	for _, areaID := range s.sharedAreaIDs(project.ID) {
+		if s.Can(models.PermProjectsManage, &areaID) {
+			areaIDs = append(areaIDs, areaID)
+		}
+	}
+	return false, areaIDs
+}
+
+// CanCloneProject checks if the subject can copy a project into a new one of the same area
+func (s *Subject) CanCloneProject(project *models.Project) bool {
+	return s.Can(models.PermProjectsManage, project.AreaID) || s.ownsPersonalProject(project)
+}
+
+// CanUpdateProjectStatus checks if the subject can move a project between statuses; members need a role other than viewer
+func (s *Subject) CanUpdateProjectStatus(project *models.Project) bool {
+	if s.CanManageProject(project) || s.ownsPersonalProject(project) {
+		return true
+	}
+	role, ok := s.ProjectRole(project.ID)
+	return ok && role.CanLogTime()
+}
+
+// CanViewTask checks if the subject can see a task. The task's Project must be loaded.
+func (s *Subject) CanViewTask(task *models.Task) bool {
+	return s.Can(models.PermTasksView, task.Project.AreaID) ||
+		s.ownsPersonalProject(&task.Project) ||
+		s.IsAssignedToTask(task.ID) ||
+		s.IsAssignedToProject(task.ProjectID) ||
+		s.canInSharedArea(models.PermTasksView, task.ProjectID)
+}
+
+// CanManageTask checks if the subject can create, edit, assign or delete a task. The task's Project must be loaded.
+func (s *Subject) CanManageTask(task *models.Task) bool {
+	return s.CanManageTasksIn(&task.Project)
+}
+
+// CanManageTasksIn checks if the subject can create and manage tasks within a project: task managers of its area,
+// the owner of a personal project and the project's owners and managers
+func (s *Subject) CanManageTasksIn(project *models.Project) bool {
+	if s.Can(models.PermTasksManage, project.AreaID) || s.ownsPersonalProject(project) {
+		return true
+	}
+	role, ok := s.ProjectRole(project.ID)
+	return ok && role.CanManageTasks()
+}
+
+// CanUpdateTaskStatus checks if the subject can move a task between statuses. The task's Project must be loaded.
+func (s *Subject) CanUpdateTaskStatus(task *models.Task) bool {
+	return s.CanManageTask(task) || s.IsAssignedToTask(task.ID)
+}
+
+// CanViewActivity checks if the subject can see an activity
+func (s *Subject) CanViewActivity(activity *models.Activity) bool {
+	return activity.UserID == s.UserID || s.Can(models.PermActivitiesView, activity.AreaID)
+}
+
+// CanLogActivities checks if the subject can register their own activities
+func (s *Subject) CanLogActivities() bool {
+	return s.CanAnywhere(models.PermActivitiesLog)
+}
+
+// CanManageUser checks if the subject can update another user, i.e. manages users in one of the user's areas
+func (s *Subject) CanManageUser(user *models.User) bool {
+	if s.Can(models.PermUsersManage, user.AreaID) {
+		return true
+	}
+	for _, areaID := range models.UserAreaIDs(config.DB, user.ID) {
+		if s.Can(models.PermUsersManage, &areaID) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAssignBaseRole checks if the subject can give a user one of the built-in roles.
+// Only holders of the global users.manage permission can create admins or super admins.
+func (s *Subject) CanAssignBaseRole(role models.Role) bool {
+	return role == models.RoleUser || s.IsGlobal(models.PermUsersManage)
+}
+
+// ProjectManagers returns the active users who manage the project: holders of projects.manage in its host or
+// shared areas (SuperAdmins only when they are members), its owner and manager members and, for personal
+// projects, their creator
+func ProjectManagers(project *models.Project) []models.User {
+	memberRoles := []models.MemberRole{models.MemberRoleOwner, models.MemberRoleManager}
+	query := config.DB.Where("is_active = ?", true)
+	if project.ProjectType == models.ProjectTypePersonal {
+		query = query.Where("(id = ? OR id IN (SELECT user_id FROM project_assignments WHERE project_id = ? AND is_active = ? AND role IN ? AND deleted_at IS NULL))",
+			project.CreatedBy, project.ID, true, memberRoles)
+	} else {
+		query = query.Where("id IN (SELECT user_id FROM project_assignments WHERE project_id = ? AND is_active = ? AND role IN ? AND deleted_at IS NULL)",
+			project.ID, true, memberRoles)
+	}
+	var users []models.User
+	query.Find(&users)
+
+	seen := make(map[uint]bool, len(users))
+	for _, user := range users {
+		seen[user.ID] = true
+	}
+
+	areaIDs := models.ProjectAreaIDs(config.DB, project)
+	if len(areaIDs) == 0 {
+		return users
+	}
+
+	// Candidates are admins of some area and holders of a custom role with the permission;
+	// the subject of each one decides whether it applies to the project's areas
+	var candidates []models.User
+	config.DB.Where("is_active = ? AND role <> ?", true, models.RoleSuperAdmin).
+		Where("(role = ? OR id IN (SELECT user_id FROM user_areas WHERE role = ?) OR id IN ("+permissionHoldersSQL+"))",
+			models.RoleAdmin, models.RoleAdmin, models.PermProjectsManage).
+		Find(&candidates)
+	for _, candidate := range candidates {
+		if seen[candidate.ID] {
+			continue
+		}
+		subject := ForUser(&candidate)
+		for _, areaID := range areaIDs {
+			if subject.Can(models.PermProjectsManage, &areaID) {
+				users = append(users, candidate)
+				break
+			}
+		}
+	}
+	return users
+}
+
+// ScopeProjects restricts a projects query to the projects the subject can see
+func (s *Subject) ScopeProjects(db *gorm.DB) *gorm.DB {
+	global, areaIDs := s.AreaScope(models.PermProjectsView)
+	if global {
+		return db
+	}
+	return db.Where("(projects.area_id IN ? OR projects.id IN ("+sharedProjectsSQL+") OR projects.id IN ("+assignedProjectsSQL+") OR (projects.project_type = ? AND projects.created_by = ?))",
+		models.NonEmptyIDs(areaIDs), models.NonEmptyIDs(areaIDs), s.UserID, true, models.ProjectTypePersonal, s.UserID)
+}
+
+// ScopeTasks restricts a tasks query to the tasks the subject can see
+func (s *Subject) ScopeTasks(db *gorm.DB) *gorm.DB {
+	global, areaIDs := s.AreaScope(models.PermTasksView)
+	if global {
+		return db
+	}
+	return db.Where("(tasks.project_id IN (SELECT id FROM projects WHERE area_id IN ? OR (project_type = ? AND created_by = ?)) OR tasks.project_id IN ("+sharedProjectsSQL+") OR tasks.id IN ("+assignedTasksSQL+") OR tasks.project_id IN ("+assignedProjectsSQL+"))",
+		models.NonEmptyIDs(areaIDs), models.ProjectTypePersonal, s.UserID, models.NonEmptyIDs(areaIDs), s.UserID, true, s.UserID, true)
+}
+
+// ScopeActivities restricts an activities query to the activities the subject can see
+func (s *Subject) ScopeActivities(db *gorm.DB) *gorm.DB {
+	global, areaIDs := s.AreaScope(models.PermActivitiesView)
+	if global {
+		return db
+	}
+	return db.Where("(activities.user_id = ? OR activities.area_id IN ?)", s.UserID, models.NonEmptyIDs(areaIDs))
+}
+
+// ScopeUsers restricts a users query to the users the subject can see
+func (s *Subject) ScopeUsers(db *gorm.DB) *gorm.DB {
+	return s.ScopeAreaMembers(db, models.PermUsersView)
+}
+
+// ScopeAreaMembers restricts a users query to members of the areas where the subject holds a permission
+func (s *Subject) ScopeAreaMembers(db *gorm.DB, p models.Permission) *gorm.DB {
+	global, areaIDs := s.AreaScope(p)
+	if global {
+		return db
+	}
+	return db.Where("(users.area_id IN ? OR users.id IN ("+areaMembersSQL+"))", models.NonEmptyIDs(areaIDs), models.NonEmptyIDs(areaIDs))
+}
+
+// ScopeProjectAreas restricts a projects query to the projects hosted in or shared with the areas where the subject holds a permission
+func (s *Subject) ScopeProjectAreas(db *gorm.DB, p models.Permission) *gorm.DB {
+	global, areaIDs := s.AreaScope(p)
+	if global {
+		return db
+	}
+	return models.ScopeProjectsInAreas(db, areaIDs)
+}
+
+// ScopeAreas restricts a query on an area column to the areas where the subject holds a permission
+func (s *Subject) ScopeAreas(db *gorm.DB, p models.Permission, column string) *gorm.DB {
+	global, areaIDs := s.AreaScope(p)
+	if global {
+		return db
+	}
+	return db.Where(column+" IN ?", models.NonEmptyIDs(areaIDs))
+}
@@ -20,18 +20,56 @@ func SetupRoutes(router *gin.Engine) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Public keys for verifying issued tokens
+	router.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
+	// SCIM 2.0 provisioning (authenticated with a provisioning token)
+	scim := router.Group("/scim/v2")
+	scim.Use(middleware.SCIMAuthMiddleware())
+	{
+		scim.GET("/ServiceProviderConfig", handlers.SCIMServiceProviderConfig)
+		scim.GET("/ResourceTypes", handlers.SCIMResourceTypes)
+
+		scim.GET("/Users", handlers.SCIMGetUsers)
+		scim.GET("/Users/:id", handlers.SCIMGetUser)
+		scim.POST("/Users", handlers.SCIMCreateUser)
+		scim.PUT("/Users/:id", handlers.SCIMReplaceUser)
+		scim.PATCH("/Users/:id", handlers.SCIMPatchUser)
+		scim.DELETE("/Users/:id", handlers.SCIMDeleteUser)
+
+		scim.GET("/Groups", handlers.SCIMGetGroups)
+		scim.GET("/Groups/:id", handlers.SCIMGetGroup)
+		scim.POST("/Groups", handlers.SCIMCreateGroup)
+		scim.PUT("/Groups/:id", handlers.SCIMReplaceGroup)
+		scim.PATCH("/Groups/:id", handlers.SCIMPatchGroup)
+		scim.DELETE("/Groups/:id", handlers.SCIMDeleteGroup)
+	}
+
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
+		// First-run setup routes (registered before RequireSetupComplete so they stay reachable)
+		setup := v1.Group("/setup")
+		{
+			setup.GET("/status", handlers.GetSetupStatus)
+			setup.POST("", handlers.CompleteSetup)
+		}
+
+		// Everything below is unavailable until the first super admin exists
+		v1.Use(middleware.RequireSetupComplete())
+
 		// Public routes
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/login", handlers.Login)
 			auth.POST("/microsoft", handlers.MicrosoftLogin)
-			auth.POST("/register", handlers.Register) // Public registration
+			auth.GET("/providers", handlers.GetAuthProviders)
+			auth.POST("/sso/:provider", handlers.SSOLogin)
+			auth.POST("/register", handlers.Register) // Invitation-based registration
+			auth.GET("/invitations/:token", handlers.ValidateInvitation)
 		}
 
 		// Public areas endpoint (for registration form)
@@ -43,27 +81,149 @@ func SetupRoutes(router *gin.Engine) {
 		{
 			// Auth routes
 			protected.GET("/auth/me", handlers.Me)
+			protected.GET("/auth/me/settings", handlers.GetMySettings)
+			protected.PUT("/auth/me/settings", handlers.UpdateMySettings)
+			protected.POST("/auth/change-password", handlers.ChangePassword)
 			protected.POST("/auth/superadmin", middleware.RequireRole(models.RoleSuperAdmin), handlers.CreateSuperAdmin)
+			protected.POST("/auth/impersonate", middleware.RequireRole(models.RoleSuperAdmin), handlers.StartImpersonation)
+			protected.POST("/auth/impersonation/stop", handlers.StopImpersonation)
+
+			// Impersonation audit (SuperAdmin only)
+			impersonations := protected.Group("/impersonations")
+			impersonations.Use(middleware.RequireRole(models.RoleSuperAdmin))
+			{
+				impersonations.GET("", handlers.GetImpersonationSessions)
+				impersonations.GET("/:id/audit", handlers.GetImpersonationAuditLog)
+			}
 
-			// Area routes (management - SuperAdmin only)
+			// Identity mapping rules for SSO users (SuperAdmin only)
+			identityMappings := protected.Group("/identity-mappings")
+			identityMappings.Use(middleware.RequireRole(models.RoleSuperAdmin))
+			{
+				identityMappings.GET("", handlers.GetIdentityMappings)
+				identityMappings.POST("", handlers.CreateIdentityMapping)
+				identityMappings.PUT("/:id", handlers.UpdateIdentityMapping)
+				identityMappings.DELETE("/:id", handlers.DeleteIdentityMapping)
+			}
+
+			// SCIM provisioning tokens (SuperAdmin only)
+			provisioningTokens := protected.Group("/provisioning-tokens")
+			provisioningTokens.Use(middleware.RequireRole(models.RoleSuperAdmin))
+			{
+				provisioningTokens.GET("", handlers.GetProvisioningTokens)
+				provisioningTokens.POST("", handlers.CreateProvisioningToken)
+				provisioningTokens.DELETE("/:id", handlers.RevokeProvisioningToken)
+			}
+
+			// Area routes (management requires areas.manage)
 			areas := protected.Group("/areas")
 			{
 				areas.GET("/:id", handlers.GetArea)
 
-				// SuperAdmin only
-				areas.POST("", middleware.RequireRole(models.RoleSuperAdmin), handlers.CreateArea)
-				areas.PUT("/:id", middleware.RequireRole(models.RoleSuperAdmin), handlers.UpdateArea)
-				areas.DELETE("/:id", middleware.RequireRole(models.RoleSuperAdmin), handlers.DeleteArea)
+				areas.POST("", middleware.RequirePermission(models.PermAreasManage), handlers.CreateArea)
+				areas.PUT("/:id", middleware.RequirePermission(models.PermAreasManage), handlers.UpdateArea)
+				areas.DELETE("/:id", middleware.RequirePermission(models.PermAreasManage), handlers.DeleteArea)
 			}
 
 			// User routes
 			users := protected.Group("/users")
 			{
-				users.GET("", middleware.RequireRole(models.RoleSuperAdmin, models.RoleAdmin), handlers.GetUsers)
-				users.GET("/:id", middleware.RequireRole(models.RoleSuperAdmin, models.RoleAdmin), handlers.GetUser)
-				users.POST("", middleware.RequireRole(models.RoleSuperAdmin, models.RoleAdmin), handlers.CreateUser)
-				users.PUT("/:id", middleware.RequireRole(models.RoleSuperAdmin, models.RoleAdmin), handlers.UpdateUser)
-				users.DELETE("/:id", middleware.RequireRole(models.RoleSuperAdmin), handlers.DeleteUser)
+				users.GET("", middleware.RequirePermission(models.PermUsersView), handlers.GetUsers)
+				users.GET("/pending", middleware.RequirePermission(models.PermUsersApprove), handlers.GetPendingUsers)
+				users.POST("/:id/approve", middleware.RequirePermission(models.PermUsersApprove), handlers.ApproveUser)
+				users.POST("/:id/reject", middleware.RequirePermission(models.PermUsersApprove), handlers.RejectUser)
+				users.GET("/:id", middleware.RequirePermission(models.PermUsersView), handlers.GetUser)
+				users.POST("", middleware.RequirePermission(models.PermUsersManage), handlers.CreateUser)
+				users.POST("/import", middleware.RequirePermission(models.PermUsersManage), handlers.ImportUsers)
+				users.PUT("/:id", middleware.RequirePermission(models.PermUsersManage), handlers.UpdateUser)
+				users.DELETE("/:id", middleware.RequirePermission(models.PermUsersManage), handlers.DeleteUser)
+				users.GET("/:id/offboarding", middleware.RequirePermission(models.PermUsersManage), handlers.GetOffboardingPreview)
+				users.POST("/:id/offboard", middleware.RequirePermission(models.PermUsersManage), handlers.OffboardUser)
+
+				// Area memberships
+				users.GET("/:id/areas", middleware.RequirePermission(models.PermUsersView), handlers.GetUserAreas)
+				users.POST("/:id/areas", middleware.RequirePermission(models.PermUsersManage), handlers.AddUserArea)
+				users.PUT("/:id/areas/:areaId", middleware.RequirePermission(models.PermUsersManage), handlers.UpdateUserArea)
+				users.DELETE("/:id/areas/:areaId", middleware.RequirePermission(models.PermUsersManage), handlers.RemoveUserArea)
+
+				// Custom role assignments
+				users.GET("/:id/roles", middleware.RequirePermission(models.PermRolesAssign), handlers.GetUserRoles)
+				users.POST("/:id/roles", middleware.RequirePermission(models.PermRolesAssign), handlers.AssignRole)
+				users.DELETE("/:id/roles/:assignmentId", middleware.RequirePermission(models.PermRolesAssign), handlers.RevokeRole)
+
+				// Skills and proficiency levels (users can set their own)
+				users.GET("/:id/skills", handlers.GetUserSkills)
+				users.PUT("/:id/skills", handlers.SetUserSkills)
+			}
+
+			// Permission catalog and custom roles
+			protected.GET("/permissions", handlers.GetPermissions)
+			roles := protected.Group("/roles")
+			{
+				roles.GET("", middleware.RequirePermission(models.PermRolesManage, models.PermRolesAssign), handlers.GetRoles)
+				roles.POST("", middleware.RequirePermission(models.PermRolesManage), handlers.CreateRole)
+				roles.PUT("/:id", middleware.RequirePermission(models.PermRolesManage), handlers.UpdateRole)
+				roles.DELETE("/:id", middleware.RequirePermission(models.PermRolesManage), handlers.DeleteRole)
+			}
+
+			// Activity types (management requires activity_types.manage)
+			activityTypes := protected.Group("/activity-types")
+			{
+				activityTypes.GET("", handlers.GetActivityTypes)
+				activityTypes.POST("", middleware.RequirePermission(models.PermActivityTypesManage), handlers.CreateActivityType)
+				activityTypes.PUT("/:id", middleware.RequirePermission(models.PermActivityTypesManage), handlers.UpdateActivityType)
+				activityTypes.DELETE("/:id", middleware.RequirePermission(models.PermActivityTypesManage), handlers.DeleteActivityType)
+			}
+
+			// Hourly rates (requires rates.manage)
+			rates := protected.Group("/rates")
+			rates.Use(middleware.RequirePermission(models.PermRatesManage))
+			{
+				rates.GET("", handlers.GetRates)
+				rates.POST("", handlers.CreateRate)
+				rates.PUT("/:id", handlers.UpdateRate)
+				rates.DELETE("/:id", handlers.DeleteRate)
+			}
+
+			// Clients (management requires clients.manage)
+			clients := protected.Group("/clients")
+			{
+				clients.GET("", handlers.GetClients)
+				clients.GET("/:id", handlers.GetClient)
+				clients.POST("", middleware.RequirePermission(models.PermClientsManage), handlers.CreateClient)
+				clients.PUT("/:id", middleware.RequirePermission(models.PermClientsManage), handlers.UpdateClient)
+				clients.DELETE("/:id", middleware.RequirePermission(models.PermClientsManage), handlers.DeleteClient)
+			}
+
+			// Invoices (requires invoices.manage)
+			invoices := protected.Group("/invoices")
+			invoices.Use(middleware.RequirePermission(models.PermInvoicesManage))
+			{
+				invoices.GET("", handlers.GetInvoices)
+				invoices.GET("/preview", handlers.PreviewInvoice)
+				invoices.GET("/:id", handlers.GetInvoice)
+				invoices.GET("/:id/export", handlers.ExportInvoice)
+				invoices.POST("", handlers.CreateInvoice)
+				invoices.POST("/:id/void", handlers.VoidInvoice)
+			}
+
+			// Skills catalog (management requires skills.manage)
+			skills := protected.Group("/skills")
+			{
+				skills.GET("", handlers.GetSkills)
+				skills.POST("", middleware.RequirePermission(models.PermSkillsManage), handlers.CreateSkill)
+				skills.PUT("/:id", middleware.RequirePermission(models.PermSkillsManage), handlers.UpdateSkill)
+				skills.DELETE("/:id", middleware.RequirePermission(models.PermSkillsManage), handlers.DeleteSkill)
+			}
+
+			// Invitation routes (requires invitations.manage)
+			invitations := protected.Group("/invitations")
+			invitations.Use(middleware.RequirePermission(models.PermInvitationsManage))
+			{
+				invitations.GET("", handlers.GetInvitations)
+				invitations.POST("", handlers.CreateInvitation)
+				invitations.POST("/:id/resend", handlers.ResendInvitation)
+				invitations.DELETE("/:id", handlers.RevokeInvitation)
 			}
 
 			// Project routes
@@ -74,9 +234,41 @@ func SetupRoutes(router *gin.Engine) {
 				projects.POST("", handlers.CreateProject)
 				projects.PUT("/:id", handlers.UpdateProject)
 				projects.PATCH("/:id/status", handlers.UpdateProjectStatus)
+				projects.POST("/:id/members", handlers.AddProjectMembers)
+				projects.PATCH("/:id/members/:userId", handlers.UpdateProjectMember)
+				projects.DELETE("/:id/members/:userId", handlers.RemoveProjectMember)
+				projects.GET("/:id/assignment-history", handlers.GetProjectAssignmentHistory)
+				projects.POST("/:id/areas", handlers.AddProjectArea)
+				projects.DELETE("/:id/areas/:areaId", handlers.RemoveProjectArea)
+				projects.PUT("/:id/skills", handlers.SetProjectSkills)
+				projects.GET("/:id/candidates", handlers.GetProjectCandidates)
+				projects.GET("/:id/forecast", handlers.GetProjectForecast)
+				projects.GET("/:id/milestones", handlers.GetProjectMilestones)
+				projects.POST("/:id/milestones", handlers.CreateMilestone)
+				projects.POST("/:id/clone", handlers.CloneProject)
+				projects.POST("/:id/save-as-template", handlers.SaveProjectAsTemplate)
 				projects.DELETE("/:id", handlers.DeleteProject)
 			}
 
+			// Milestone routes
+			milestones := protected.Group("/milestones")
+			{
+				milestones.GET("", handlers.GetMilestoneAgenda)
+				milestones.PUT("/:id", handlers.UpdateMilestone)
+				milestones.DELETE("/:id", handlers.DeleteMilestone)
+			}
+
+			// Project template routes (management requires projects.manage on the template's area)
+			projectTemplates := protected.Group("/project-templates")
+			{
+				projectTemplates.GET("", handlers.GetProjectTemplates)
+				projectTemplates.GET("/:id", handlers.GetProjectTemplate)
+				projectTemplates.POST("", handlers.CreateProjectTemplate)
+				projectTemplates.PUT("/:id", handlers.UpdateProjectTemplate)
+				projectTemplates.DELETE("/:id", handlers.DeleteProjectTemplate)
+				projectTemplates.POST("/:id/instantiate", handlers.InstantiateProjectTemplate)
+			}
+
 			// Task routes
 			tasks := protected.Group("/tasks")
 			{
@@ -85,6 +277,13 @@ func SetupRoutes(router *gin.Engine) {
 				tasks.POST("", handlers.CreateTask)
 				tasks.PUT("/:id", handlers.UpdateTask)
 				tasks.PATCH("/:id/status", handlers.UpdateTaskStatus)
+				tasks.PUT("/:id/skills", handlers.SetTaskSkills)
+				tasks.GET("/:id/dependencies", handlers.GetTaskDependencies)
+				tasks.PUT("/:id/dependencies", handlers.SetTaskDependencies)
+				tasks.POST("/:id/assignees", handlers.AddTaskAssignees)
+				tasks.DELETE("/:id/assignees/:userId", handlers.RemoveTaskAssignee)
+				tasks.GET("/:id/assignment-history", handlers.GetTaskAssignmentHistory)
+				tasks.GET("/:id/candidates", handlers.GetTaskCandidates)
 				tasks.PATCH("/bulk-order", handlers.BulkUpdateTaskOrder)
 				tasks.DELETE("/:id", handlers.DeleteTask)
 			}
@@ -109,13 +308,23 @@ func SetupRoutes(router *gin.Engine) {
 				comments.DELETE("/:id", handlers.DeleteComment)
 			}
 
-			// Stats routes (Admin and SuperAdmin only)
+			// Stats routes (requires stats.view)
 			stats := protected.Group("/stats")
-			stats.Use(middleware.RequireRole(models.RoleSuperAdmin, models.RoleAdmin))
+			stats.Use(middleware.RequirePermission(models.PermStatsView))
 			{
 				stats.GET("/areas", handlers.GetAreasSummary)
 				stats.GET("/users", handlers.GetUsersSummary)
 				stats.GET("/projects", handlers.GetProjectsSummary)
+				stats.GET("/billing", handlers.GetBillingReport)
+				stats.GET("/budgets", handlers.GetBudgetReport)
+			}
+
+			// Notifications of the current user
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", handlers.GetMyNotifications)
+				notifications.PATCH("/:id/read", handlers.MarkNotificationRead)
+				notifications.POST("/read-all", handlers.MarkAllNotificationsRead)
 			}
 
 			// Calendar routes (cualquier usuario autenticado puede ver SU calendario)
@@ -123,6 +332,7 @@ func SetupRoutes(router *gin.Engine) {
 			{
 				calendar.POST("/events", handlers.GetCalendarEvents)
 				calendar.GET("/today", handlers.GetTodayCalendarEvents)
+				calendar.GET("/week", handlers.GetWeekCalendarEvents)
 			}
 		}
 	}
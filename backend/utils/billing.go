@@ -0,0 +1,11 @@
+package utils
+
+import "os"
+
+// GetBillingCurrency returns the currency of rates, reports and invoices
+func GetBillingCurrency() string {
+	if currency := os.Getenv("BILLING_CURRENCY"); currency != "" {
+		return currency
+	}
+	return "USD" // default
+}
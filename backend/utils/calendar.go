@@ -54,9 +54,9 @@ type CalendarEventsResponse struct {
 
 // GetCalendarEvents obtiene los eventos del calendario del usuario
 func GetCalendarEvents(accessToken string, startDate, endDate time.Time) ([]CalendarEvent, error) {
-	// Formato de fechas para Microsoft Graph API
-	startISO := startDate.Format("2006-01-02T15:04:05")
-	endISO := endDate.Format("2006-01-02T15:04:05")
+	// Formato de fechas para Microsoft Graph API (sin zona horaria se interpretan en UTC)
+	startISO := startDate.UTC().Format("2006-01-02T15:04:05")
+	endISO := endDate.UTC().Format("2006-01-02T15:04:05")
 
 	// Construir URL con filtro de fechas
 	url := fmt.Sprintf(
@@ -92,26 +92,21 @@ func GetCalendarEvents(accessToken string, startDate, endDate time.Time) ([]Cale
 	return result.Value, nil
 }
 
-// GetTodayEvents obtiene los eventos del día actual
-func GetTodayEvents(accessToken string) ([]CalendarEvent, error) {
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+// GetTodayEvents obtiene los eventos del día actual en la zona horaria indicada
+func GetTodayEvents(accessToken string, loc *time.Location) ([]CalendarEvent, error) {
+	now := time.Now().In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
 	return GetCalendarEvents(accessToken, startOfDay, endOfDay)
 }
 
-// GetWeekEvents obtiene los eventos de la semana actual
-func GetWeekEvents(accessToken string) ([]CalendarEvent, error) {
-	now := time.Now()
-	// Lunes de esta semana
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7 // Domingo = 7
-	}
-	startOfWeek := now.AddDate(0, 0, -weekday+1)
-	startOfWeek = time.Date(startOfWeek.Year(), startOfWeek.Month(), startOfWeek.Day(), 0, 0, 0, 0, startOfWeek.Location())
-
+// GetWeekEvents obtiene los eventos de la semana actual en la zona horaria indicada.
+// weekStart es el primer día de la semana (0 = domingo, 1 = lunes)
+func GetWeekEvents(accessToken string, loc *time.Location, weekStart int) ([]CalendarEvent, error) {
+	now := time.Now().In(loc)
+	offset := (int(now.Weekday()) - weekStart + 7) % 7
+	startOfWeek := time.Date(now.Year(), now.Month(), now.Day()-offset, 0, 0, 0, 0, loc)
 	endOfWeek := startOfWeek.AddDate(0, 0, 7)
 
 	return GetCalendarEvents(accessToken, startOfWeek, endOfWeek)
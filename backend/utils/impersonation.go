@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetImpersonationMaxMinutes returns the longest an impersonation token can stay valid
+func GetImpersonationMaxMinutes() int {
+	if maxStr := os.Getenv("IMPERSONATION_MAX_MINUTES"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+			return max
+		}
+	}
+	return 60 // default: 1 hour
+}
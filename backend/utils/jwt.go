@@ -3,7 +3,6 @@ package utils
 import (
 	"errors"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,52 +14,99 @@ type JWTClaims struct {
 	Email  string      `json:"email"`
 	Role   models.Role `json:"role"`
 	AreaID *uint       `json:"area_id,omitempty"`
+	// MustChangePassword restricts the token to the password change endpoint
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// Impersonation is set when a SuperAdmin is acting as this user
+	Impersonation *ImpersonationClaims `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ImpersonationClaims identify the SuperAdmin behind an impersonation token
+type ImpersonationClaims struct {
+	SessionID         uint   `json:"sid"`
+	ImpersonatorID    uint   `json:"impersonator_id"`
+	ImpersonatorEmail string `json:"impersonator_email"`
+	ReadOnly          bool   `json:"read_only"`
+}
+
 // GenerateToken generates a JWT token for a user
 func GenerateToken(user *models.User) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET not configured")
-	}
+	claims := userClaims(user, time.Now().Add(time.Hour*time.Duration(GetJWTExpirationHours())))
+	claims.MustChangePassword = user.MustChangePassword
+	return signClaims(claims)
+}
 
-	expirationHours := 24 // default
-	if expStr := os.Getenv("JWT_EXPIRATION_HOURS"); expStr != "" {
-		if exp, err := strconv.Atoi(expStr); err == nil {
-			expirationHours = exp
-		}
+// GenerateImpersonationToken generates a token that acts as the impersonated user until the session expires
+func GenerateImpersonationToken(user *models.User, impersonator *models.User, session *models.ImpersonationSession) (string, error) {
+	claims := userClaims(user, session.ExpiresAt)
+	claims.Impersonation = &ImpersonationClaims{
+		SessionID:         session.ID,
+		ImpersonatorID:    impersonator.ID,
+		ImpersonatorEmail: impersonator.Email,
+		ReadOnly:          session.ReadOnly,
 	}
+	return signClaims(claims)
+}
 
-	claims := JWTClaims{
+// userClaims builds the identity claims for a user
+func userClaims(user *models.User, expiresAt time.Time) JWTClaims {
+	return JWTClaims{
 		UserID: user.ID,
 		Email:  user.Email,
 		Role:   user.Role,
 		AreaID: user.AreaID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expirationHours))),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "timeflow",
 		},
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
 }
 
-// ValidateToken validates and parses a JWT token
-func ValidateToken(tokenString string) (*JWTClaims, error) {
+// signClaims signs the claims with the current asymmetric key, or with JWT_SECRET when HS256 is configured
+func signClaims(claims JWTClaims) (string, error) {
+	if GetSigningAlgorithm() != AlgHS256 {
+		if keyring == nil {
+			return "", errors.New("keyring not initialized")
+		}
+		kid, alg, key, err := keyring.signer()
+		if err != nil {
+			return "", err
+		}
+		token := jwt.NewWithClaims(jwt.GetSigningMethod(alg), claims)
+		token.Header["kid"] = kid
+		return token.SignedString(key)
+	}
+
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
-		return nil, errors.New("JWT_SECRET not configured")
+		return "", errors.New("JWT_SECRET not configured")
 	}
 
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// ValidateToken validates and parses a JWT token. Asymmetric tokens are verified with the key
+// named by their kid; HS256 tokens are accepted while IsHS256Accepted allows it.
+func ValidateToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if !IsHS256Accepted() {
+				return nil, errors.New("HS256 tokens are no longer accepted")
+			}
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+			if keyring == nil {
+				return nil, errors.New("keyring not initialized")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return keyring.verifier(kid, token.Method.Alg())
+		default:
 			return nil, errors.New("invalid signing method")
 		}
-		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jaliko05/time-flow/models"
+)
+
+func TestValidateTokenHS256(t *testing.T) {
+	const secret = "test-secret"
+	user := &models.User{ID: 5, Email: "eva@example.com", Role: models.RoleUser}
+
+	sign := func(t *testing.T, key string, expiresAt time.Time) string {
+		t.Helper()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims(user, expiresAt)).SignedString([]byte(key))
+		if err != nil {
+			t.Fatalf("SignedString() error: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		signingAlg string
+		acceptHS   string
+		secret     string
+		key        string
+		expiresIn  time.Duration
+		wantValid  bool
+	}{
+		{"HS256 signing ignores JWT_ACCEPT_HS256", AlgHS256, "false", secret, secret, time.Hour, true},
+		{"asymmetric signing accepts HS256 by default", AlgEdDSA, "", secret, secret, time.Hour, true},
+		{"asymmetric signing with JWT_ACCEPT_HS256=true", AlgRS256, "true", secret, secret, time.Hour, true},
+		{"asymmetric signing with JWT_ACCEPT_HS256=false", AlgEdDSA, "false", secret, secret, time.Hour, false},
+		{"no JWT_SECRET", AlgHS256, "", "", secret, time.Hour, false},
+		{"wrong secret", AlgHS256, "", secret, "another-secret", time.Hour, false},
+		{"expired token", AlgHS256, "", secret, secret, -time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SIGNING_ALG", tt.signingAlg)
+			t.Setenv("JWT_ACCEPT_HS256", tt.acceptHS)
+			t.Setenv("JWT_SECRET", tt.secret)
+
+			claims, err := ValidateToken(sign(t, tt.key, time.Now().Add(tt.expiresIn)))
+			if tt.wantValid {
+				if err != nil {
+					t.Fatalf("ValidateToken() error: %v", err)
+				}
+				if claims.UserID != user.ID || claims.Role != user.Role {
+					t.Errorf("ValidateToken() claims = %+v, want user %d with role %s", claims, user.ID, user.Role)
+				}
+			} else if err == nil {
+				t.Errorf("ValidateToken() accepted the token")
+			}
+		})
+	}
+}
+
+func TestGenerateTokenHS256(t *testing.T) {
+	t.Setenv("JWT_SIGNING_ALG", AlgHS256)
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("JWT_EXPIRATION_HOURS", "3")
+
+	user := &models.User{ID: 9, Email: "leo@example.com", Role: models.RoleAdmin, MustChangePassword: true}
+	token, err := GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken() error: %v", err)
+	}
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error: %v", err)
+	}
+	if !claims.MustChangePassword {
+		t.Errorf("MustChangePassword not carried in the token")
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != 3*time.Hour {
+		t.Errorf("token lifetime = %v, want 3h", lifetime)
+	}
+
+	t.Setenv("JWT_SECRET", "")
+	if _, err := GenerateToken(user); err == nil {
+		t.Errorf("GenerateToken() without JWT_SECRET succeeded")
+	}
+}
@@ -0,0 +1,480 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaliko05/time-flow/models"
+	"gorm.io/gorm"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// encryptedKeyPrefix marks private keys stored encrypted with JWT_KEY_ENCRYPTION_KEY
+const encryptedKeyPrefix = "enc:"
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519 curve
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type verificationKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// Keyring holds the asymmetric keys used to sign and verify JWTs
+type Keyring struct {
+	db *gorm.DB
+
+	mu          sync.RWMutex
+	signingKid  string
+	signingAlg  string
+	signingKey  crypto.PrivateKey
+	activatedAt time.Time
+	verifiers   map[string]verificationKey
+	jwks        JWKSet
+	refreshedAt time.Time
+}
+
+// minRefreshInterval limits reloads triggered by tokens with an unknown kid
+const minRefreshInterval = time.Minute
+
+var keyring *Keyring
+
+// GetSigningAlgorithm returns the algorithm used to sign new tokens (JWT_SIGNING_ALG, default HS256)
+func GetSigningAlgorithm() string {
+	switch strings.ToUpper(os.Getenv("JWT_SIGNING_ALG")) {
+	case "RS256":
+		return AlgRS256
+	case "EDDSA", "ED25519":
+		return AlgEdDSA
+	default:
+		return AlgHS256
+	}
+}
+
+// IsHS256Accepted reports whether HS256 tokens signed with JWT_SECRET are still accepted.
+// They always are when HS256 is the signing algorithm; otherwise JWT_ACCEPT_HS256 (default true)
+// keeps existing sessions valid while migrating to asymmetric keys.
+func IsHS256Accepted() bool {
+	if os.Getenv("JWT_SECRET") == "" {
+		return false
+	}
+	if GetSigningAlgorithm() == AlgHS256 {
+		return true
+	}
+	accept, err := strconv.ParseBool(os.Getenv("JWT_ACCEPT_HS256"))
+	return err != nil || accept
+}
+
+// GetKeyRotationDays returns how long a signing key is used before a new one replaces it
+func GetKeyRotationDays() int {
+	if daysStr := os.Getenv("JWT_KEY_ROTATION_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days > 0 {
+			return days
+		}
+	}
+	return 30 // default
+}
+
+// GetJWTExpirationHours returns how long issued tokens stay valid
+func GetJWTExpirationHours() int {
+	if expStr := os.Getenv("JWT_EXPIRATION_HOURS"); expStr != "" {
+		if exp, err := strconv.Atoi(expStr); err == nil {
+			return exp
+		}
+	}
+	return 24 // default
+}
+
+// InitKeyring loads the signing keys from the database. When an asymmetric algorithm is
+// configured and there is no usable key yet, a first key is generated.
+func InitKeyring(db *gorm.DB) error {
+	keyring = &Keyring{db: db, verifiers: make(map[string]verificationKey)}
+	if err := keyring.refresh(); err != nil {
+		return err
+	}
+
+	if alg := GetSigningAlgorithm(); alg != AlgHS256 {
+		keyring.mu.RLock()
+		hasKey := keyring.signingKey != nil
+		keyring.mu.RUnlock()
+		if !hasKey {
+			if _, err := RotateSigningKey(); err != nil {
+				return err
+			}
+		}
+		log.Printf("JWT signing with %s (kid %s)", alg, keyring.currentKid())
+	}
+	return nil
+}
+
+// StartKeyRotation checks every hour whether the signing key is due for rotation
+func StartKeyRotation() {
+	if keyring == nil || GetSigningAlgorithm() == AlgHS256 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := keyring.rotateIfDue(); err != nil {
+				log.Printf("Warning: JWT key rotation failed: %v", err)
+			}
+		}
+	}()
+}
+
+// RotateSigningKey generates a new signing key for the configured algorithm. Previous keys stop
+// signing but keep verifying until every token they signed has expired.
+func RotateSigningKey() (*models.SigningKey, error) {
+	if keyring == nil {
+		return nil, errors.New("keyring not initialized")
+	}
+	alg := GetSigningAlgorithm()
+	if alg == AlgHS256 {
+		return nil, errors.New("key rotation requires JWT_SIGNING_ALG to be RS256 or EdDSA")
+	}
+
+	key, err := generateSigningKey(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := retiredKeyExpiry(now)
+	err = keyring.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).
+			Where("retired_at IS NULL").
+			Updates(map[string]interface{}{"retired_at": now, "expires_at": expiresAt}).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop keys that no token can reference anymore
+	keyring.db.Where("expires_at < ?", now.Add(-24*time.Hour)).Delete(&models.SigningKey{})
+
+	if err := keyring.refresh(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// retiredKeyExpiry returns until when a key retired at the given moment keeps verifying:
+// the lifetime of the last token it signed plus an hour of margin
+func retiredKeyExpiry(retiredAt time.Time) time.Time {
+	return retiredAt.Add(time.Duration(GetJWTExpirationHours())*time.Hour + time.Hour)
+}
+
+// GetJWKS returns the public keys that currently verify tokens
+func GetJWKS() JWKSet {
+	if keyring == nil {
+		return JWKSet{Keys: []JWK{}}
+	}
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+	return keyring.jwks
+}
+
+// rotateIfDue reloads the keys (another instance may have rotated) and rotates when the signing key is too old
+func (k *Keyring) rotateIfDue() error {
+	if err := k.refresh(); err != nil {
+		return err
+	}
+
+	k.mu.RLock()
+	due := k.signingKey == nil ||
+		k.signingAlg != GetSigningAlgorithm() ||
+		time.Since(k.activatedAt) >= time.Duration(GetKeyRotationDays())*24*time.Hour
+	k.mu.RUnlock()
+
+	if due {
+		key, err := RotateSigningKey()
+		if err != nil {
+			return err
+		}
+		log.Printf("JWT signing key rotated (kid %s)", key.Kid)
+	}
+	return nil
+}
+
+// refresh loads every key that still verifies tokens and picks the newest active one for signing
+func (k *Keyring) refresh() error {
+	var keys []models.SigningKey
+	if err := k.db.Order("activated_at DESC").Find(&keys).Error; err != nil {
+		return err
+	}
+	k.load(keys, time.Now())
+	return nil
+}
+
+// load replaces the verifying and signing keys with those of keys, given newest first
+func (k *Keyring) load(keys []models.SigningKey, now time.Time) {
+	alg := GetSigningAlgorithm()
+	verifiers := make(map[string]verificationKey)
+	jwks := JWKSet{Keys: []JWK{}}
+	var signing *models.SigningKey
+	var signingKey crypto.PrivateKey
+
+	for i := range keys {
+		key := &keys[i]
+		if !key.CanVerify(now) {
+			continue
+		}
+
+		publicKey, err := parsePublicKey(key.PublicKey)
+		if err != nil {
+			log.Printf("Warning: Skipping JWT key %s: %v", key.Kid, err)
+			continue
+		}
+		verifiers[key.Kid] = verificationKey{alg: key.Algorithm, key: publicKey}
+		if jwk, ok := toJWK(key.Kid, key.Algorithm, publicKey); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+
+		if signing == nil && key.CanSign() && key.Algorithm == alg {
+			privateKey, err := parsePrivateKey(key.PrivateKey)
+			if err != nil {
+				log.Printf("Warning: Cannot load private JWT key %s: %v", key.Kid, err)
+				continue
+			}
+			signing = key
+			signingKey = privateKey
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.verifiers = verifiers
+	k.jwks = jwks
+	k.refreshedAt = now
+	k.signingKey = signingKey
+	if signing != nil {
+		k.signingKid = signing.Kid
+		k.signingAlg = signing.Algorithm
+		k.activatedAt = signing.ActivatedAt
+	} else {
+		k.signingKid, k.signingAlg = "", ""
+	}
+}
+
+func (k *Keyring) currentKid() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signingKid
+}
+
+// signer returns the current signing key with its kid and algorithm
+func (k *Keyring) signer() (kid, alg string, key crypto.PrivateKey, err error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.signingKey == nil {
+		return "", "", nil, errors.New("no JWT signing key available")
+	}
+	return k.signingKid, k.signingAlg, k.signingKey, nil
+}
+
+// verifier returns the public key for a kid, reloading the keys once if it is unknown
+func (k *Keyring) verifier(kid, alg string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	v, ok := k.verifiers[kid]
+	stale := time.Since(k.refreshedAt) >= minRefreshInterval
+	k.mu.RUnlock()
+
+	if !ok && stale {
+		if err := k.refresh(); err != nil {
+			return nil, err
+		}
+		k.mu.RLock()
+		v, ok = k.verifiers[kid]
+		k.mu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if v.alg != alg {
+		return nil, errors.New("signing method does not match key")
+	}
+	return v.key, nil
+}
+
+// generateSigningKey creates a new key pair for the algorithm
+func generateSigningKey(alg string) (*models.SigningKey, error) {
+	var privateKey crypto.PrivateKey
+	var publicKey crypto.PublicKey
+	switch alg {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, publicKey = key, &key.PublicKey
+	case AlgEdDSA:
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, publicKey = key, pub
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %s", alg)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM, err := encryptPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		return nil, err
+	}
+
+	// The kid is derived from the public key so it is stable and unique
+	fingerprint := sha256.Sum256(publicDER)
+	return &models.SigningKey{
+		Kid:         base64.RawURLEncoding.EncodeToString(fingerprint[:12]),
+		Algorithm:   alg,
+		PrivateKey:  privatePEM,
+		PublicKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		ActivatedAt: time.Now(),
+	}, nil
+}
+
+func parsePublicKey(publicPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func parsePrivateKey(stored string) (crypto.PrivateKey, error) {
+	privatePEM, err := decryptPrivateKey(stored)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// toJWK converts a public key to its JWK representation
+func toJWK(kid, alg string, publicKey crypto.PublicKey) (JWK, bool) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: alg,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Alg: alg,
+			Use: "sig",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// keyEncryptionCipher returns the AES-GCM cipher derived from JWT_KEY_ENCRYPTION_KEY, or nil when unset
+func keyEncryptionCipher() (cipher.AEAD, error) {
+	secret := os.Getenv("JWT_KEY_ENCRYPTION_KEY")
+	if secret == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptPrivateKey encrypts a private key PEM for storage when an encryption key is configured
+func encryptPrivateKey(privatePEM []byte) (string, error) {
+	gcm, err := keyEncryptionCipher()
+	if err != nil || gcm == nil {
+		return string(privatePEM), err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, privatePEM, nil)
+	return encryptedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptPrivateKey reverses encryptPrivateKey
+func decryptPrivateKey(stored string) ([]byte, error) {
+	if !strings.HasPrefix(stored, encryptedKeyPrefix) {
+		return []byte(stored), nil
+	}
+	gcm, err := keyEncryptionCipher()
+	if err != nil {
+		return nil, err
+	}
+	if gcm == nil {
+		return nil, errors.New("key is encrypted but JWT_KEY_ENCRYPTION_KEY is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedKeyPrefix))
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted key is too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
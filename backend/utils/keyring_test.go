@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jaliko05/time-flow/models"
+)
+
+// useKeyring installs an in-memory keyring with the given keys, newest first, as seen at now
+func useKeyring(t *testing.T, now time.Time, keys ...models.SigningKey) {
+	t.Helper()
+	previous := keyring
+	t.Cleanup(func() { keyring = previous })
+
+	keyring = &Keyring{verifiers: make(map[string]verificationKey)}
+	keyring.load(keys, now)
+}
+
+func newSigningKey(t *testing.T, alg string) models.SigningKey {
+	t.Helper()
+	key, err := generateSigningKey(alg)
+	if err != nil {
+		t.Fatalf("generateSigningKey(%s) error: %v", alg, err)
+	}
+	return *key
+}
+
+func tokenKid(t *testing.T, tokenString string) string {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &JWTClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error: %v", err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func TestKeyringRotation(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			t.Setenv("JWT_SIGNING_ALG", alg)
+			t.Setenv("JWT_EXPIRATION_HOURS", "2")
+			t.Setenv("JWT_SECRET", "")
+			user := &models.User{ID: 7, Email: "ana@example.com", Role: models.RoleUser}
+			now := time.Now()
+
+			first := newSigningKey(t, alg)
+			useKeyring(t, now, first)
+			before, err := GenerateToken(user)
+			if err != nil {
+				t.Fatalf("GenerateToken() error: %v", err)
+			}
+			if kid := tokenKid(t, before); kid != first.Kid {
+				t.Fatalf("token kid = %q, want %q", kid, first.Kid)
+			}
+
+			// Rotate: the first key stops signing and keeps verifying until its tokens expire
+			second := newSigningKey(t, alg)
+			second.ActivatedAt = now.Add(time.Second)
+			expiresAt := retiredKeyExpiry(now)
+			first.RetiredAt, first.ExpiresAt = &now, &expiresAt
+			useKeyring(t, now, second, first)
+
+			after, err := GenerateToken(user)
+			if err != nil {
+				t.Fatalf("GenerateToken() after rotation error: %v", err)
+			}
+			if kid := tokenKid(t, after); kid != second.Kid {
+				t.Errorf("token kid after rotation = %q, want %q", kid, second.Kid)
+			}
+			for name, token := range map[string]string{"before rotation": before, "after rotation": after} {
+				claims, err := ValidateToken(token)
+				if err != nil {
+					t.Errorf("ValidateToken(%s) error: %v", name, err)
+				} else if claims.UserID != user.ID {
+					t.Errorf("ValidateToken(%s) user = %d, want %d", name, claims.UserID, user.ID)
+				}
+			}
+			if got := len(GetJWKS().Keys); got != 2 {
+				t.Errorf("JWKS has %d keys, want both the current and the retired key", got)
+			}
+
+			// Once the retired key expires, its tokens are rejected and it leaves the JWKS
+			useKeyring(t, expiresAt.Add(time.Second), second, first)
+			if _, err := ValidateToken(before); err == nil || !strings.Contains(err.Error(), "unknown key id") {
+				t.Errorf("ValidateToken() with an expired key error = %v, want unknown key id", err)
+			}
+			if _, err := ValidateToken(after); err != nil {
+				t.Errorf("ValidateToken() with the current key error: %v", err)
+			}
+			if jwks := GetJWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != second.Kid {
+				t.Errorf("JWKS = %+v, want only the current key", jwks.Keys)
+			}
+		})
+	}
+}
+
+func TestRetiredKeyExpiry(t *testing.T) {
+	tests := []struct {
+		hours string
+		want  time.Duration
+	}{
+		{"", 25 * time.Hour},
+		{"8", 9 * time.Hour},
+		{"invalid", 25 * time.Hour},
+	}
+
+	retiredAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run("JWT_EXPIRATION_HOURS="+tt.hours, func(t *testing.T) {
+			t.Setenv("JWT_EXPIRATION_HOURS", tt.hours)
+			if got := retiredKeyExpiry(retiredAt).Sub(retiredAt); got != tt.want {
+				t.Errorf("retiredKeyExpiry() = retiredAt + %v, want + %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTokenKeyChecks(t *testing.T) {
+	t.Setenv("JWT_SIGNING_ALG", AlgEdDSA)
+	t.Setenv("JWT_SECRET", "")
+	user := &models.User{ID: 3, Email: "luis@example.com", Role: models.RoleAdmin}
+
+	known := newSigningKey(t, AlgEdDSA)
+	unknown := newSigningKey(t, AlgEdDSA)
+
+	useKeyring(t, time.Now(), unknown)
+	foreign, err := GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken() error: %v", err)
+	}
+
+	useKeyring(t, time.Now(), known)
+	signed, err := GenerateToken(user)
+	if err != nil {
+		t.Fatalf("GenerateToken() error: %v", err)
+	}
+
+	// A token claiming the known kid but signed by another key
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, userClaims(user, time.Now().Add(time.Hour)))
+	forged.Header["kid"] = known.Kid
+	unknownKey, err := parsePrivateKey(unknown.PrivateKey)
+	if err != nil {
+		t.Fatalf("parsePrivateKey() error: %v", err)
+	}
+	forgedString, err := forged.SignedString(unknownKey)
+	if err != nil {
+		t.Fatalf("SignedString() error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"current key", signed, ""},
+		{"unknown kid", foreign, "unknown key id"},
+		{"wrong key for kid", forgedString, "signature is invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateToken(tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateToken() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateToken() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrivateKeyEncryption(t *testing.T) {
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", "a long and secret passphrase")
+	key := newSigningKey(t, AlgEdDSA)
+	if !strings.HasPrefix(key.PrivateKey, encryptedKeyPrefix) {
+		t.Fatalf("private key stored in clear with JWT_KEY_ENCRYPTION_KEY set")
+	}
+	if _, err := parsePrivateKey(key.PrivateKey); err != nil {
+		t.Errorf("parsePrivateKey() error: %v", err)
+	}
+
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", "")
+	if _, err := parsePrivateKey(key.PrivateKey); err == nil {
+		t.Errorf("parsePrivateKey() of an encrypted key without JWT_KEY_ENCRYPTION_KEY succeeded")
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// EmailConfigured reports whether an SMTP server is configured, that is, whether SendEmail delivers anything
+func EmailConfigured() bool {
+	return os.Getenv("SMTP_HOST") != ""
+}
+
+// SendEmail sends a plain text email through the SMTP server configured in the environment.
+// When SMTP_HOST is not set the email is skipped and only logged, so development setups keep working.
+func SendEmail(to, subject, body string) error {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Printf("SMTP not configured, skipping email to %s: %s", to, subject)
+		return nil
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@timeflow.com"
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(host+":"+port, auth, from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// GetAppURL returns the public frontend URL used to build links sent by email
+func GetAppURL() string {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		return "http://localhost:5173"
+	}
+	return strings.TrimRight(appURL, "/")
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page in points, with the same margin on every side
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// PDFCell is a piece of text placed at a horizontal offset from the left margin.
+// Text longer than Width (when set) is truncated.
+type PDFCell struct {
+	X     float64
+	Width float64
+	Text  string
+}
+
+// PDFDocument is a minimal writer for text reports: A4 pages flowing top to bottom,
+// Helvetica regular and bold, horizontal rules. Text is encoded as WinAnsi (Latin-1).
+type PDFDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// NewPDFDocument creates a document with an empty first page
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.addPage()
+	return d
+}
+
+// Heading writes a line of large bold text
+func (d *PDFDocument) Heading(text string) {
+	d.Row(16, true, PDFCell{Text: text})
+}
+
+// Text writes a line of regular text
+func (d *PDFDocument) Text(text string) {
+	d.Row(10, false, PDFCell{Text: text})
+}
+
+// Row writes a line made of cells, starting a new page when the current one is full
+func (d *PDFDocument) Row(size float64, bold bool, cells ...PDFCell) {
+	lineHeight := size * 1.4
+	if d.y-lineHeight < pdfMargin {
+		d.addPage()
+	}
+	d.y -= lineHeight
+
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	page := d.pages[len(d.pages)-1]
+	for _, cell := range cells {
+		text := cell.Text
+		if cell.Width > 0 {
+			// Helvetica averages about half the font size per character
+			if max := int(cell.Width / (size * 0.5)); len([]rune(text)) > max && max > 3 {
+				text = string([]rune(text)[:max-3]) + "..."
+			}
+		}
+		fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, pdfMargin+cell.X, d.y, pdfEscape(text))
+	}
+}
+
+// Rule draws a horizontal line across the page
+func (d *PDFDocument) Rule() {
+	d.Space(4)
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, d.y, pdfPageWidth-pdfMargin, d.y)
+	d.Space(4)
+}
+
+// Space moves down the given number of points
+func (d *PDFDocument) Space(points float64) {
+	if d.y-points < pdfMargin {
+		d.addPage()
+		return
+	}
+	d.y -= points
+}
+
+// Bytes renders the document
+func (d *PDFDocument) Bytes() []byte {
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+
+	// 1: catalog, 2: page tree, 3-4: fonts, then a page and its content stream per page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func (d *PDFDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// pdfEscape encodes text as WinAnsi and escapes the string delimiters
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '€':
+			b.WriteByte(0x80)
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 256:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// IsOpenRegistrationEnabled reports whether /auth/register accepts sign-ups without an invitation.
+// Open registration is disabled unless OPEN_REGISTRATION_ENABLED is explicitly set to true.
+func IsOpenRegistrationEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("OPEN_REGISTRATION_ENABLED"))
+	return enabled
+}
+
+// GetInvitationExpirationHours returns how long a new invitation stays valid
+func GetInvitationExpirationHours() int {
+	if expStr := os.Getenv("INVITATION_EXPIRATION_HOURS"); expStr != "" {
+		if exp, err := strconv.Atoi(expStr); err == nil && exp > 0 {
+			return exp
+		}
+	}
+	return 72 // default: 3 days
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIM schema URNs
+const (
+	SCIMSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIMContentType is the media type of SCIM responses
+const SCIMContentType = "application/scim+json"
+
+// SCIMError is the SCIM error response body (RFC 7644 section 3.12)
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMResponse sends a SCIM resource
+func SCIMResponse(c *gin.Context, statusCode int, data interface{}) {
+	c.Header("Content-Type", SCIMContentType)
+	c.JSON(statusCode, data)
+}
+
+// SCIMErrorResponse sends a SCIM error. scimType is optional (e.g. invalidFilter, uniqueness).
+func SCIMErrorResponse(c *gin.Context, statusCode int, scimType, detail string) {
+	SCIMResponse(c, statusCode, SCIMError{
+		Schemas:  []string{SCIMSchemaError},
+		Status:   strconv.Itoa(statusCode),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}